@@ -0,0 +1,252 @@
+package dbfetch
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"iter"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// BulkOptions configure BulkInsert.
+// The zero value is usable.
+type BulkOptions struct {
+	// BatchSize is the maximum number of rows per INSERT statement, 500 if it is zero.
+	// With Dollar, it is reduced to stay within the limit of 65535 parameters per statement of PostgreSQL.
+	// It is not used with COPY.
+	BatchSize int
+	// Dollar uses the placeholders $1, $2, ... instead of ? as required by PostgreSQL.
+	// It is set automatically for the drivers lib/pq and pgx and for drivers implementing DollarDriver.
+	Dollar bool
+	// NoCopy disables COPY for lib/pq and for connections implementing CopyFromConn.
+	NoCopy bool
+}
+
+// maxDollarParams is the maximum number of parameters per statement in PostgreSQL.
+const maxDollarParams = 65535
+
+// DollarDriver is implemented by drivers requiring the placeholders $1, $2, ... of PostgreSQL,
+// e.g. the driver of pgxfetch. BulkInsert uses them if DollarPlaceholders reports true.
+type DollarDriver interface {
+	driver.Driver
+	DollarPlaceholders() bool
+}
+
+// CopyFromConn is implemented by driver connections with a native COPY FROM, e.g. the ones of pgxfetch.
+// BulkInsert retrieves them with sql.Conn.Raw.
+type CopyFromConn interface {
+	// CopyFrom inserts rows into the columns of table in a single statement and retrieves the number of rows.
+	// It fails for rows without a value per column.
+	CopyFrom(ctx context.Context, table string, columns []string, rows iter.Seq[[]any]) (int64, error)
+}
+
+// copyDrivers contains the drivers supporting COPY FROM STDIN with prepared statements, see driverType.
+var copyDrivers = map[string]bool{
+	"github.com/lib/pq.Driver": true,
+}
+
+// dollarDrivers contains the drivers requiring $n placeholders not implementing DollarDriver, see driverType.
+var dollarDrivers = map[string]bool{
+	"github.com/lib/pq.Driver":              true,
+	"github.com/jackc/pgx/v4/stdlib.Driver": true,
+	"github.com/jackc/pgx/v5/stdlib.Driver": true,
+}
+
+// driverType retrieves the package path and the name of the type of d, e.g. "github.com/lib/pq.Driver".
+func driverType(d driver.Driver) string {
+	t := reflect.TypeOf(d)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.PkgPath() + "." + t.Name()
+}
+
+// usesDollar reports whether d requires $n placeholders.
+func usesDollar(d driver.Driver) bool {
+	if dd, ok := d.(DollarDriver); ok {
+		return dd.DollarPlaceholders()
+	}
+	return dollarDrivers[driverType(d)]
+}
+
+// BulkInsert inserts all rows into the columns of table and retrieves the number of rows.
+// It uses the native COPY FROM of driver connections implementing CopyFromConn, e.g. of pgxfetch,
+// COPY FROM STDIN with the driver lib/pq and batches of multi-row INSERT statements otherwise.
+// db can be any Querier. On a *sql.DB or a *sql.Conn, all rows are inserted in a single transaction
+// or a single COPY; on a *sql.Tx, they are inserted in that transaction with INSERT statements.
+// Only a *sql.DB reports its driver, so set BulkOptions.Dollar for PostgreSQL on other Queriers.
+// table and columns are inserted into the statements verbatim, they must not contain untrusted input.
+// opts can be nil.
+//
+//	n, err := dbfetch.BulkInsert(ctx, db, "events", []string{"id", "payload"}, func(yield func([]any) bool) {
+//		for _, e := range events {
+//			if !yield([]any{e.ID, e.Payload}) {
+//				return
+//			}
+//		}
+//	}, nil)
+func BulkInsert(ctx context.Context, db Querier, table string, columns []string, rows iter.Seq[[]any], opts *BulkOptions) (int64, error) {
+	if opts == nil {
+		opts = &BulkOptions{}
+	}
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("bulk insert into %s: no columns", table)
+	}
+	insert := bulkInsertBatches
+	o := *opts
+	if d, ok := db.(interface{ Driver() driver.Driver }); ok {
+		if copyDrivers[driverType(d.Driver())] && !opts.NoCopy {
+			insert = bulkCopy
+		}
+		o.Dollar = o.Dollar || usesDollar(d.Driver())
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 500
+	}
+	if o.Dollar {
+		o.BatchSize = max(1, min(o.BatchSize, maxDollarParams/len(columns)))
+	}
+	if sdb, ok := db.(*sql.DB); ok {
+		// keep the connection checked for CopyFromConn
+		conn, err := sdb.Conn(ctx)
+		if err != nil {
+			return 0, err
+		}
+		defer conn.Close()
+		db = conn
+	}
+	if conn, ok := db.(*sql.Conn); ok && !o.NoCopy {
+		if n, copied, err := nativeCopy(ctx, conn, table, columns, rows); copied || err != nil {
+			return n, err
+		}
+	}
+	tb, ok := db.(TxBeginner)
+	if !ok {
+		return insert(ctx, db, table, columns, rows, &o)
+	}
+	var n int64
+	err := InTx(ctx, tb, nil, func(tx Querier) error {
+		var err error
+		n, err = insert(ctx, tx, table, columns, rows, &o)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// nativeCopy inserts rows with the native COPY FROM of the driver connection of conn.
+// copied is false if it does not implement CopyFromConn.
+func nativeCopy(ctx context.Context, conn *sql.Conn, table string, columns []string, rows iter.Seq[[]any]) (n int64, copied bool, err error) {
+	err = conn.Raw(func(dc any) error {
+		cf, ok := dc.(CopyFromConn)
+		if !ok {
+			return nil
+		}
+		copied = true
+		var err error
+		n, err = cf.CopyFrom(ctx, table, columns, rows)
+		return err
+	})
+	if err != nil {
+		return n, copied, newQueryError(copyQuery(table, columns), nil, PhaseExec, err)
+	}
+	return n, copied, nil
+}
+
+// copyQuery creates the COPY FROM STDIN statement for columns of table.
+func copyQuery(table string, columns []string) string {
+	return "COPY " + table + " (" + strings.Join(columns, ", ") + ") FROM STDIN"
+}
+
+// bulkCopy inserts rows with COPY FROM STDIN as supported by lib/pq.
+func bulkCopy(ctx context.Context, tx Querier, table string, columns []string, rows iter.Seq[[]any], _ *BulkOptions) (int64, error) {
+	query := copyQuery(table, columns)
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, newQueryError(query, nil, PhasePrepare, err)
+	}
+	defer stmt.Close()
+	var n int64
+	for row := range rows {
+		if len(row) != len(columns) {
+			return n, newQueryError(query, row, PhaseExec, fmt.Errorf("row %d has %d values for %d columns", n, len(row), len(columns)))
+		}
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			return n, newQueryError(query, row, PhaseExec, err)
+		}
+		n++
+	}
+	// flush the buffered rows
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return n, newQueryError(query, nil, PhaseExec, err)
+	}
+	return n, nil
+}
+
+// bulkInsertBatches inserts rows with multi-row INSERT statements.
+func bulkInsertBatches(ctx context.Context, tx Querier, table string, columns []string, rows iter.Seq[[]any], opts *BulkOptions) (int64, error) {
+	prefix := "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES "
+	var (
+		n     int64
+		batch int
+		args  = make([]any, 0, opts.BatchSize*len(columns))
+	)
+	flush := func() error {
+		if batch == 0 {
+			return nil
+		}
+		query := bulkQuery(prefix, len(columns), batch, opts.Dollar)
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return newQueryError(query, args, PhaseExec, err)
+		}
+		n += int64(batch)
+		batch = 0
+		args = args[:0]
+		return nil
+	}
+	for row := range rows {
+		if len(row) != len(columns) {
+			return n, newQueryError(prefix, row, PhaseExec, fmt.Errorf("row %d has %d values for %d columns", n+int64(batch), len(row), len(columns)))
+		}
+		args = append(args, row...)
+		batch++
+		if batch == opts.BatchSize {
+			if err := flush(); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, flush()
+}
+
+// bulkQuery creates an INSERT statement for rows rows with cols values each.
+func bulkQuery(prefix string, cols, rows int, dollar bool) string {
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	p := 0
+	for r := range rows {
+		if r > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteByte('(')
+		for c := range cols {
+			if c > 0 {
+				sb.WriteString(", ")
+			}
+			p++
+			if dollar {
+				sb.WriteByte('$')
+				sb.WriteString(strconv.Itoa(p))
+			} else {
+				sb.WriteByte('?')
+			}
+		}
+		sb.WriteByte(')')
+	}
+	return sb.String()
+}
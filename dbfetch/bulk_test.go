@@ -0,0 +1,241 @@
+package dbfetch
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+// numberRows yields n rows with two values.
+func numberRows(n int) func(func([]any) bool) {
+	return func(yield func([]any) bool) {
+		for i := range n {
+			if !yield([]any{int64(i), fmt.Sprint(i)}) {
+				return
+			}
+		}
+	}
+}
+
+func TestBulkInsert(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"INSERT INTO t (a, b) VALUES (?, ?), (?, ?)": {},
+		"INSERT INTO t (a, b) VALUES (?, ?)":         {},
+	})
+	n, err := BulkInsert(ctx, db.DB, "t", []string{"a", "b"}, numberRows(5), &BulkOptions{BatchSize: 2})
+	if err != nil || n != 5 {
+		t.Errorf("got %d, %v, want 5 rows", n, err)
+	}
+	want := []string{
+		"begin",
+		"exec INSERT INTO t (a, b) VALUES (?, ?), (?, ?)",
+		"exec INSERT INTO t (a, b) VALUES (?, ?), (?, ?)",
+		"exec INSERT INTO t (a, b) VALUES (?, ?)",
+		"commit",
+	}
+	if calls := callLog(db); !slices.Equal(calls, want) {
+		t.Errorf("got calls %v, want %v", calls, want)
+	}
+	bad := func(yield func([]any) bool) { yield([]any{1}) }
+	if _, err := BulkInsert(ctx, db.DB, "t", []string{"a", "b"}, bad, nil); err == nil {
+		t.Errorf("no error for a row with a missing value")
+	}
+	if calls, want := callLog(db), []string{"begin", "rollback"}; !slices.Equal(calls, want) {
+		t.Errorf("got calls %v, want %v", calls, want)
+	}
+}
+
+func TestBulkInsertDollarLimit(t *testing.T) {
+	ctx := context.Background()
+	columns := make([]string, 16384)
+	for i := range columns {
+		columns[i] = fmt.Sprint("c", i)
+	}
+	prefix := "INSERT INTO t (" + strings.Join(columns, ", ") + ") VALUES "
+	full, rest := bulkQuery(prefix, len(columns), 3, true), bulkQuery(prefix, len(columns), 1, true)
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{full: {}, rest: {}})
+	rows := func(yield func([]any) bool) {
+		for range 4 {
+			if !yield(make([]any, len(columns))) {
+				return
+			}
+		}
+	}
+	n, err := BulkInsert(ctx, db.DB, "t", columns, rows, &BulkOptions{Dollar: true})
+	if err != nil || n != 4 {
+		t.Errorf("got %d, %v, want 4 rows", n, err)
+	}
+	if calls, want := callLog(db), []string{"begin", "exec " + full, "exec " + rest, "commit"}; !slices.Equal(calls, want) {
+		t.Errorf("got %d calls, want %d with 3 and 1 rows", len(calls), len(want))
+	}
+}
+
+// dollarConnector opens connections of its connector with a driver implementing DollarDriver like pgxfetch.
+type dollarConnector struct {
+	driver.Connector
+}
+
+func (c dollarConnector) Driver() driver.Driver {
+	return dollarDriver{c.Connector.Driver()}
+}
+
+type dollarDriver struct {
+	driver.Driver
+}
+
+func (dollarDriver) DollarPlaceholders() bool {
+	return true
+}
+
+func TestBulkInsertDollarDriver(t *testing.T) {
+	ctx := context.Background()
+	columns := make([]string, 40000)
+	for i := range columns {
+		columns[i] = fmt.Sprint("c", i)
+	}
+	query := bulkQuery("INSERT INTO t ("+strings.Join(columns, ", ")+") VALUES ", len(columns), 1, true)
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{query: {}})
+	dollar := sql.OpenDB(dollarConnector{db.Connector()})
+	defer dollar.Close()
+	rows := func(yield func([]any) bool) {
+		for range 2 {
+			if !yield(make([]any, len(columns))) {
+				return
+			}
+		}
+	}
+	// a batch of two rows would exceed the limit of parameters
+	n, err := BulkInsert(ctx, dollar, "t", columns, rows, nil)
+	if err != nil || n != 2 {
+		t.Errorf("got %d, %v, want 2 rows", n, err)
+	}
+	if calls, want := callLog(db), []string{"begin", "exec " + query, "exec " + query, "commit"}; !slices.Equal(calls, want) {
+		t.Errorf("got %d calls, want %d with a row each", len(calls), len(want))
+	}
+	if got := driverType(db.Driver()); got != "github.com/arnehormann/goof/dbfetch/dbfetchtest.fakeDriver" {
+		t.Errorf("got driver type %q", got)
+	}
+}
+
+func TestBulkInsertCopy(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"COPY t (a, b) FROM STDIN": {},
+	})
+	name := driverType(db.Driver())
+	copyDrivers[name] = true
+	defer delete(copyDrivers, name)
+	n, err := BulkInsert(ctx, db.DB, "t", []string{"a", "b"}, numberRows(2), nil)
+	if err != nil || n != 2 {
+		t.Errorf("got %d, %v, want 2 rows", n, err)
+	}
+	want := []string{
+		"begin",
+		"prepare COPY t (a, b) FROM STDIN",
+		"exec COPY t (a, b) FROM STDIN",
+		"exec COPY t (a, b) FROM STDIN",
+		"exec COPY t (a, b) FROM STDIN",
+		"commit",
+	}
+	if calls := callLog(db); !slices.Equal(calls, want) {
+		t.Errorf("got calls %v, want %v", calls, want)
+	}
+}
+
+func TestBulkInsertTx(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"INSERT INTO t (a, b) VALUES (?, ?), (?, ?)": {},
+	})
+	err := InTx(ctx, db, nil, func(tx Querier) error {
+		n, err := BulkInsert(ctx, tx, "t", []string{"a", "b"}, numberRows(2), nil)
+		if err == nil && n != 2 {
+			t.Errorf("got %d, want 2 rows", n)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the rows are inserted in the transaction of the caller
+	want := []string{"begin", "exec INSERT INTO t (a, b) VALUES (?, ?), (?, ?)", "commit"}
+	if calls := callLog(db); !slices.Equal(calls, want) {
+		t.Errorf("got calls %v, want %v", calls, want)
+	}
+}
+
+// copyConnector opens connections of its connector implementing CopyFromConn like the ones of pgxfetch.
+type copyConnector struct {
+	driver.Connector
+	copied *[][]any
+}
+
+func (c copyConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	dc, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return copyConn{dc, c.copied}, nil
+}
+
+type copyConn struct {
+	driver.Conn
+	copied *[][]any
+}
+
+func (c copyConn) CopyFrom(_ context.Context, table string, columns []string, rows iter.Seq[[]any]) (int64, error) {
+	var n int64
+	for row := range rows {
+		if len(row) != len(columns) {
+			return n, fmt.Errorf("row %d has %d values for %d columns", n, len(row), len(columns))
+		}
+		*c.copied = append(*c.copied, row)
+		n++
+	}
+	return n, nil
+}
+
+func TestBulkInsertCopyFrom(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"INSERT INTO t (a, b) VALUES (?, ?), (?, ?)": {},
+	})
+	var copied [][]any
+	native := sql.OpenDB(copyConnector{db.Connector(), &copied})
+	defer native.Close()
+	n, err := BulkInsert(ctx, native, "t", []string{"a", "b"}, numberRows(2), nil)
+	if err != nil || n != 2 {
+		t.Errorf("got %d, %v, want 2 rows", n, err)
+	}
+	if want := [][]any{{int64(0), "0"}, {int64(1), "1"}}; !reflect.DeepEqual(copied, want) {
+		t.Errorf("copied %v, want %v", copied, want)
+	}
+	if calls := callLog(db); len(calls) != 0 {
+		t.Errorf("got calls %v, want none", calls)
+	}
+	bad := func(yield func([]any) bool) { yield([]any{1}) }
+	if _, err := BulkInsert(ctx, native, "t", []string{"a", "b"}, bad, nil); !errors.As(err, new(*QueryError)) {
+		t.Errorf("got %v, want a query error for a row with a missing value", err)
+	}
+	copied = nil
+	n, err = BulkInsert(ctx, native, "t", []string{"a", "b"}, numberRows(2), &BulkOptions{NoCopy: true})
+	if err != nil || n != 2 || copied != nil {
+		t.Errorf("got %d, %v and copied %v, want 2 rows inserted", n, err, copied)
+	}
+}
+
+func TestBulkQuery(t *testing.T) {
+	if got, want := bulkQuery("V ", 2, 2, true), "V ($1, $2), ($3, $4)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,49 @@
+package dbfetch
+
+import (
+	"context"
+)
+
+// RunChan runs the query of f in a new goroutine and sends every row as T on the returned channel.
+// Rows are mapped to T like in FetchAll, buffer is the capacity of the channel.
+// When the query is done, an error is sent on the error channel if it failed and both channels are closed.
+// Cancelling ctx stops the query; the rows channel need not be drained then.
+//
+//	rows, errc := dbfetch.RunChan[event](ctx, dbfetch.Fetch(db, `select id, payload from events`), 100)
+//	for range workers {
+//		go func() {
+//			for e := range rows {
+//				handle(e)
+//			}
+//		}()
+//	}
+//	if err := <-errc; err != nil {
+//		return err
+//	}
+func RunChan[T any](ctx context.Context, f *fetcher, buffer int, args ...any) (<-chan T, <-chan error) {
+	f = f.call()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	rows := make(chan T, max(buffer, 0))
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		defer close(rows)
+		var row T
+		err := f.scanInto(&row).
+			Yield(func() error {
+				select {
+				case rows <- row:
+					return nil
+				case <-ctx.Done():
+					return context.Cause(ctx)
+				}
+			}).
+			Run(ctx, args...)
+		if err != nil {
+			errc <- err
+		}
+	}()
+	return rows, errc
+}
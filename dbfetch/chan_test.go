@@ -0,0 +1,40 @@
+package dbfetch
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+func TestRunChan(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	rows, errc := RunChan[user](ctx, Fetch(db, "select users"), 1)
+	var got []user
+	for u := range rows {
+		got = append(got, u)
+	}
+	if err := <-errc; err != nil || !slices.Equal(got, users) {
+		t.Errorf("got %v, %v, want %v", got, err, users)
+	}
+	rows, errc = RunChan[user](ctx, Fetch(db, "select unknown"), 0)
+	if _, ok := <-rows; ok {
+		t.Errorf("got a row for an unknown query")
+	}
+	if err := <-errc; err == nil {
+		t.Errorf("no error for an unknown query")
+	}
+	cctx, cancel := context.WithCancel(ctx)
+	rows, errc = RunChan[user](cctx, Fetch(db, "select users"), 0)
+	<-rows
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v after cancel, want %v", err, context.Canceled)
+	}
+	for range rows {
+		// closed after the error
+	}
+}
@@ -0,0 +1,158 @@
+package dbfetch
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+)
+
+// scannedValue retrieves the plain value a scan destination points to.
+// Pointers are dereferenced and the types of database/sql like sql.NullString are converted,
+// both are nil for NULL. Byte slices are copied, the driver may reuse them for the next row.
+func scannedValue(ptr any) any {
+	if _, ok := ptr.(*skipped); ok {
+		return nil
+	}
+	v := reflect.ValueOf(ptr).Elem()
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if vr, ok := v.Interface().(driver.Valuer); ok && v.Type().PkgPath() == "database/sql" {
+		value, err := vr.Value()
+		if err != nil || value == nil {
+			return nil
+		}
+		v = reflect.ValueOf(value)
+	}
+	switch b := v.Interface().(type) {
+	case sql.RawBytes:
+		if b == nil {
+			return nil
+		}
+		return bytes.Clone(b)
+	case []byte:
+		if b == nil {
+			return nil
+		}
+		return bytes.Clone(b)
+	default:
+		return b
+	}
+}
+
+// sliceTarget retrieves the slice dst points to.
+func sliceTarget(dst any) (reflect.Value, error) {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return reflect.Value{}, fmt.Errorf("destination must be a non-nil pointer to a slice, got %T", dst)
+	}
+	return v.Elem(), nil
+}
+
+// YieldMap is like YieldColumns but passes each row as a map from column names to values.
+// The values are converted like in YieldColumns, NULL is nil. Every row gets a new map.
+// It replaces scan destinations set before.
+func (f *fetcher) YieldMap(yield func(row map[string]any) error) *fetcher {
+	f.dst = nil
+	f.initCols = f.deriveScan()
+	f.yield = func(r *run) error {
+		row := make(map[string]any, len(r.cols))
+		for i, ct := range r.cols {
+			row[ct.Name()] = scannedValue(r.dst[i])
+		}
+		return yield(row)
+	}
+	return f
+}
+
+// All runs the query and retrieves all rows as maps from column names to values, see YieldMap.
+// It replaces scan destinations and yield funcs set before.
+func (f *fetcher) All(ctx context.Context, args ...any) ([]map[string]any, error) {
+	f = f.call()
+	var rows []map[string]any
+	if f.expectRows > 0 {
+		rows = make([]map[string]any, 0, f.expectRows)
+	}
+	f.YieldMap(func(row map[string]any) error {
+		rows = append(rows, row)
+		return nil
+	})
+	if err := f.Run(ctx, args...); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// AllMaps runs query on db and retrieves all rows as maps from column names to values, see YieldMap.
+//
+//	rows, err := dbfetch.AllMaps(ctx, db, `select * from settings where scope = ?`, scope)
+func AllMaps(ctx context.Context, db Querier, query string, args ...any) ([]map[string]any, error) {
+	return Fetch(db, query).All(ctx, args...)
+}
+
+// AllInto runs the query and appends all rows to the slice dst points to.
+// Rows are mapped to the element type like in FetchAll.
+// It replaces scan destinations and yield funcs set before.
+//
+//	var users []user
+//	err := dbfetch.Fetch(db, `select id, login from users`).AllInto(ctx, &users)
+func (f *fetcher) AllInto(ctx context.Context, dst any, args ...any) error {
+	f = f.call()
+	slice, err := sliceTarget(dst)
+	if err != nil {
+		return err
+	}
+	row := reflect.New(slice.Type().Elem())
+	f.scanInto(row.Interface())
+	return f.appendRows(ctx, slice, row, args)
+}
+
+// Column runs a query with a single column and appends its values to the slice dst points to.
+// Unlike AllInto, the column is scanned into the elements even if they are structs.
+// It replaces scan destinations and yield funcs set before.
+//
+//	var logins []string
+//	err := dbfetch.Fetch(db, `select login from users`).Column(ctx, &logins)
+func (f *fetcher) Column(ctx context.Context, dst any, args ...any) error {
+	f = f.call()
+	slice, err := sliceTarget(dst)
+	if err != nil {
+		return err
+	}
+	row := reflect.New(slice.Type().Elem())
+	f.InitColumns(func(cts []*sql.ColumnType, err error) error {
+		if err != nil {
+			return err
+		}
+		if len(cts) != 1 {
+			return fmt.Errorf("expected a single column, got %d", len(cts))
+		}
+		f.dst = []any{row.Interface()}
+		return nil
+	})
+	return f.appendRows(ctx, slice, row, args)
+}
+
+// appendRows runs the query and appends the value row points to to slice after each scan.
+// On errors, slice is left unchanged.
+func (f *fetcher) appendRows(ctx context.Context, slice, row reflect.Value, args []any) error {
+	rows := slice
+	if f.expectRows > 0 {
+		rows = reflect.AppendSlice(reflect.MakeSlice(slice.Type(), 0, slice.Len()+f.expectRows), slice)
+	}
+	f.Yield(func() error {
+		rows = reflect.Append(rows, row.Elem())
+		return nil
+	})
+	if err := f.Run(ctx, args...); err != nil {
+		return err
+	}
+	slice.Set(rows)
+	return nil
+}
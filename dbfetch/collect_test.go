@@ -0,0 +1,219 @@
+package dbfetch
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+func TestAll(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	rows, err := Fetch(db, "select users").All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != len(users) {
+		t.Fatalf("got %d rows, want %d", len(rows), len(users))
+	}
+	for i, u := range users {
+		want := map[string]any{"id": u.ID, "user_login": u.Login}
+		if !maps.Equal(rows[i], want) {
+			t.Errorf("row %d: got %v, want %v", i, rows[i], want)
+		}
+	}
+}
+
+func TestAllInto(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	got := []user{{ID: 0, Login: "existing"}}
+	if err := Fetch(db, "select users").AllInto(ctx, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := append([]user{{ID: 0, Login: "existing"}}, users...)
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if err := Fetch(db, "select users").AllInto(ctx, got); err == nil {
+		t.Errorf("no error for a slice instead of a pointer")
+	}
+}
+
+func TestColumn(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"select login": {
+			Columns: dbfetchtest.Columns("login"),
+			Rows:    [][]any{{"alice"}, {"bob"}},
+		},
+	})
+	var got []string
+	if err := Fetch(db, "select login").Column(ctx, &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"alice", "bob"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	db = dbfetchtest.Open(t, usersQuery)
+	if err := Fetch(db, "select users").Column(ctx, &got); err == nil {
+		t.Errorf("no error for two columns")
+	}
+}
+
+func TestYieldMap(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"select settings": {
+			Columns: dbfetchtest.Columns("key", "value"),
+			Rows:    [][]any{{"theme", []byte("dark")}, {"lang", nil}},
+		},
+	})
+	var got []map[string]any
+	err := Fetch(db, "select settings").
+		YieldMap(func(row map[string]any) error {
+			got = append(got, row)
+			return nil
+		}).
+		Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0]["key"] != "theme" || string(got[0]["value"].([]byte)) != "dark" ||
+		got[1]["key"] != "lang" || got[1]["value"] != nil {
+		t.Errorf("got %v", got)
+	}
+	rows, err := AllMaps(ctx, db, "select settings")
+	if err != nil || len(rows) != 2 || rows[1]["value"] != nil {
+		t.Errorf("got %v, %v", rows, err)
+	}
+}
+
+func TestReuseBuffers(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	f := Fetch(db, "select users").ReuseBuffers(true)
+	first, err := f.All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := f.derived.dst
+	second, err := f.All(ctx)
+	if got := f.derived.dst; err != nil || len(dst) != 2 || len(got) != 2 || got[0] != dst[0] || got[1] != dst[1] {
+		t.Errorf("scan destinations were not reused: %v", err)
+	}
+	for i := range first {
+		if !maps.Equal(first[i], second[i]) {
+			t.Errorf("row %d: got %v, want %v", i, second[i], first[i])
+		}
+	}
+	f.ReuseBuffers(false).All(ctx)
+	if f.derived != nil {
+		t.Errorf("scan destinations kept without ReuseBuffers")
+	}
+}
+
+func TestExpectRows(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	got := []user{{ID: 0}}
+	if err := Fetch(db, "select users").ExpectRows(10).AllInto(ctx, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1+len(users) || cap(got) < 11 {
+		t.Errorf("got len %d and cap %d, want %d and at least 11", len(got), cap(got), 1+len(users))
+	}
+	rows, err := Fetch(db, "select users").ExpectRows(10).All(ctx)
+	if err != nil || len(rows) != len(users) || cap(rows) != 10 {
+		t.Errorf("got len %d and cap %d, %v, want %d and 10", len(rows), cap(rows), err, len(users))
+	}
+}
+
+func TestConcurrentCalls(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"select login": {
+			Columns: dbfetchtest.Columns("login"),
+			Rows:    [][]any{{"alice"}, {"bob"}},
+		},
+	})
+	// terminal methods keep their scan destinations and yield funcs to themselves
+	f := Fetch(db, "select login").ReuseBuffers(true)
+	want := []string{"alice", "bob"}
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			var got []string
+			switch i % 3 {
+			case 0:
+				if err := f.Column(ctx, &got); err != nil {
+					t.Error(err)
+				}
+			case 1:
+				if err := f.AllInto(ctx, &got); err != nil {
+					t.Error(err)
+				}
+			case 2:
+				rows, err := f.All(ctx)
+				if err != nil {
+					t.Error(err)
+				}
+				for _, row := range rows {
+					got = append(got, row["login"].(string))
+				}
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("call %d: got %v, want %v", i, got, want)
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func BenchmarkAll(b *testing.B) {
+	ctx := context.Background()
+	rows := make([][]any, 100)
+	for i := range rows {
+		rows[i] = []any{int64(i), "name", 1.5}
+	}
+	db := dbfetchtest.Open(b, map[string]dbfetchtest.Result{
+		"select rows": {Columns: dbfetchtest.Columns("id", "name", "score"), Rows: rows},
+	})
+	type row struct {
+		ID    int64
+		Name  string
+		Score float64
+	}
+	for _, tc := range []struct {
+		name    string
+		prepare func(*fetcher) *fetcher
+	}{
+		{"plain", func(f *fetcher) *fetcher { return f }},
+		{"hint", func(f *fetcher) *fetcher { return f.ExpectRows(len(rows)) }},
+		{"reuse", func(f *fetcher) *fetcher { return f.ReuseBuffers(true).ExpectRows(len(rows)) }},
+	} {
+		b.Run("AllInto/"+tc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			f := tc.prepare(Fetch(db, "select rows"))
+			for b.Loop() {
+				var got []row
+				if err := f.AllInto(ctx, &got); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run("YieldColumns/"+tc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			f := tc.prepare(Fetch(db, "select rows")).YieldColumns(func([]any) error { return nil })
+			for b.Loop() {
+				if err := f.Run(ctx); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
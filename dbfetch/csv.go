@@ -0,0 +1,90 @@
+package dbfetch
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// CSVOptions configure RunCSV. The zero value writes comma separated values with a header row.
+type CSVOptions struct {
+	// Comma is the field delimiter, ',' if it is zero.
+	Comma rune
+	// Null represents NULL, the empty string by default.
+	Null string
+	// NoHeader leaves out the header row with the column names.
+	NoHeader bool
+	// CRLF ends lines with \r\n as required by RFC 4180.
+	CRLF bool
+}
+
+// RunCSV runs the query and writes the rows to w as CSV with a header row, as they are read.
+// Byte slices are written as they are, times in RFC 3339 and other values are formatted with %v.
+// On errors, the output is incomplete. opts can be nil.
+// It replaces scan destinations and yield funcs set before.
+//
+//	w.Header().Set("Content-Type", "text/csv")
+//	err := dbfetch.Fetch(db, `select id, login, created from users`).
+//		RunCSV(ctx, w, &dbfetch.CSVOptions{Comma: ';', Null: "NULL"})
+func (f *fetcher) RunCSV(ctx context.Context, w io.Writer, opts *CSVOptions, args ...any) error {
+	f = f.call()
+	if opts == nil {
+		opts = &CSVOptions{}
+	}
+	cw := csv.NewWriter(w)
+	if opts.Comma != 0 {
+		cw.Comma = opts.Comma
+	}
+	cw.UseCRLF = opts.CRLF
+	var record []string
+	derive := f.deriveScan()
+	f.initCols = func(r *run, cts []*sql.ColumnType, err error) error {
+		if err := derive(r, cts, err); err != nil {
+			return err
+		}
+		record = make([]string, len(cts))
+		if opts.NoHeader {
+			return nil
+		}
+		for i, ct := range cts {
+			record[i] = ct.Name()
+		}
+		return cw.Write(record)
+	}
+	f.yield = func(r *run) error {
+		for i, ptr := range r.dst {
+			record[i] = csvField(scannedValue(ptr), opts.Null)
+		}
+		return cw.Write(record)
+	}
+	err := f.Run(ctx, args...)
+	cw.Flush()
+	if err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// csvField formats a column value for RunCSV.
+func csvField(v any, null string) string {
+	switch v := v.(type) {
+	case nil:
+		return null
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,44 @@
+package dbfetch
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+func TestRunCSV(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2024, 2, 29, 12, 30, 0, 0, time.UTC)
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"select users": usersQuery["select users"],
+		"select mixed": {
+			Columns: dbfetchtest.Columns("n", "text", "created", "empty"),
+			Rows: [][]any{
+				{1.5, []byte("a,\"b\""), created, nil},
+				{2.0, []byte("line\nbreak"), created, "x"},
+			},
+		},
+	})
+	for _, tc := range []struct {
+		query string
+		opts  *CSVOptions
+		want  string
+	}{
+		{"select users", nil, "id,user_login\n1,alice\n2,bob\n3,carol\n"},
+		{"select users", &CSVOptions{Comma: ';', NoHeader: true, CRLF: true}, "1;alice\r\n2;bob\r\n3;carol\r\n"},
+		{"select mixed", &CSVOptions{Null: "NULL"}, "n,text,created,empty\n" +
+			"1.5,\"a,\"\"b\"\"\",2024-02-29T12:30:00Z,NULL\n" +
+			"2,\"line\nbreak\",2024-02-29T12:30:00Z,x\n"},
+	} {
+		var buf bytes.Buffer
+		if err := Fetch(db, tc.query).RunCSV(ctx, &buf, tc.opts); err != nil {
+			t.Errorf("%s: %v", tc.query, err)
+		}
+		if got := buf.String(); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.query, got, tc.want)
+		}
+	}
+}
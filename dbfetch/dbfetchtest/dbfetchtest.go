@@ -0,0 +1,335 @@
+// Package dbfetchtest provides a fake database for tests of code using dbfetch or database/sql.
+// It answers queries with predefined results, including column types and NULL values,
+// and records the queries and statements it received.
+//
+//	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+//		"select id, login from users": {
+//			Columns: dbfetchtest.Columns("id", "login"),
+//			Rows:    [][]any{{1, "alice"}, {2, nil}},
+//		},
+//	})
+//	users, err := dbfetch.FetchAll[user](ctx, db, "select id, login from users")
+package dbfetchtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// Column describes a column of a Result.
+type Column struct {
+	Name string
+	// DatabaseType is reported by sql.ColumnType.DatabaseTypeName, e.g. "INT8" or "JSONB".
+	DatabaseType string
+	// ScanType is reported by sql.ColumnType.ScanType.
+	// If it is nil, the type of the first non-NULL value of the column is reported, any if there is none.
+	ScanType reflect.Type
+	// Nullable is reported by sql.ColumnType.Nullable.
+	Nullable bool
+}
+
+// Columns creates columns with names only.
+// They are reported as nullable if they contain NULL.
+func Columns(names ...string) []Column {
+	cols := make([]Column, len(names))
+	for i, name := range names {
+		cols[i] = Column{Name: name}
+	}
+	return cols
+}
+
+// Result is the result of a query or statement.
+type Result struct {
+	Columns []Column
+	// Rows contain the values of the columns, nil is NULL.
+	// Values are converted like query arguments, e.g. int to int64.
+	Rows [][]any
+	// Err is reported after the last row.
+	Err error
+	// RowsAffected and LastInsertID are the result of statements.
+	// LastInsertId fails if LastInsertID is 0, like for drivers without insert IDs.
+	RowsAffected, LastInsertID int64
+}
+
+// result is a Result with the rows converted to driver values.
+type result struct {
+	Result
+	rows [][]driver.Value
+}
+
+// Call is a query or statement received by a DB.
+type Call struct {
+	// Kind is one of "query", "exec", "prepare", "begin", "commit" and "rollback".
+	Kind  string
+	Query string
+	Args  []any
+}
+
+// DB is a fake database. It is a *sql.DB, so it also implements dbfetch.Querier.
+type DB struct {
+	*sql.DB
+	t testing.TB
+
+	mu      sync.Mutex
+	results map[string]*result
+	calls   []Call
+	errs    []error
+}
+
+// Open opens a fake database answering queries with results, keyed by the query.
+// Queries without a result fail. The database is closed when the test ends.
+func Open(t testing.TB, results map[string]Result) *DB {
+	t.Helper()
+	db := &DB{t: t, results: make(map[string]*result)}
+	for query, r := range results {
+		db.Set(query, r)
+	}
+	db.DB = sql.OpenDB(connector{db})
+	t.Cleanup(func() { db.DB.Close() })
+	return db
+}
+
+// Connector retrieves the connector of db, e.g. to open it with a wrapped driver.
+// Databases opened with it share the results and calls of db.
+func (db *DB) Connector() driver.Connector {
+	return connector{db}
+}
+
+// Set sets the result of query.
+func (db *DB) Set(query string, r Result) {
+	db.t.Helper()
+	rows := make([][]driver.Value, len(r.Rows))
+	for i, row := range r.Rows {
+		if len(row) != len(r.Columns) {
+			db.t.Fatalf("dbfetchtest: row %d of %q has %d values for %d columns", i, query, len(row), len(r.Columns))
+		}
+		rows[i] = make([]driver.Value, len(row))
+		for j, v := range row {
+			dv, err := driver.DefaultParameterConverter.ConvertValue(v)
+			if err != nil {
+				db.t.Fatalf("dbfetchtest: column %q of row %d of %q: %v", r.Columns[j].Name, i, query, err)
+			}
+			rows[i][j] = dv
+		}
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	r.Columns = slices.Clone(r.Columns)
+	db.results[query] = &result{Result: r, rows: rows}
+}
+
+// Fail makes the next queries and statements fail with errs, in order.
+func (db *DB) Fail(errs ...error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.errs = append(db.errs, errs...)
+}
+
+// Calls retrieves the queries and statements received since the last call of Calls.
+func (db *DB) Calls() []Call {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	calls := db.calls
+	db.calls = nil
+	return calls
+}
+
+func (db *DB) record(kind, query string, args []driver.NamedValue) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var values []any
+	for _, arg := range args {
+		values = append(values, arg.Value)
+	}
+	db.calls = append(db.calls, Call{Kind: kind, Query: query, Args: values})
+}
+
+// answer retrieves the result of query or the next error set with Fail.
+func (db *DB) answer(query string) (*result, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if len(db.errs) > 0 {
+		err := db.errs[0]
+		db.errs = db.errs[1:]
+		return nil, err
+	}
+	r, ok := db.results[query]
+	if !ok {
+		return nil, fmt.Errorf("dbfetchtest: unexpected query %q", query)
+	}
+	return r, nil
+}
+
+type connector struct {
+	db *DB
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return conn(c), nil
+}
+
+func (c connector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, fmt.Errorf("dbfetchtest: use Open")
+}
+
+type conn connector
+
+func (c conn) Prepare(query string) (driver.Stmt, error) {
+	c.db.record("prepare", query, nil)
+	return stmt{c, query}, nil
+}
+
+func (c conn) Close() error {
+	return nil
+}
+
+func (c conn) Begin() (driver.Tx, error) {
+	c.db.record("begin", "", nil)
+	return tx(c), nil
+}
+
+func (c conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.record("query", query, args)
+	r, err := c.db.answer(query)
+	if err != nil {
+		return nil, err
+	}
+	return &rows{result: r}, nil
+}
+
+func (c conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.record("exec", query, args)
+	r, err := c.db.answer(query)
+	if err != nil {
+		return nil, err
+	}
+	return execResult{r}, nil
+}
+
+// tx is a transaction without any effect.
+type tx conn
+
+func (t tx) Commit() error {
+	t.db.record("commit", "", nil)
+	return nil
+}
+
+func (t tx) Rollback() error {
+	t.db.record("rollback", "", nil)
+	return nil
+}
+
+type execResult struct {
+	result *result
+}
+
+func (r execResult) LastInsertId() (int64, error) {
+	if r.result.LastInsertID == 0 {
+		return 0, fmt.Errorf("dbfetchtest: no insert id")
+	}
+	return r.result.LastInsertID, nil
+}
+
+func (r execResult) RowsAffected() (int64, error) {
+	return r.result.RowsAffected, nil
+}
+
+type stmt struct {
+	conn  conn
+	query string
+}
+
+func (s stmt) Close() error {
+	return nil
+}
+
+func (s stmt) NumInput() int {
+	return -1
+}
+
+func (s stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, named(args))
+}
+
+func (s stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, named(args))
+}
+
+// named converts positional arguments.
+func named(args []driver.Value) []driver.NamedValue {
+	nv := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		nv[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return nv
+}
+
+type rows struct {
+	result *result
+	idx    int
+}
+
+func (r *rows) Columns() []string {
+	names := make([]string, len(r.result.Columns))
+	for i, col := range r.result.Columns {
+		names[i] = col.Name
+	}
+	return names
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.idx >= len(r.result.rows) {
+		if r.result.Err != nil {
+			return r.result.Err
+		}
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.idx])
+	r.idx++
+	return nil
+}
+
+func (r *rows) ColumnTypeScanType(index int) reflect.Type {
+	if t := r.result.Columns[index].ScanType; t != nil {
+		return t
+	}
+	for _, row := range r.result.rows {
+		if row[index] != nil {
+			return reflect.TypeOf(row[index])
+		}
+	}
+	return reflect.TypeFor[any]()
+}
+
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.result.Columns[index].DatabaseType
+}
+
+func (r *rows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	if r.result.Columns[index].Nullable {
+		return true, true
+	}
+	for _, row := range r.result.rows {
+		if row[index] == nil {
+			return true, true
+		}
+	}
+	return false, true
+}
@@ -0,0 +1,84 @@
+package dbfetchtest_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/arnehormann/goof/dbfetch"
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+func TestDB(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"select users": {
+			Columns: []dbfetchtest.Column{
+				{Name: "id", DatabaseType: "INT8"},
+				{Name: "login", DatabaseType: "TEXT", Nullable: true},
+			},
+			Rows: [][]any{{1, "alice"}, {2, nil}},
+		},
+		"delete users": {RowsAffected: 2},
+	})
+	var types []string
+	var nullable []bool
+	var rows [][]any
+	err := dbfetch.Fetch(db, "select users").
+		InitColumns(func(cts []*sql.ColumnType, err error) error {
+			for _, ct := range cts {
+				n, _ := ct.Nullable()
+				types = append(types, ct.DatabaseTypeName()+" "+ct.ScanType().String())
+				nullable = append(nullable, n)
+			}
+			return err
+		}).
+		ScanInto(new(int64), new(sql.NullString)).
+		Run(ctx, "arg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"INT8 int64", "TEXT string"}; !slices.Equal(types, want) || !slices.Equal(nullable, []bool{false, true}) {
+		t.Errorf("got types %v and nullable %v, want %v", types, nullable, want)
+	}
+	rows, err = collect(ctx, db)
+	if want := [][]any{{int64(1), "alice"}, {int64(2), nil}}; err != nil || !reflect.DeepEqual(rows, want) {
+		t.Errorf("got %v, %v, want %v", rows, err, want)
+	}
+	n, err := dbfetch.Fetch(db, "delete users").ExecAffected(ctx)
+	if err != nil || n != 2 {
+		t.Errorf("got %d, %v, want 2", n, err)
+	}
+	want := []dbfetchtest.Call{
+		{Kind: "query", Query: "select users", Args: []any{"arg"}},
+		{Kind: "query", Query: "select users"},
+		{Kind: "exec", Query: "delete users"},
+	}
+	if calls := db.Calls(); !reflect.DeepEqual(calls, want) {
+		t.Errorf("got calls %v, want %v", calls, want)
+	}
+
+	errBroken := errors.New("broken")
+	db.Fail(errBroken)
+	if _, err := collect(ctx, db); !errors.Is(err, errBroken) {
+		t.Errorf("got %v, want %v", err, errBroken)
+	}
+	if _, err := dbfetch.Fetch(db, "select unknown").All(ctx); err == nil {
+		t.Errorf("no error for an unknown query")
+	}
+}
+
+// collect retrieves the rows of the query "select users".
+func collect(ctx context.Context, db dbfetch.Querier) ([][]any, error) {
+	var rows [][]any
+	for row, err := range dbfetch.Fetch(db, "select users").Rows(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,190 @@
+package dbfetch
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Decoder decodes the value src of a column as received from the driver into dst, a pointer.
+// src is never nil, NULL sets the value dst points to to its zero value without calling the Decoder.
+type Decoder func(src, dst any) error
+
+// decoders is the global registry of decoders, keyed by upper case database type names.
+var decoders struct {
+	sync.RWMutex
+	byType map[string]Decoder
+}
+
+// RegisterDecoder registers the decoder for columns of the database type typeName
+// as reported by sql.ColumnType.DatabaseTypeName, e.g. "JSONB" or "UUID"; the case is ignored.
+// It is used by all fetchers without their own decoder for the type; nil removes it.
+//
+//	dbfetch.RegisterDecoder("JSONB", dbfetch.DecodeJSON)
+func RegisterDecoder(typeName string, d Decoder) {
+	decoders.Lock()
+	defer decoders.Unlock()
+	typeName = strings.ToUpper(typeName)
+	if d == nil {
+		delete(decoders.byType, typeName)
+		return
+	}
+	if decoders.byType == nil {
+		decoders.byType = make(map[string]Decoder)
+	}
+	decoders.byType[typeName] = d
+}
+
+// Decode sets the decoder for columns of the database type typeName for f, see RegisterDecoder.
+// Columns with a decoder are derived as any, so the decoded value is passed to YieldColumns or All.
+// With ScanInto or struct scanning, the decoder receives the pointer to the variable or field.
+func (f *fetcher) Decode(typeName string, d Decoder) *fetcher {
+	if f.decoders == nil {
+		f.decoders = make(map[string]Decoder)
+	}
+	f.decoders[strings.ToUpper(typeName)] = d
+	return f
+}
+
+// hasDecoders reports whether f or the global registry have any decoders.
+func (f *fetcher) hasDecoders() bool {
+	if len(f.decoders) > 0 {
+		return true
+	}
+	decoders.RLock()
+	defer decoders.RUnlock()
+	return len(decoders.byType) > 0
+}
+
+// decoderFor retrieves the decoder for the column ct, nil if there is none.
+func (f *fetcher) decoderFor(ct *sql.ColumnType) Decoder {
+	typeName := strings.ToUpper(ct.DatabaseTypeName())
+	if d, ok := f.decoders[typeName]; ok {
+		return d
+	}
+	decoders.RLock()
+	defer decoders.RUnlock()
+	return decoders.byType[typeName]
+}
+
+// columnDecoders retrieves the decoders of the columns, nil if none has one.
+func (f *fetcher) columnDecoders(cts []*sql.ColumnType) []Decoder {
+	var decs []Decoder
+	for i, ct := range cts {
+		d := f.decoderFor(ct)
+		if d == nil {
+			continue
+		}
+		if decs == nil {
+			decs = make([]Decoder, len(cts))
+		}
+		decs[i] = d
+	}
+	return decs
+}
+
+// decodeTarget is a scan destination passing the value to a Decoder.
+type decodeTarget struct {
+	decode Decoder
+	dst    any
+}
+
+func (t *decodeTarget) Scan(src any) error {
+	if src == nil {
+		reflect.ValueOf(t.dst).Elem().SetZero()
+		return nil
+	}
+	return t.decode(src, t.dst)
+}
+
+// decodeTargets wraps the destinations of columns with a decoder into wrapped; targets and wrapped are reused.
+func decodeTargets(decs []Decoder, dst []any, targets []decodeTarget, wrapped []any) []any {
+	wrapped = append(wrapped[:0], dst...)
+	for i, d := range decs {
+		if d == nil || i >= len(dst) {
+			continue
+		}
+		if _, ok := dst[i].(*skipped); ok {
+			continue
+		}
+		targets[i] = decodeTarget{decode: d, dst: dst[i]}
+		wrapped[i] = &targets[i]
+	}
+	return wrapped
+}
+
+// srcBytes retrieves the bytes of the column value src.
+func srcBytes(src any) ([]byte, error) {
+	switch src := src.(type) {
+	case []byte:
+		return src, nil
+	case string:
+		return []byte(src), nil
+	}
+	return nil, fmt.Errorf("unsupported value of type %T", src)
+}
+
+// DecodeJSON is a Decoder unmarshaling JSON, e.g. for the types JSON and JSONB.
+// It decodes into structs, maps, slices and *any.
+func DecodeJSON(src, dst any) error {
+	b, err := srcBytes(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
+
+// DecodeUUID is a Decoder for UUIDs in text form or as 16 bytes.
+// dst can point to a [16]byte array or a type based on it, a string or any; strings are formatted in the canonical text form.
+func DecodeUUID(src, dst any) error {
+	b, err := srcBytes(src)
+	if err != nil {
+		return err
+	}
+	var id [16]byte
+	switch len(b) {
+	case 16:
+		copy(id[:], b)
+	case 36:
+		if b[8] != '-' || b[13] != '-' || b[18] != '-' || b[23] != '-' {
+			return fmt.Errorf("invalid UUID %q", b)
+		}
+		h := make([]byte, 0, 32)
+		h = append(h, b[:8]...)
+		h = append(h, b[9:13]...)
+		h = append(h, b[14:18]...)
+		h = append(h, b[19:23]...)
+		h = append(h, b[24:]...)
+		if _, err := hex.Decode(id[:], h); err != nil {
+			return fmt.Errorf("invalid UUID %q: %w", b, err)
+		}
+	default:
+		return fmt.Errorf("invalid UUID %q", b)
+	}
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("UUID destination must be a non-nil pointer, got %T", dst)
+	}
+	v = v.Elem()
+	switch {
+	case v.Kind() == reflect.Array && v.Len() == 16 && v.Type().Elem().Kind() == reflect.Uint8:
+		reflect.Copy(v, reflect.ValueOf(id[:]))
+	case v.Kind() == reflect.String:
+		v.SetString(formatUUID(id))
+	case v.Kind() == reflect.Interface && v.NumMethod() == 0:
+		v.Set(reflect.ValueOf(formatUUID(id)))
+	default:
+		return fmt.Errorf("can not decode UUID into %T", dst)
+	}
+	return nil
+}
+
+// formatUUID formats id in the canonical text form.
+func formatUUID(id [16]byte) string {
+	h := hex.EncodeToString(id[:])
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
@@ -0,0 +1,86 @@
+package dbfetch
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+func TestDecode(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"select docs": {
+			Columns: []dbfetchtest.Column{
+				{Name: "id", DatabaseType: "INT8"},
+				{Name: "doc", DatabaseType: "jsonb"},
+				{Name: "ref", DatabaseType: "UUID"},
+			},
+			Rows: [][]any{
+				{int64(1), []byte(`{"name":"a","tags":["x"]}`), "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+				{int64(2), nil, []byte{0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}},
+			},
+		},
+	})
+	type doc struct {
+		Name string
+		Tags []string
+	}
+	type row struct {
+		ID  int64
+		Doc doc
+		Ref [16]byte
+	}
+	var (
+		r   row
+		got []row
+	)
+	err := Fetch(db, "select docs").
+		Decode("JSONB", DecodeJSON).
+		Decode("uuid", DecodeUUID).
+		scanInto(&r).
+		Yield(func() error {
+			got = append(got, r)
+			return nil
+		}).
+		Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := [16]byte{0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+	want := []row{{1, doc{"a", []string{"x"}}, ref}, {2, doc{}, ref}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	RegisterDecoder("jsonb", DecodeJSON)
+	RegisterDecoder("UUID", DecodeUUID)
+	t.Cleanup(func() {
+		RegisterDecoder("JSONB", nil)
+		RegisterDecoder("UUID", nil)
+	})
+	rows, err := Fetch(db, "select docs").All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRows := []map[string]any{
+		{"id": int64(1), "doc": map[string]any{"name": "a", "tags": []any{"x"}}, "ref": "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{"id": int64(2), "doc": nil, "ref": "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+	}
+	if !reflect.DeepEqual(rows, wantRows) {
+		t.Errorf("got %v, want %v", rows, wantRows)
+	}
+}
+
+func TestDecodeUUID(t *testing.T) {
+	var s string
+	for _, src := range []any{"6ba7b810-9dad-11d1-80b4", "6ba7b810x9dad-11d1-80b4-00c04fd430c8", "zba7b810-9dad-11d1-80b4-00c04fd430c8", 42} {
+		if err := DecodeUUID(src, &s); err == nil {
+			t.Errorf("no error for %v", src)
+		}
+	}
+	if err := DecodeUUID("6ba7b810-9dad-11d1-80b4-00c04fd430c8", new(int)); err == nil {
+		t.Errorf("no error for an int destination")
+	}
+}
@@ -0,0 +1,68 @@
+package dbfetch
+
+import (
+	"context"
+	"database/sql"
+)
+
+// ColumnInfo describes a column of a result as reported by the driver.
+// Properties the driver does not report are nil.
+type ColumnInfo struct {
+	Name string `json:"name"`
+	// DatabaseType is the database type name, e.g. "VARCHAR" or "INT8".
+	DatabaseType string `json:"databaseType,omitempty"`
+	// GoType is the Go type the driver scans the column into, e.g. "int64" or "sql.NullString".
+	GoType    string `json:"goType,omitempty"`
+	Nullable  *bool  `json:"nullable,omitempty"`
+	Length    *int64 `json:"length,omitempty"`
+	Precision *int64 `json:"precision,omitempty"`
+	Scale     *int64 `json:"scale,omitempty"`
+}
+
+// columnInfo describes ct.
+func columnInfo(ct *sql.ColumnType) ColumnInfo {
+	info := ColumnInfo{
+		Name:         ct.Name(),
+		DatabaseType: ct.DatabaseTypeName(),
+	}
+	if t := ct.ScanType(); t != nil {
+		info.GoType = t.String()
+	}
+	if nullable, ok := ct.Nullable(); ok {
+		info.Nullable = &nullable
+	}
+	if length, ok := ct.Length(); ok {
+		info.Length = &length
+	}
+	if precision, scale, ok := ct.DecimalSize(); ok {
+		info.Precision, info.Scale = &precision, &scale
+	}
+	return info
+}
+
+// Describe runs the query and describes the columns of its result without reading any rows.
+// The query is cancelled afterwards; the database may still have to start executing it,
+// so expensive queries should be described with a condition like "where false" or "limit 0".
+// It replaces scan destinations and yield funcs set before.
+//
+//	cols, err := dbfetch.Fetch(db, `select * from users limit 0`).Describe(ctx)
+func (f *fetcher) Describe(ctx context.Context, args ...any) ([]ColumnInfo, error) {
+	f = f.call()
+	var cols []ColumnInfo
+	f.dst = nil
+	f.yield = nil
+	f.InitColumns(func(cts []*sql.ColumnType, err error) error {
+		if err != nil {
+			return err
+		}
+		cols = make([]ColumnInfo, len(cts))
+		for i, ct := range cts {
+			cols[i] = columnInfo(ct)
+		}
+		return ErrStop
+	})
+	if err := f.Run(ctx, args...); err != nil {
+		return nil, err
+	}
+	return cols, nil
+}
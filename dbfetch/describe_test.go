@@ -0,0 +1,38 @@
+package dbfetch
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+func TestDescribe(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"select docs": {
+			Columns: []dbfetchtest.Column{
+				{Name: "id", DatabaseType: "INT8"},
+				{Name: "doc", DatabaseType: "JSONB"},
+			},
+			Rows: [][]any{{int64(1), nil}},
+		},
+	})
+	cols, err := Fetch(db, "select docs").Describe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(cols)
+	want := `[{"name":"id","databaseType":"INT8","goType":"int64","nullable":false},` +
+		`{"name":"doc","databaseType":"JSONB","goType":"interface {}","nullable":true}]`
+	if err != nil || string(got) != want {
+		t.Errorf("got %s, %v, want %s", got, err, want)
+	}
+	if calls := callLog(db); len(calls) != 1 {
+		t.Errorf("got calls %v, want a single query", calls)
+	}
+	if _, err := Fetch(db, "select unknown").Describe(ctx); err == nil {
+		t.Errorf("no error for an unknown query")
+	}
+}
@@ -0,0 +1,17 @@
+// Package dbfetch runs queries with database/sql and passes the rows to callbacks,
+// iterators or typed results with little boilerplate.
+//
+// # PostgreSQL with pgx
+//
+// The module github.com/arnehormann/goof/dbfetch/pgxfetch runs dbfetch on a pgxpool.Pool.
+// It is a separate module to keep pgx out of the dependencies of dbfetch.
+// pgxfetch.Open returns a *sql.DB whose queries run on pgx directly, with rows decoded by pgx
+// and the Go types of the decoded values reported as scan types:
+//
+//	db := pgxfetch.Open(pool)
+//	defer db.Close()
+//	users, err := dbfetch.FetchAll[user](ctx, db, `select id, login from users`)
+//
+// Column types are reported with the PostgreSQL type names, e.g. "JSONB" for Decode.
+// BulkInsert uses the native COPY FROM of pgx, other features only available natively require pgx directly.
+package dbfetch
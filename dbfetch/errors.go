@@ -0,0 +1,116 @@
+package dbfetch
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// Phase is the step of running a query in which a QueryError occurred.
+type Phase string
+
+const (
+	PhasePrepare Phase = "prepare"
+	PhaseQuery   Phase = "query"
+	PhaseColumns Phase = "columns"
+	PhaseScan    Phase = "scan"
+	PhaseRows    Phase = "rows"
+	PhaseExec    Phase = "exec"
+	PhaseResult  Phase = "result"
+)
+
+// Classifications of errors, use them with errors.Is on errors returned by dbfetch.
+var (
+	// ErrNotFound classifies queries without a result row, see ErrNoRows.
+	ErrNotFound = errors.New("dbfetch: not found")
+	// ErrConflict classifies integrity constraint violations (SQLSTATE class 23),
+	// serialization failures and deadlocks.
+	ErrConflict = errors.New("dbfetch: conflict")
+	// ErrTimeout classifies timeouts of the fetcher, the caller's context or the database server.
+	ErrTimeout = errors.New("dbfetch: timeout")
+	// ErrConnection classifies broken or refused connections (SQLSTATE class 08).
+	ErrConnection = errors.New("dbfetch: connection failure")
+)
+
+// QueryError reports a failed query.
+// Errors returned by yield funcs are not wrapped in it.
+type QueryError struct {
+	Query string
+	// Args are the types of the query arguments, their values are left out
+	// to keep secrets and personal data out of error messages and logs.
+	Args  []string
+	Phase Phase
+	Err   error
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("%s: %v for query %q", e.Phase, e.Err, e.Query)
+}
+
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the wrapped error is classified as target,
+// which is one of ErrNotFound, ErrConflict, ErrTimeout and ErrConnection.
+func (e *QueryError) Is(target error) bool {
+	return classify(e.Err) == target && target != nil
+}
+
+// newQueryError wraps err in a *QueryError.
+func newQueryError(query string, args []any, phase Phase, err error) error {
+	var types []string
+	if len(args) > 0 {
+		types = make([]string, len(args))
+		for i, arg := range args {
+			types[i] = fmt.Sprintf("%T", arg)
+		}
+	}
+	return &QueryError{Query: query, Args: types, Phase: phase, Err: err}
+}
+
+// sqlState retrieves the SQLSTATE of errors with a method SQLState() string,
+// which are provided by the PostgreSQL drivers lib/pq and pgx.
+func sqlState(err error) string {
+	var se interface{ SQLState() string }
+	if errors.As(err, &se) {
+		return se.SQLState()
+	}
+	return ""
+}
+
+// isConnectionError reports broken and refused connections.
+func isConnectionError(err error) bool {
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		strings.HasPrefix(sqlState(err), "08")
+}
+
+// isTimeout reports timeouts of the client, the network or the server.
+func isTimeout(err error) bool {
+	var ne net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.As(err, &ne) && ne.Timeout() || isServerTimeout(err)
+}
+
+// classify retrieves the classification of err, nil if it has none.
+func classify(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrNotFound
+	case isTimeout(err):
+		return ErrTimeout
+	case IsSerializationFailure(err) || strings.HasPrefix(sqlState(err), "23"):
+		return ErrConflict
+	case isConnectionError(err):
+		return ErrConnection
+	}
+	return nil
+}
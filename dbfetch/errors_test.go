@@ -0,0 +1,61 @@
+package dbfetch
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+func TestQueryError(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	db.Fail(syscall.ECONNRESET)
+	err := Fetch(db, "select users").Run(ctx, "secret", 42)
+	var qe *QueryError
+	if !errors.As(err, &qe) {
+		t.Fatalf("got error %v, want a *QueryError", err)
+	}
+	if qe.Query != "select users" || qe.Phase != PhaseQuery || !slices.Equal(qe.Args, []string{"string", "int"}) {
+		t.Errorf("got %+v", qe)
+	}
+	if !errors.Is(err, ErrConnection) || !errors.Is(err, syscall.ECONNRESET) || errors.Is(err, ErrTimeout) {
+		t.Errorf("got wrong classification of %v", err)
+	}
+
+	err = Fetch(db, "select none").One(ctx, new(int64), new(string))
+	if !errors.Is(err, ErrNotFound) || !errors.Is(err, ErrNoRows) || !errors.As(err, &qe) || qe.Phase != PhaseRows {
+		t.Errorf("got error %v, want a QueryError with ErrNotFound", err)
+	}
+
+	for _, tc := range []struct {
+		err  error
+		want error
+	}{
+		{sqlStateError("23505"), ErrConflict},
+		{sqlStateError("40P01"), ErrConflict},
+		{sqlStateError("08006"), ErrConnection},
+		{sqlStateError("57014"), ErrTimeout},
+		{sqlStateError("42601"), nil},
+	} {
+		db.Fail(tc.err)
+		err := Fetch(db, "select users").Run(ctx)
+		for _, class := range []error{ErrNotFound, ErrConflict, ErrTimeout, ErrConnection} {
+			if got := errors.Is(err, class); got != (class == tc.want) {
+				t.Errorf("%v: errors.Is(%v) got %v", tc.err, class, got)
+			}
+		}
+	}
+
+	err = Fetch(db, "select users").Timeout(time.Millisecond).Yield(func() error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}).Run(ctx)
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("got error %v, want ErrTimeout", err)
+	}
+}
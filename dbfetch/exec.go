@@ -0,0 +1,170 @@
+package dbfetch
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Exec runs the query as a statement without result rows, e.g. INSERT, UPDATE or DELETE.
+// Like Run, it uses a prepared statement with UseStmt and the arguments set with Args if args is empty.
+// Scan destinations and yield funcs are not used.
+// With Retry, the statement is repeated after transient errors; it should be idempotent.
+func (f *fetcher) Exec(ctx context.Context, args ...any) (sql.Result, error) {
+	return f.execWith(ctx, &f.settings, args)
+}
+
+// execWith is Exec with the settings s.
+func (f *fetcher) execWith(ctx context.Context, s *settings, args []any) (sql.Result, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if len(args) == 0 {
+		args = f.args
+	}
+	if err := f.checkReadOnly(args); err != nil {
+		return nil, err
+	}
+	r := f.newRun()
+	tctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	var res sql.Result
+	err := f.retried(tctx, s, func(*bool) error {
+		return f.inSession(tctx, r, func() error {
+			return f.observed(tctx, args, func(rows *int64) error {
+				var err error
+				res, err = f.exec(tctx, r, args)
+				if err == nil {
+					// zero if the driver does not report it
+					*rows, _ = res.RowsAffected()
+				}
+				return err
+			})
+		})
+	})
+	if err != nil {
+		return nil, f.timeoutError(ctx, s, err)
+	}
+	return res, nil
+}
+
+// exec is a single attempt of Exec with the state r.
+func (f *fetcher) exec(ctx context.Context, r *run, args []any) (sql.Result, error) {
+	stmt, release, err := f.statement(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	var res sql.Result
+	if stmt != nil {
+		res, err = stmt.ExecContext(ctx, args...)
+	} else {
+		res, err = r.db.ExecContext(ctx, f.query, args...)
+	}
+	if err != nil {
+		return nil, newQueryError(f.query, args, PhaseExec, err)
+	}
+	return res, nil
+}
+
+// ExecAffected is Exec retrieving the number of rows affected by the statement.
+func (f *fetcher) ExecAffected(ctx context.Context, args ...any) (int64, error) {
+	res, err := f.Exec(ctx, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, newQueryError(f.query, args, PhaseResult, err)
+	}
+	return n, nil
+}
+
+// ExecInsertID is Exec retrieving the id of the inserted row.
+// Not all drivers support it, e.g. PostgreSQL requires INSERT ... RETURNING instead.
+func (f *fetcher) ExecInsertID(ctx context.Context, args ...any) (int64, error) {
+	res, err := f.Exec(ctx, args...)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, newQueryError(f.query, args, PhaseResult, err)
+	}
+	return id, nil
+}
+
+// hasReturning reports whether query contains a RETURNING clause.
+func hasReturning(query string) bool {
+	upper := strings.ToUpper(query)
+	for i := 0; ; {
+		j := strings.Index(upper[i:], "RETURNING")
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len("RETURNING")
+		if (start == 0 || !isIdentByte(upper[start-1])) && (end == len(upper) || !isIdentByte(upper[end])) {
+			return true
+		}
+		i = end
+	}
+}
+
+// isIdentByte reports whether c can be part of an unquoted identifier.
+func isIdentByte(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '_'
+}
+
+// ExecReturning runs an INSERT, UPDATE or DELETE statement and scans its result into dst.
+// Statements with a RETURNING clause are run as a query; each returned row is scanned into dst
+// and passed to the yield func if one is set. Without a yield func, a statement returning no rows
+// is reported as ErrNoRows. Statements without RETURNING are run with Exec and the id of the
+// inserted row is scanned into dst, which must be a single pointer to an integer then.
+// Query arguments are set with Args.
+//
+//	var id int64
+//	err := dbfetch.Fetch(db, `insert into users (login) values ($1) returning id`).
+//		Args(login).
+//		ExecReturning(ctx, &id)
+func (f *fetcher) ExecReturning(ctx context.Context, dst ...any) error {
+	if !hasReturning(f.query) {
+		if len(dst) != 1 {
+			return fmt.Errorf("expected a single destination for the insert id, got %d", len(dst))
+		}
+		v := reflect.ValueOf(dst[0])
+		if v.Kind() != reflect.Pointer || v.IsNil() || !v.Elem().CanInt() && !v.Elem().CanUint() {
+			return fmt.Errorf("insert id destination must be a pointer to an integer, got %T", dst[0])
+		}
+		id, err := f.ExecInsertID(ctx)
+		if err != nil {
+			return err
+		}
+		if v.Elem().CanInt() {
+			v.Elem().SetInt(id)
+		} else {
+			v.Elem().SetUint(uint64(id))
+		}
+		return nil
+	}
+	f = f.call()
+	yield := f.yield
+	n := 0
+	f.initCols = nil
+	f.dst = dst
+	f.yield = func(r *run) error {
+		n++
+		if yield != nil {
+			return yield(r)
+		}
+		return nil
+	}
+	if err := f.Run(ctx); err != nil {
+		return err
+	}
+	if n == 0 && yield == nil {
+		return newQueryError(f.query, f.args, PhaseRows, ErrNoRows)
+	}
+	return nil
+}
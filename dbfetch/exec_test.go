@@ -0,0 +1,89 @@
+package dbfetch
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+var execQueries = map[string]dbfetchtest.Result{
+	"insert user":  {RowsAffected: 1, LastInsertID: 4},
+	"delete users": {RowsAffected: 3},
+}
+
+func TestExec(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, execQueries)
+	if id, err := Fetch(db, "insert user").ExecInsertID(ctx, "dave"); err != nil || id != 4 {
+		t.Errorf("got id %d, %v, want 4", id, err)
+	}
+	if n, err := Fetch(db, "delete users").UseStmt(true).ExecAffected(ctx); err != nil || n != 3 {
+		t.Errorf("got %d affected rows, %v, want 3", n, err)
+	}
+	if _, err := Fetch(db, "delete users").ExecInsertID(ctx); err == nil {
+		t.Errorf("no error for a missing insert id")
+	}
+	if _, err := Fetch(db, "drop users").Exec(ctx); err == nil {
+		t.Errorf("no error for a failing statement")
+	}
+	want := []string{"exec insert user", "prepare delete users", "exec delete users", "exec delete users", "exec drop users"}
+	if calls := callLog(db); !slices.Equal(calls, want) {
+		t.Errorf("got calls %v, want %v", calls, want)
+	}
+}
+
+func TestExecReturning(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"insert into users (login) values ($1) returning id": {
+			Columns: dbfetchtest.Columns("id"),
+			Rows:    [][]any{{int64(7)}},
+		},
+		"update users set active = true returning id, login": usersQuery["select users"],
+		"delete from users where id = 0 RETURNING id":        {Columns: dbfetchtest.Columns("id")},
+		"insert into users (login) values (?)":               {LastInsertID: 8},
+	})
+	var id int64
+	err := Fetch(db, "insert into users (login) values ($1) returning id").Args("dave").ExecReturning(ctx, &id)
+	if err != nil || id != 7 {
+		t.Errorf("got %d, %v, want 7", id, err)
+	}
+	var ids []int64
+	var login string
+	err = Fetch(db, "update users set active = true returning id, login").
+		Yield(func() error {
+			ids = append(ids, id)
+			return nil
+		}).
+		ExecReturning(ctx, &id, &login)
+	if err != nil || len(ids) != len(users) {
+		t.Errorf("got %v, %v, want %d ids", ids, err, len(users))
+	}
+	if err := Fetch(db, "delete from users where id = 0 RETURNING id").ExecReturning(ctx, &id); !errors.Is(err, ErrNoRows) {
+		t.Errorf("got %v, want %v", err, ErrNoRows)
+	}
+	var uid uint32
+	err = Fetch(db, "insert into users (login) values (?)").Args("erin").ExecReturning(ctx, &uid)
+	if err != nil || uid != 8 {
+		t.Errorf("got %d, %v, want 8", uid, err)
+	}
+	if err := Fetch(db, "insert into users (login) values (?)").ExecReturning(ctx, &login); err == nil {
+		t.Errorf("no error for a string insert id")
+	}
+}
+
+func TestHasReturning(t *testing.T) {
+	for query, want := range map[string]bool{
+		"insert into t (a) values (1) returning id": true,
+		"INSERT INTO t (a) VALUES (1)\nRETURNING *": true,
+		"insert into returning_log (a) values (1)":  false,
+		"update t set not_returning = 1":            false,
+	} {
+		if got := hasReturning(query); got != want {
+			t.Errorf("hasReturning(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
@@ -3,36 +3,128 @@ package dbfetch
 import (
 	"context"
 	"database/sql"
-	"fmt"
+	"errors"
 	"reflect"
+	"slices"
+	"sync"
+	"time"
 )
 
-type querror struct {
-	query string
-	err   error
+// ErrStop can be returned by yield funcs to stop reading rows.
+// Run closes the rows and reports no error.
+//
+//	var first string
+//	err := dbfetch.Fetch(db, `select login from users order by created`).
+//		ScanInto(&first).
+//		Yield(func() error {
+//			if strings.HasPrefix(first, "a") {
+//				return dbfetch.ErrStop
+//			}
+//			return nil
+//		}).
+//		Run(ctx)
+var ErrStop = errors.New("dbfetch: stop")
+
+// Querier runs queries and statements. It is implemented by *sql.DB, *sql.Tx and *sql.Conn.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+var (
+	_ Querier = (*sql.DB)(nil)
+	_ Querier = (*sql.Tx)(nil)
+	_ Querier = (*sql.Conn)(nil)
+)
+
+// settings tune single runs of a fetcher.
+type settings struct {
+	// timeout limits the duration of Run and Exec, zero for none
+	timeout time.Duration
+	// cancelRow is the number of rows after which Run stops, zero for all
+	cancelRow int
+	// maxRows is the number of rows Run accepts, zero for all
+	maxRows int
+	// truncate stops Run at maxRows instead of failing
+	truncate bool
+	// retry is the policy for failed attempts, nil for none
+	retry *RetryPolicy
 }
 
-func (e querror) Error() string {
-	return fmt.Sprintf("%v for query %q", e.err, e.query)
+// run is the state of a single call of Run or Exec, so concurrent runs of a fetcher do not share it.
+type run struct {
+	// db and cache are those of the fetcher or the connection of a session
+	db    Querier
+	cache *StmtCache
+	// dst are the scan destinations of the fetcher or set by initCols
+	dst []any
+	// derived reports dst was derived from the column types and can be kept with ReuseBuffers
+	derived bool
+	// textTimes marks the derived destinations of columns parsed with ParseTextTimes
+	textTimes []bool
+	// initCols is the initCols of the fetcher, it derives dst if neither is set
+	initCols func(r *run, cts []*sql.ColumnType, err error) error
+	// cols are the column types of the result, they are only retrieved for initCols
+	cols []*sql.ColumnType
+	// values and batch are reused by the yield funcs of YieldColumns and YieldBatch
+	values []any
+	batch  [][]any
 }
 
 type fetcher struct {
-	db    *sql.DB
+	db    Querier
 	query string
+	// stmt is a statement prepared by the caller, it is used instead of db and query
+	stmt *sql.Stmt
 	// use prepared statement; relevant for MySQL binary instead of text protocol
 	asStmt bool
+	// cache provides prepared statements, it implies asStmt
+	cache *StmtCache
+	// hooks are notified about queries, the global hooks are used if it is nil
+	hooks *Hooks
+	// log configures query logging, nil for none
+	log *LogOptions
+	// sensitive are the indexes of arguments redacted in logs
+	sensitive []int
+	// loc is the location scanned times are converted to, nil to keep them
+	loc *time.Location
+	// textLoc is the location of timestamps received as text, nil to keep them as text
+	textLoc *time.Location
+	// session configures a pinned connection for Run and Exec, nil for none
+	session *SessionOptions
+	// readOnly rejects statements failing CheckReadOnly with forbidden
+	readOnly  bool
+	forbidden []string
+	// derived keeps derived scan destinations for further runs with the same column types, nil without ReuseBuffers
+	derived *derivedScan
+	// expectRows is the expected number of rows to preallocate results, zero if unknown
+	expectRows int
+	// progress is called every progressEvery rows, see Progress
+	progress      func(rows int64, elapsed time.Duration)
+	progressEvery int64
+	// decoders by upper case database type name, they take precedence over the registered ones
+	decoders map[string]Decoder
+	// settings can be overridden per run with RunWith and ExecWith
+	settings
 	// rows.Scan target pointers. Will be derived if nil
 	dst []any
 	// query arguments
 	args []any
 	// initCols is called before the first call to rows.Scan followed by yield;
-	// it can still change dst.
-	initCols func([]*sql.ColumnType, error) error
+	// it can still change the scan destinations of the run.
+	initCols func(r *run, cts []*sql.ColumnType, err error) error
 	// yield is called once per row
-	yield func() error
+	yield func(r *run) error
+	// flush is called at the end of each attempt of Run, deliver is false if it failed
+	flush func(r *run, deliver bool) error
 }
 
-func Fetch(db *sql.DB, query string) *fetcher {
+// Fetch creates a fetcher for query.
+// db can be a *sql.DB, a *sql.Tx, a *sql.Conn or any other Querier.
+// Terminal methods like All, Column or One keep their scan destinations and yield funcs to the call,
+// so goroutines can share a fetcher configured once and call them concurrently.
+func Fetch(db Querier, query string) *fetcher {
 	f := &fetcher{
 		db:    db,
 		query: query,
@@ -40,28 +132,166 @@ func Fetch(db *sql.DB, query string) *fetcher {
 	return f
 }
 
-func (f *fetcher) deriveScan() func([]*sql.ColumnType, error) error {
-	// add a default function to derive scan types
-	return func(cts []*sql.ColumnType, err error) error {
+// FetchStmt creates a fetcher for a statement prepared by the caller.
+// The statement is not closed by Run. UseStmt has no effect.
+// Use tx.StmtContext to run it inside a transaction.
+func FetchStmt(stmt *sql.Stmt) *fetcher {
+	return &fetcher{
+		stmt:  stmt,
+		query: "prepared statement",
+	}
+}
+
+// derivedScan keeps derived scan destinations for ReuseBuffers.
+// It is shared by the copies of a fetcher made for its terminal methods.
+type derivedScan struct {
+	// mu guards dst, it is taken by a run and returned afterwards
+	mu  sync.Mutex
+	dst []any
+}
+
+// call copies f for a single call of a terminal method.
+// Terminal methods set scan destinations and callbacks on the copy,
+// so concurrent calls on a shared fetcher do not interfere.
+func (f *fetcher) call() *fetcher {
+	c := *f
+	return &c
+}
+
+// newRun creates the state of a run of f.
+func (f *fetcher) newRun() *run {
+	r := &run{
+		db:       f.db,
+		cache:    f.cache,
+		dst:      f.dst,
+		initCols: f.initCols,
+	}
+	if r.initCols == nil && r.dst == nil {
+		// derive scan types just before rows.Scan
+		r.initCols = f.deriveScan()
+	}
+	return r
+}
+
+// deriveScan creates an initCols func setting the scan destinations of a run for the column types.
+func (f *fetcher) deriveScan() func(r *run, cts []*sql.ColumnType, err error) error {
+	return func(r *run, cts []*sql.ColumnType, err error) error {
 		if err != nil {
 			return err
 		}
-		scan := make([]any, len(cts))
+		types := make([]reflect.Type, len(cts))
+		r.textTimes = nil
 		for i, ct := range cts {
-			v := reflect.New(ct.ScanType())
-			scan[i] = v.Interface()
+			if f.decoderFor(ct) != nil {
+				// receives the decoded value
+				types[i] = reflect.TypeFor[any]()
+				continue
+			}
+			if f.isTextTime(ct) {
+				// receives the parsed time
+				if r.textTimes == nil {
+					r.textTimes = make([]bool, len(cts))
+				}
+				r.textTimes[i] = true
+				types[i] = reflect.TypeFor[any]()
+				continue
+			}
+			types[i] = scanTargetType(ct)
+		}
+		r.derived = true
+		if d := f.derived; d != nil {
+			d.mu.Lock()
+			derived := d.dst
+			if slices.EqualFunc(derived, types, func(dst any, t reflect.Type) bool {
+				return reflect.TypeOf(dst).Elem() == t
+			}) {
+				// concurrent runs derive their own
+				d.dst = nil
+				d.mu.Unlock()
+				r.dst = derived
+				return nil
+			}
+			d.mu.Unlock()
+		}
+		scan := make([]any, len(cts))
+		for i, t := range types {
+			scan[i] = reflect.New(t).Interface()
 		}
-		f.dst = scan
+		r.dst = scan
 		return nil
 	}
 }
 
+// keepDerived keeps the derived scan destinations of r for further runs with ReuseBuffers.
+func (f *fetcher) keepDerived(r *run) {
+	d := f.derived
+	if d == nil || !r.derived {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.dst = r.dst
+}
+
+// scanTarget allocates a scan destination for a column, see scanTargetType.
+func scanTarget(ct *sql.ColumnType) any {
+	return reflect.New(scanTargetType(ct)).Interface()
+}
+
+// scanTargetType retrieves the type of the scan destination for a column.
+// Nullable columns with a scan type not accepting NULL are scanned into a pointer, nil for NULL.
+func scanTargetType(ct *sql.ColumnType) reflect.Type {
+	t := ct.ScanType()
+	if t == nil {
+		return reflect.TypeFor[any]()
+	}
+	if nullable, ok := ct.Nullable(); (nullable || !ok) && !acceptsNull(t) {
+		t = reflect.PointerTo(t)
+	}
+	return t
+}
+
+// acceptsNull reports whether database/sql can scan NULL into a value of type t.
+func acceptsNull(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Slice:
+		// slices are []byte and sql.RawBytes
+		return true
+	}
+	return reflect.PointerTo(t).Implements(scannerType)
+}
+
 // UseStmt defines whether the query should be run as a prepared statement.
 func (f *fetcher) UseStmt(p bool) *fetcher {
 	f.asStmt = p
 	return f
 }
 
+// ReuseBuffers keeps the derived scan destinations, e.g. of All or YieldColumns, for further runs of f
+// returning the same column types, to save allocations when f is run repeatedly.
+// Values passed on are copied like before, so reusing is safe for them.
+func (f *fetcher) ReuseBuffers(reuse bool) *fetcher {
+	switch {
+	case !reuse:
+		f.derived = nil
+	case f.derived == nil:
+		f.derived = &derivedScan{}
+	}
+	return f
+}
+
+// ExpectRows is a hint for the number of rows, All, AllInto and Column preallocate space for them.
+func (f *fetcher) ExpectRows(n int) *fetcher {
+	f.expectRows = max(n, 0)
+	return f
+}
+
+// Args sets query arguments used by Run and terminal methods without arguments, e.g. One.
+func (f *fetcher) Args(args ...any) *fetcher {
+	f.args = args
+	return f
+}
+
 // ScanInto sets scan destinations.
 // It expects a slice of pointers to all variables for the column values.
 //
@@ -80,19 +310,71 @@ func (f *fetcher) ScanInto(ptrs ...any) *fetcher {
 }
 
 // Yield sets a func that is called once for each row.
+// Returning ErrStop ends Run without an error, other errors are reported by Run.
 //
 // Use it with ScanInto (see example there).
 func (f *fetcher) Yield(yield func() error) *fetcher {
-	f.yield = yield
+	f.yield = nil
+	if yield != nil {
+		f.yield = func(*run) error {
+			return yield()
+		}
+	}
 	return f
 }
 
-// YieldColumns is like Yield but will get a slice of pointers to column values each row.
-// Do not change the slice contents, it must only ever be read.
+// YieldColumns is like Yield but will get a slice of the column values each row.
+// The values are plain Go values like int64 or string read from the scan destinations,
+// NULL is nil. Byte slices are copied, the slice itself is reused for the next row.
 // YieldColumns is less efficient than yield.
 func (f *fetcher) YieldColumns(yield func([]any) error) *fetcher {
-	f.yield = func() error {
-		return yield(f.dst)
+	f.yield = func(r *run) error {
+		r.values = slices.Grow(r.values[:0], len(r.dst))[:len(r.dst)]
+		for i, ptr := range r.dst {
+			r.values[i] = scannedValue(ptr)
+		}
+		return yield(r.values)
+	}
+	return f
+}
+
+// YieldBatch is like YieldColumns but collects up to n rows and passes them to yield together.
+// The last batch can be smaller. The slices are reused for the next batch.
+// Rows already collected are passed to yield when Run is stopped with ErrStop or Cancel,
+// but not when it fails.
+//
+//	err := dbfetch.Fetch(db, `select id, payload from events`).
+//		YieldBatch(1000, func(rows [][]any) error {
+//			return index.Add(ctx, rows)
+//		}).
+//		Run(ctx)
+func (f *fetcher) YieldBatch(n int, yield func(rows [][]any) error) *fetcher {
+	n = max(n, 1)
+	f.yield = func(r *run) error {
+		if r.batch == nil {
+			r.batch = make([][]any, 0, n)
+		}
+		// reuse the row of a previous batch
+		r.batch = r.batch[:len(r.batch)+1]
+		row := slices.Grow(r.batch[len(r.batch)-1][:0], len(r.dst))[:len(r.dst)]
+		for i, ptr := range r.dst {
+			row[i] = scannedValue(ptr)
+		}
+		r.batch[len(r.batch)-1] = row
+		if len(r.batch) < n {
+			return nil
+		}
+		rows := r.batch
+		r.batch = r.batch[:0]
+		return yield(rows)
+	}
+	f.flush = func(r *run, deliver bool) error {
+		rows := r.batch
+		r.batch = r.batch[:0]
+		if !deliver || len(rows) == 0 {
+			return nil
+		}
+		return yield(rows)
 	}
 	return f
 }
@@ -100,71 +382,192 @@ func (f *fetcher) YieldColumns(yield func([]any) error) *fetcher {
 // HandleColumns receives a function that will be called on results before the first
 // yield is called.
 // The func cols will receive the result of database/sql:Rows.ColumnTypes().
-// If an error is reported, the whole query will be cancelled.
+// If an error is reported, the whole query will be cancelled; ErrStop ends Run without reading rows or an error.
 //
 // When used with MySQL, f.Prepared(true) should be used if you intend to use numeric types.
 // MySQL only uses a typed result in binary protocol, which is only used with prepared statements.
 // Text protocol only returns string values as sql.RawBytes.
 func (f *fetcher) InitColumns(initCols func([]*sql.ColumnType, error) error) *fetcher {
 	// requires f.prepared = true for MySQL
-	f.initCols = initCols
+	f.initCols = nil
+	if initCols != nil {
+		f.initCols = func(r *run, cts []*sql.ColumnType, err error) error {
+			err = initCols(cts, err)
+			// initCols can set scan destinations with ScanInto
+			r.dst = f.dst
+			return err
+		}
+	}
 	return f
 }
 
+// statement retrieves the prepared statement to run, nil to run the query directly on r.db.
+// release must be called when the statement is not needed anymore.
+func (f *fetcher) statement(ctx context.Context, r *run) (stmt *sql.Stmt, release func(), err error) {
+	if f.stmt != nil {
+		return f.stmt, func() {}, nil
+	}
+	if r.cache != nil {
+		stmt, done, err := r.cache.prepare(ctx, f.query)
+		if err != nil {
+			return nil, nil, newQueryError(f.query, nil, PhasePrepare, err)
+		}
+		if tx, ok := r.db.(*sql.Tx); ok {
+			txStmt := tx.StmtContext(ctx, stmt)
+			return txStmt, func() {
+				txStmt.Close()
+				done()
+			}, nil
+		}
+		return stmt, done, nil
+	}
+	if !f.asStmt {
+		return nil, func() {}, nil
+	}
+	stmt, err = r.db.PrepareContext(ctx, f.query)
+	if err != nil {
+		return nil, nil, newQueryError(f.query, nil, PhasePrepare, err)
+	}
+	return stmt, func() { stmt.Close() }, nil
+}
+
 // Run the query.
-func (f *fetcher) Run(ctx context.Context, args ...any) (err error) {
+// Without args, the arguments set with Args are used.
+func (f *fetcher) Run(ctx context.Context, args ...any) error {
+	return f.runWith(ctx, &f.settings, args)
+}
+
+// runWith is Run with the settings s.
+func (f *fetcher) runWith(ctx context.Context, s *settings, args []any) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	if f.initCols == nil && f.dst == nil {
-		// derive scan types just before rows.Scan
-		f.initCols = f.deriveScan()
+	if len(args) == 0 {
+		args = f.args
+	}
+	if err := f.checkReadOnly(args); err != nil {
+		return err
 	}
+	r := f.newRun()
+	defer f.keepDerived(r)
+	tctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	err := f.retried(tctx, s, func(delivered *bool) error {
+		return f.inSession(tctx, r, func() error {
+			return f.observed(tctx, args, func(rows *int64) error {
+				return f.run(tctx, r, s, args, delivered, rows)
+			})
+		})
+	})
+	return f.timeoutError(ctx, s, err)
+}
+
+// run is a single attempt of Run with the state r. It sets delivered before the first call of yield
+// and counts the rows it read in nrows.
+func (f *fetcher) run(ctx context.Context, r *run, s *settings, args []any, delivered *bool, nrows *int64) (err error) {
+	start := time.Now()
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
+	stmt, release, err := f.statement(ctx, r)
+	if err != nil {
+		return err
+	}
+	defer release()
 	var rows *sql.Rows
-	if f.asStmt {
-		var stmt *sql.Stmt
-		stmt, err = f.db.PrepareContext(ctx, f.query)
-		if err != nil {
-			err = querror{f.query, err}
-			return
-		}
-		defer stmt.Close()
+	if stmt != nil {
 		rows, err = stmt.QueryContext(ctx, args...)
 	} else {
-		rows, err = f.db.QueryContext(ctx, f.query, args...)
+		rows, err = r.db.QueryContext(ctx, f.query, args...)
 	}
 	if err != nil {
-		err = querror{f.query, err}
-		return err
+		return newQueryError(f.query, args, PhaseQuery, err)
 	}
 	defer func() {
-		cerr := rows.Close()
-		if err == nil {
-			err = cerr
+		if cerr := rows.Close(); err == nil && cerr != nil {
+			err = newQueryError(f.query, args, PhaseRows, cerr)
 		}
 	}()
-	if f.initCols != nil {
+	if f.flush != nil {
+		defer func() {
+			ferr := f.flush(r, err == nil)
+			if err == nil && !errors.Is(ferr, ErrStop) {
+				err = ferr
+			}
+		}()
+	}
+	if r.initCols != nil {
 		// for MySQL this should be used with f.Prepared(true)
-		err = f.initCols(rows.ColumnTypes())
+		r.cols, err = rows.ColumnTypes()
+		err = r.initCols(r, r.cols, err)
+		if errors.Is(err, ErrStop) {
+			return nil
+		}
 		if err != nil {
-			err = querror{f.query, err}
-			return err
+			return newQueryError(f.query, args, PhaseColumns, err)
 		}
 	}
+	var (
+		decs    []Decoder
+		targets []decodeTarget
+		wrapped []any
+	)
+	if f.hasDecoders() {
+		cts, err := rows.ColumnTypes()
+		if err != nil {
+			return newQueryError(f.query, args, PhaseColumns, err)
+		}
+		if decs = f.columnDecoders(cts); decs != nil {
+			targets = make([]decodeTarget, len(decs))
+		}
+	}
+	n := 0
 	for rows.Next() {
-		err = rows.Scan(f.dst...)
+		if n == s.maxRows && n > 0 {
+			if !s.truncate {
+				return newQueryError(f.query, args, PhaseRows, &RowLimitError{Limit: s.maxRows})
+			}
+			cancel()
+			rows.Close()
+			return nil
+		}
+		scanDst := r.dst
+		if decs != nil {
+			// r.dst can change while yielding
+			wrapped = decodeTargets(decs, r.dst, targets, wrapped)
+			scanDst = wrapped
+		}
+		err = rows.Scan(scanDst...)
 		if err != nil {
-			return err
+			return newQueryError(f.query, args, PhaseScan, err)
+		}
+		*nrows++
+		if f.progress != nil && f.progressEvery > 0 && *nrows%f.progressEvery == 0 {
+			f.progress(*nrows, time.Since(start))
+		}
+		if f.loc != nil || f.textLoc != nil {
+			if err = f.localize(r.dst, r.textTimes); err != nil {
+				return newQueryError(f.query, args, PhaseScan, err)
+			}
 		}
 		if f.yield != nil {
-			err = f.yield()
+			*delivered = true
+			err = f.yield(r)
+			if errors.Is(err, ErrStop) {
+				return nil
+			}
 			if err != nil {
 				return err
 			}
 		}
+		if n++; n == s.cancelRow {
+			// the error of closing cancelled rows is irrelevant
+			cancel()
+			rows.Close()
+			return nil
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return newQueryError(f.query, args, PhaseRows, err)
 	}
-	err = rows.Err()
-	return err
+	return nil
 }
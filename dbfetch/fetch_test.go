@@ -0,0 +1,105 @@
+package dbfetch
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+func TestErrStop(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	var (
+		id    int64
+		login string
+		seen  int
+	)
+	err := Fetch(db, "select users").
+		ScanInto(&id, &login).
+		Yield(func() error {
+			seen++
+			if login == "bob" {
+				return fmt.Errorf("found: %w", ErrStop)
+			}
+			return nil
+		}).
+		Run(ctx)
+	if err != nil || seen != 2 || id != 2 {
+		t.Errorf("got %v after %d rows with id %d, want no error after 2 rows", err, seen, id)
+	}
+}
+
+func TestNullColumns(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"select nullable": {
+			Columns: dbfetchtest.Columns("id", "name", "data"),
+			Rows: [][]any{
+				{int64(1), nil, []byte("a")},
+				{int64(2), "bob", nil},
+			},
+		},
+	})
+	want := [][]any{{int64(1), nil, []byte("a")}, {int64(2), "bob", nil}}
+	var got [][]any
+	err := Fetch(db, "select nullable").YieldColumns(func(values []any) error {
+		got = append(got, slices.Clone(values))
+		return nil
+	}).Run(ctx)
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, %v, want %#v", got, err, want)
+	}
+	rows, err := Fetch(db, "select nullable").All(ctx)
+	if err != nil || rows[0]["name"] != nil || rows[1]["name"] != "bob" || rows[1]["data"] != nil {
+		t.Errorf("got %v, %v", rows, err)
+	}
+}
+
+func TestYieldBatch(t *testing.T) {
+	ctx := context.Background()
+	rows := make([][]any, 5)
+	for i := range rows {
+		rows[i] = []any{int64(i)}
+	}
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"select numbers": {Columns: dbfetchtest.Columns("n"), Rows: rows},
+	})
+	collect := func(f *fetcher, size int) ([][]int64, error) {
+		var batches [][]int64
+		err := f.YieldBatch(size, func(rows [][]any) error {
+			var batch []int64
+			for _, row := range rows {
+				batch = append(batch, row[0].(int64))
+			}
+			batches = append(batches, batch)
+			return nil
+		}).Run(ctx)
+		return batches, err
+	}
+	for _, tc := range []struct {
+		size   int
+		cancel int
+		want   string
+	}{
+		{size: 2, want: "[[0 1] [2 3] [4]]"},
+		{size: 5, want: "[[0 1 2 3 4]]"},
+		{size: 10, want: "[[0 1 2 3 4]]"},
+		{size: 2, cancel: 3, want: "[[0 1] [2]]"},
+	} {
+		got, err := collect(Fetch(db, "select numbers").Cancel(tc.cancel), tc.size)
+		if err != nil || fmt.Sprint(got) != tc.want {
+			t.Errorf("batch size %d, cancel %d: got %v, %v, want %s", tc.size, tc.cancel, got, err, tc.want)
+		}
+	}
+	db = dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"select numbers": {Columns: dbfetchtest.Columns("n"), Rows: rows, Err: fmt.Errorf("broken")},
+	})
+	got, err := collect(Fetch(db, "select numbers"), 2)
+	if err == nil || fmt.Sprint(got) != "[[0 1] [2 3]]" {
+		t.Errorf("got %v, %v, want the full batches and an error", got, err)
+	}
+}
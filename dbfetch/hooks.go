@@ -0,0 +1,77 @@
+package dbfetch
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Hooks are notified about queries run by Run, Exec and the functions based on them.
+// All funcs are optional and must be safe for concurrent use.
+//
+//	dbfetch.SetHooks(&dbfetch.Hooks{
+//		AfterQuery: func(ctx context.Context, query string, d time.Duration, rows int64, err error) {
+//			queryDuration.WithLabelValues(query).Observe(d.Seconds())
+//			queryRows.WithLabelValues(query).Observe(float64(rows))
+//		},
+//	})
+type Hooks struct {
+	// BeforeQuery is called before each attempt to run query.
+	BeforeQuery func(ctx context.Context, query string)
+	// AfterQuery is called after each attempt with its duration and error.
+	// rows is the number of rows read by Run or the number of rows affected by Exec if the driver reports it.
+	AfterQuery func(ctx context.Context, query string, d time.Duration, rows int64, err error)
+	// OnRetry is called before repeating query after the failure err; attempt starts at 2.
+	OnRetry func(ctx context.Context, query string, attempt int, err error)
+}
+
+// globalHooks are used by fetchers without their own Hooks.
+var globalHooks atomic.Pointer[Hooks]
+
+// SetHooks sets the hooks used by all fetchers without their own, nil removes them.
+func SetHooks(h *Hooks) {
+	globalHooks.Store(h)
+}
+
+// Hooks sets hooks for f instead of those set with SetHooks.
+func (f *fetcher) Hooks(h *Hooks) *fetcher {
+	f.hooks = h
+	return f
+}
+
+// activeHooks retrieves the hooks of f, nil if there are none.
+func (f *fetcher) activeHooks() *Hooks {
+	if f.hooks != nil {
+		return f.hooks
+	}
+	return globalHooks.Load()
+}
+
+// observed calls attempt, notifies the hooks of f before and after it and logs it.
+// attempt sets rows to the number of rows it read or affected.
+func (f *fetcher) observed(ctx context.Context, args []any, attempt func(rows *int64) error) error {
+	h := f.activeHooks()
+	if h == nil && f.log == nil {
+		var rows int64
+		return attempt(&rows)
+	}
+	if h != nil && h.BeforeQuery != nil {
+		h.BeforeQuery(ctx, f.query)
+	}
+	var rows int64
+	start := time.Now()
+	err := attempt(&rows)
+	d := time.Since(start)
+	if h != nil && h.AfterQuery != nil {
+		h.AfterQuery(ctx, f.query, d, rows, err)
+	}
+	f.logQuery(ctx, args, d, rows, err)
+	return err
+}
+
+// retrying notifies the hooks of f before another attempt.
+func (f *fetcher) retrying(ctx context.Context, attempt int, err error) {
+	if h := f.activeHooks(); h != nil && h.OnRetry != nil {
+		h.OnRetry(ctx, f.query, attempt, err)
+	}
+}
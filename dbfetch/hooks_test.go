@@ -0,0 +1,57 @@
+package dbfetch
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+func TestHooks(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"select users": usersQuery["select users"],
+		"delete users": {RowsAffected: 3},
+	})
+	var events []string
+	hooks := &Hooks{
+		BeforeQuery: func(_ context.Context, query string) {
+			events = append(events, "before "+query)
+		},
+		AfterQuery: func(_ context.Context, query string, d time.Duration, rows int64, err error) {
+			events = append(events, fmt.Sprintf("after %s %d %v", query, rows, err != nil))
+		},
+		OnRetry: func(_ context.Context, query string, attempt int, err error) {
+			events = append(events, fmt.Sprintf("retry %s %d", query, attempt))
+		},
+	}
+	db.Fail(syscall.ECONNRESET)
+	err := Fetch(db, "select users").
+		Hooks(hooks).
+		Retry(RetryPolicy{Attempts: 2}).
+		Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetHooks(hooks)
+	defer SetHooks(nil)
+	if _, err := Fetch(db, "delete users").Exec(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"before select users",
+		"after select users 0 true",
+		"retry select users 2",
+		"before select users",
+		fmt.Sprintf("after select users %d false", len(users)),
+		"before delete users",
+		"after delete users 3 false",
+	}
+	if !slices.Equal(events, want) {
+		t.Errorf("got %q, want %q", events, want)
+	}
+}
@@ -0,0 +1,99 @@
+package dbfetch
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"unicode/utf8"
+)
+
+// JSONOptions configure RunJSON. The zero value writes a JSON array.
+type JSONOptions struct {
+	// NDJSON writes one object per line instead of an array.
+	NDJSON bool
+}
+
+// RunJSON runs the query and writes the rows to w as JSON objects keyed by column names, in column order.
+// Rows are written one by one as they are read. NULL is null, byte slices are strings if they are valid UTF-8
+// and base64 encoded otherwise; other values are encoded like by encoding/json.
+// On errors, the output is incomplete. opts can be nil.
+// It replaces scan destinations and yield funcs set before.
+//
+//	func (s *server) users(w http.ResponseWriter, r *http.Request) {
+//		w.Header().Set("Content-Type", "application/json")
+//		err := dbfetch.Fetch(s.db, `select id, login from users`).RunJSON(r.Context(), w, nil)
+//		...
+//	}
+func (f *fetcher) RunJSON(ctx context.Context, w io.Writer, opts *JSONOptions, args ...any) error {
+	f = f.call()
+	if opts == nil {
+		opts = &JSONOptions{}
+	}
+	bw := bufio.NewWriter(w)
+	var keys [][]byte
+	derive := f.deriveScan()
+	f.initCols = func(r *run, cts []*sql.ColumnType, err error) error {
+		if err := derive(r, cts, err); err != nil {
+			return err
+		}
+		keys = make([][]byte, len(cts))
+		for i, ct := range cts {
+			if keys[i], err = json.Marshal(ct.Name()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	n := 0
+	f.yield = func(r *run) error {
+		switch {
+		case opts.NDJSON:
+		case n == 0:
+			bw.WriteByte('[')
+		default:
+			bw.WriteByte(',')
+		}
+		n++
+		bw.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				bw.WriteByte(',')
+			}
+			bw.Write(key)
+			bw.WriteByte(':')
+			v, err := json.Marshal(jsonValue(scannedValue(r.dst[i])))
+			if err != nil {
+				return err
+			}
+			bw.Write(v)
+		}
+		bw.WriteByte('}')
+		if opts.NDJSON {
+			bw.WriteByte('\n')
+		}
+		// the error is sticky and also reported by Flush
+		_, err := bw.Write(nil)
+		return err
+	}
+	if err := f.Run(ctx, args...); err != nil {
+		bw.Flush()
+		return err
+	}
+	if !opts.NDJSON {
+		if n == 0 {
+			bw.WriteByte('[')
+		}
+		bw.WriteString("]\n")
+	}
+	return bw.Flush()
+}
+
+// jsonValue converts byte slices with valid UTF-8 to strings.
+func jsonValue(v any) any {
+	if b, ok := v.([]byte); ok && utf8.Valid(b) {
+		return string(b)
+	}
+	return v
+}
@@ -0,0 +1,40 @@
+package dbfetch
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+func TestRunJSON(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"select users": usersQuery["select users"],
+		"select mixed": {
+			Columns: dbfetchtest.Columns("n", "text", "blob", "empty"),
+			Rows:    [][]any{{1.5, []byte("ä"), []byte{0xff}, nil}},
+		},
+		"select none": {Columns: dbfetchtest.Columns("id")},
+	})
+	for _, tc := range []struct {
+		query string
+		opts  *JSONOptions
+		want  string
+	}{
+		{"select users", nil, `[{"id":1,"user_login":"alice"},{"id":2,"user_login":"bob"},{"id":3,"user_login":"carol"}]` + "\n"},
+		{"select users", &JSONOptions{NDJSON: true}, `{"id":1,"user_login":"alice"}` + "\n" + `{"id":2,"user_login":"bob"}` + "\n" + `{"id":3,"user_login":"carol"}` + "\n"},
+		{"select mixed", nil, `[{"n":1.5,"text":"ä","blob":"/w==","empty":null}]` + "\n"},
+		{"select none", nil, "[]\n"},
+		{"select none", &JSONOptions{NDJSON: true}, ""},
+	} {
+		var buf bytes.Buffer
+		if err := Fetch(db, tc.query).RunJSON(ctx, &buf, tc.opts); err != nil {
+			t.Errorf("%s: %v", tc.query, err)
+		}
+		if got := buf.String(); got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.query, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package dbfetch
+
+import (
+	"fmt"
+)
+
+// RowLimitError reports a query returning more rows than allowed with MaxRows.
+// It is wrapped in a *QueryError and matches ErrTooManyRows.
+type RowLimitError struct {
+	Limit int
+}
+
+func (e *RowLimitError) Error() string {
+	return fmt.Sprintf("more than %d rows in result", e.Limit)
+}
+
+// Is reports true for ErrTooManyRows.
+func (e *RowLimitError) Is(target error) bool {
+	return target == ErrTooManyRows
+}
+
+// MaxRows limits the number of rows Run accepts to n; zero or less accepts all.
+// If the query returns more rows, Run fails with a *RowLimitError after the first n rows were yielded.
+// With truncate, it stops without an error instead, like Cancel.
+//
+//	err := dbfetch.Fetch(db, `select id from jobs where state = 'new'`).
+//		MaxRows(1000, false).
+//		Column(ctx, &ids)
+func (f *fetcher) MaxRows(n int, truncate bool) *fetcher {
+	f.maxRows = max(n, 0)
+	f.truncate = truncate
+	return f
+}
@@ -0,0 +1,28 @@
+package dbfetch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+func TestMaxRows(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	var got []user
+	err := Fetch(db, "select users").MaxRows(len(users), false).AllInto(ctx, &got)
+	if err != nil || len(got) != len(users) {
+		t.Errorf("got %d rows, %v, want %d", len(got), err, len(users))
+	}
+	rows, err := Fetch(db, "select users").MaxRows(2, false).All(ctx)
+	var rle *RowLimitError
+	if !errors.As(err, &rle) || rle.Limit != 2 || !errors.Is(err, ErrTooManyRows) || rows != nil {
+		t.Errorf("got %v, %v, want a row limit error", rows, err)
+	}
+	rows, err = Fetch(db, "select users").MaxRows(2, true).All(ctx)
+	if err != nil || len(rows) != 2 {
+		t.Errorf("got %d rows, %v, want 2", len(rows), err)
+	}
+}
@@ -0,0 +1,112 @@
+package dbfetch
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// textTimeLayouts are the layouts of timestamps in the MySQL text protocol.
+var textTimeLayouts = []string{
+	time.DateTime + ".999999999",
+	time.DateOnly,
+}
+
+// textTimeTypes are the database types parsed with ParseTextTimes.
+var textTimeTypes = map[string]bool{
+	"DATETIME":  true,
+	"TIMESTAMP": true,
+	"DATE":      true,
+}
+
+// InLocation converts all scanned time values into loc before they are yielded.
+// It covers scan destinations of the types *time.Time, **time.Time, *sql.NullTime and *any,
+// including struct fields; nil disables it.
+func (f *fetcher) InLocation(loc *time.Location) *fetcher {
+	f.loc = loc
+	return f
+}
+
+// ParseTextTimes parses timestamps received as text, as in the text protocol of MySQL without parseTime=true,
+// into time.Time in the location src. It applies to derived scan destinations of columns with the
+// database type DATETIME, TIMESTAMP or DATE, e.g. in YieldColumns and All.
+// Combined with InLocation, the times are converted afterwards.
+func (f *fetcher) ParseTextTimes(src *time.Location) *fetcher {
+	f.textLoc = src
+	return f
+}
+
+// isTextTime reports whether ct is parsed as text timestamp.
+func (f *fetcher) isTextTime(ct *sql.ColumnType) bool {
+	return f.textLoc != nil && textTimeTypes[strings.ToUpper(ct.DatabaseTypeName())]
+}
+
+// localize converts the time values in dst into the location of f.
+// Text is only parsed as timestamp for the destinations marked in textTimes.
+func (f *fetcher) localize(dst []any, textTimes []bool) error {
+	for i, ptr := range dst {
+		switch p := ptr.(type) {
+		case *time.Time:
+			if f.loc != nil {
+				*p = p.In(f.loc)
+			}
+		case **time.Time:
+			if *p != nil && f.loc != nil {
+				t := (*p).In(f.loc)
+				*p = &t
+			}
+		case *sql.NullTime:
+			if p.Valid && f.loc != nil {
+				p.Time = p.Time.In(f.loc)
+			}
+		case *any:
+			t, ok, err := f.localTime(*p, i < len(textTimes) && textTimes[i])
+			if err != nil {
+				return err
+			}
+			if ok {
+				*p = t
+			}
+		}
+	}
+	return nil
+}
+
+// localTime converts v to the location of f if it is a time or, if parseText is set, a timestamp in text form.
+func (f *fetcher) localTime(v any, parseText bool) (time.Time, bool, error) {
+	var t time.Time
+	switch v := v.(type) {
+	case time.Time:
+		t = v
+	case []byte:
+		return f.localTime(string(v), parseText)
+	case string:
+		if !parseText {
+			return t, false, nil
+		}
+		var err error
+		if t, err = parseTextTime(v, f.textLoc); err != nil {
+			return t, false, err
+		}
+	default:
+		return t, false, nil
+	}
+	if f.loc != nil {
+		t = t.In(f.loc)
+	}
+	return t, true, nil
+}
+
+// parseTextTime parses a timestamp in one of the textTimeLayouts.
+func parseTextTime(s string, loc *time.Location) (time.Time, error) {
+	var (
+		t   time.Time
+		err error
+	)
+	for _, layout := range textTimeLayouts {
+		if t, err = time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return t, err
+}
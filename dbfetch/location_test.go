@@ -0,0 +1,88 @@
+package dbfetch
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+func TestInLocation(t *testing.T) {
+	ctx := context.Background()
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	ts := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"select times": {
+			Columns: dbfetchtest.Columns("a", "b", "c"),
+			Rows:    [][]any{{ts, ts, ts}},
+		},
+		"select text": {
+			Columns: []dbfetchtest.Column{
+				{Name: "created", DatabaseType: "DATETIME"},
+				{Name: "day", DatabaseType: "DATE"},
+				{Name: "name", DatabaseType: "VARCHAR"},
+			},
+			Rows: [][]any{{[]byte("2024-06-01 10:00:00.5"), "2024-06-01", "2024-06-01"}},
+		},
+	})
+	var (
+		a time.Time
+		b *time.Time
+		c sql.NullTime
+	)
+	err = Fetch(db, "select times").InLocation(berlin).ScanInto(&a, &b, &c).Yield(func() error { return nil }).Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, got := range []time.Time{a, *b, c.Time} {
+		if got.Location() != berlin || !got.Equal(ts) {
+			t.Errorf("got %v, want %v in %v", got, ts, berlin)
+		}
+	}
+	rows, err := Fetch(db, "select times").InLocation(berlin).All(ctx)
+	if got, ok := rows[0]["a"].(time.Time); err != nil || !ok || got.Location() != berlin {
+		t.Errorf("got %v, %v, want a time in %v", rows, err, berlin)
+	}
+	rows, err = Fetch(db, "select text").ParseTextTimes(time.UTC).InLocation(berlin).All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, _ := rows[0]["created"].(time.Time)
+	day, _ := rows[0]["day"].(time.Time)
+	if !created.Equal(ts.Add(500*time.Millisecond)) || created.Location() != berlin ||
+		!day.Equal(ts.Add(-10*time.Hour)) || rows[0]["name"] != "2024-06-01" {
+		t.Errorf("got %v", rows)
+	}
+}
+
+func TestParseTextTimesOtherText(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"select text": {
+			Columns: []dbfetchtest.Column{
+				{Name: "created", DatabaseType: "DATETIME"},
+				{Name: "login", DatabaseType: "VARCHAR", ScanType: reflect.TypeFor[any]()},
+			},
+			Rows: [][]any{{"2024-06-01 10:00:00", "alice"}},
+		},
+	})
+	rows, err := Fetch(db, "select text").ParseTextTimes(time.UTC).All(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	if created, _ := rows[0]["created"].(time.Time); !created.Equal(want) || rows[0]["login"] != "alice" {
+		t.Errorf("got %v", rows)
+	}
+	var login any
+	err = Fetch(db, "select text").ParseTextTimes(time.UTC).ScanInto(new(any), &login).Yield(func() error { return nil }).Run(ctx)
+	if err != nil || login != "alice" {
+		t.Errorf("got %v, %v, want alice", login, err)
+	}
+}
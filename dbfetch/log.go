@@ -0,0 +1,94 @@
+package dbfetch
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// Logger receives log records of queries. It is implemented by *slog.Logger.
+type Logger interface {
+	Log(ctx context.Context, level slog.Level, msg string, args ...any)
+}
+
+var _ Logger = (*slog.Logger)(nil)
+
+// LogOptions configure query logging, see Log.
+type LogOptions struct {
+	Logger Logger
+	// Level is the level of successful queries.
+	Level slog.Level
+	// ErrorLevel is the level of failed queries, slog.LevelError if it is zero.
+	ErrorLevel slog.Level
+	// MaxArgLen is the maximum length of logged arguments, longer ones are truncated; 64 if it is zero.
+	MaxArgLen int
+}
+
+// redacted replaces the values of sensitive arguments.
+const redacted = "[redacted]"
+
+// Log logs each attempt of Run and Exec with the query, its arguments, duration, number of rows and error.
+// Arguments are formatted with %v and truncated; use Sensitive to keep secrets and personal data out of logs.
+//
+//	err := dbfetch.Fetch(db, `select id from users where login = ? and password_hash = ?`).
+//		Log(&dbfetch.LogOptions{Logger: slog.Default(), Level: slog.LevelDebug}).
+//		Sensitive(1).
+//		One(ctx, &id)
+func (f *fetcher) Log(opts *LogOptions) *fetcher {
+	f.log = opts
+	return f
+}
+
+// Sensitive redacts the query arguments at argIndexes, starting at 0, in logs.
+func (f *fetcher) Sensitive(argIndexes ...int) *fetcher {
+	f.sensitive = append(f.sensitive, argIndexes...)
+	return f
+}
+
+// logQuery logs an attempt of f if logging is enabled.
+func (f *fetcher) logQuery(ctx context.Context, args []any, d time.Duration, rows int64, err error) {
+	opts := f.log
+	if opts == nil || opts.Logger == nil {
+		return
+	}
+	maxLen := opts.MaxArgLen
+	if maxLen <= 0 {
+		maxLen = 64
+	}
+	logged := make([]string, len(args))
+	for i, arg := range args {
+		if slices.Contains(f.sensitive, i) {
+			logged[i] = redacted
+			continue
+		}
+		logged[i] = truncate(fmt.Sprintf("%v", arg), maxLen)
+	}
+	attrs := []any{
+		slog.String("query", f.query),
+		slog.Any("args", logged),
+		slog.Duration("duration", d),
+		slog.Int64("rows", rows),
+	}
+	if err == nil {
+		opts.Logger.Log(ctx, opts.Level, "query", attrs...)
+		return
+	}
+	level := opts.ErrorLevel
+	if level == 0 {
+		level = slog.LevelError
+	}
+	opts.Logger.Log(ctx, level, "query failed", append(attrs, slog.Any("error", err))...)
+}
+
+// truncate shortens s to at most n runes.
+func truncate(s string, n int) string {
+	for i := range s {
+		if n == 0 {
+			return s[:i] + "…"
+		}
+		n--
+	}
+	return s
+}
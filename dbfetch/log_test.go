@@ -0,0 +1,68 @@
+package dbfetch
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+func TestLog(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "duration" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	opts := &LogOptions{Logger: logger, Level: slog.LevelDebug, MaxArgLen: 5}
+	err := Fetch(db, "select users").
+		Log(opts).
+		Sensitive(1).
+		Run(ctx, "alice-in-wonderland", "secret", 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Fetch(db, "select unknown").Log(opts).Run(ctx)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		`level=DEBUG msg=query query="select users" args="[alice… [redacted] 42]" rows=3`,
+		`level=ERROR msg="query failed" query="select unknown" args=[] rows=0 error=`,
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %q, want %d lines", lines, len(want))
+	}
+	for i, line := range lines {
+		if !strings.HasPrefix(line, want[i]) {
+			t.Errorf("got %s, want %s", line, want[i])
+		}
+	}
+	if strings.Contains(buf.String(), "secret") {
+		t.Errorf("sensitive argument logged")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"abc", 3, "abc"},
+		{"abcd", 3, "abc…"},
+		{"äöüß", 2, "äö…"},
+		{"", 0, ""},
+	} {
+		if got := truncate(tc.s, tc.n); got != tc.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tc.s, tc.n, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package dbfetch
+
+import (
+	"database/sql"
+)
+
+// skipped is the scan destination of skipped columns, it drops the value.
+type skipped struct{}
+
+func (*skipped) Scan(any) error {
+	return nil
+}
+
+// MapColumns sets a func choosing the scan destination for each column when the query ran.
+// target must be a pointer; if it is nil, a destination is derived from the column type like in Run.
+// Skipped columns are dropped, YieldColumns reports them as nil.
+// It replaces scan destinations set before, use it with Yield.
+//
+//	var id int64
+//	extra := make(map[string]*any)
+//	err := dbfetch.Fetch(db, `select * from users`).
+//		MapColumns(func(name string, ct *sql.ColumnType) (any, bool) {
+//			switch name {
+//			case "id":
+//				return &id, false
+//			case "password":
+//				return nil, true
+//			}
+//			v := new(any)
+//			extra[name] = v
+//			return v, false
+//		}).
+//		Yield(func() error { ... }).
+//		Run(ctx)
+func (f *fetcher) MapColumns(mapCol func(name string, ct *sql.ColumnType) (target any, skip bool)) *fetcher {
+	f.dst = nil
+	f.initCols = func(r *run, cts []*sql.ColumnType, err error) error {
+		if err != nil {
+			return err
+		}
+		dst := make([]any, len(cts))
+		for i, ct := range cts {
+			target, skip := mapCol(ct.Name(), ct)
+			switch {
+			case skip:
+				dst[i] = &skipped{}
+			case target == nil:
+				dst[i] = scanTarget(ct)
+			default:
+				dst[i] = target
+			}
+		}
+		r.dst = dst
+		return nil
+	}
+	return f
+}
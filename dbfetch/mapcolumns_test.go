@@ -0,0 +1,61 @@
+package dbfetch
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"testing"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+func TestMapColumns(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	var (
+		ids    []int64
+		logins []any
+		id     int64
+	)
+	err := Fetch(db, "select users").
+		MapColumns(func(name string, ct *sql.ColumnType) (any, bool) {
+			if name == "id" {
+				return &id, false
+			}
+			return nil, false
+		}).
+		YieldColumns(func(values []any) error {
+			ids = append(ids, id)
+			logins = append(logins, values[1])
+			return nil
+		}).
+		Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, u := range users {
+		if ids[i] != u.ID || logins[i] != u.Login {
+			t.Errorf("row %d: got %d %v, want %d %s", i, ids[i], logins[i], u.ID, u.Login)
+		}
+	}
+	var got []any
+	err = Fetch(db, "select users").
+		MapColumns(func(name string, ct *sql.ColumnType) (any, bool) {
+			return nil, name == "user_login"
+		}).
+		YieldColumns(func(values []any) error {
+			got = append(got, values...)
+			return nil
+		}).
+		Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []any
+	for _, u := range users {
+		want = append(want, u.ID, nil)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
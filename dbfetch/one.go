@@ -0,0 +1,84 @@
+package dbfetch
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNoRows is reported by One and Scalar for queries without results.
+	// It is sql.ErrNoRows, so both can be used with errors.Is.
+	ErrNoRows = sql.ErrNoRows
+	// ErrTooManyRows is reported by One and Scalar for queries with more than one result.
+	ErrTooManyRows = errors.New("dbfetch: more than one row in result")
+)
+
+// one runs the query and checks that it has exactly one row.
+// Scan destinations must be set before.
+func (f *fetcher) one(ctx context.Context) error {
+	n := 0
+	f.yield = func(r *run) error {
+		n++
+		if n > 1 {
+			return newQueryError(f.query, f.args, PhaseRows, ErrTooManyRows)
+		}
+		// keep the values of the first row
+		discard := make([]any, len(r.dst))
+		for i := range discard {
+			discard[i] = new(any)
+		}
+		r.dst = discard
+		return nil
+	}
+	if err := f.Run(ctx); err != nil {
+		return err
+	}
+	if n == 0 {
+		return newQueryError(f.query, f.args, PhaseRows, ErrNoRows)
+	}
+	return nil
+}
+
+// One runs the query and scans its only row into dst.
+// It reports ErrNoRows if there are no rows and ErrTooManyRows if there are more than one.
+// Query arguments are set with Args.
+//
+//	var login string
+//	var created time.Time
+//	err := dbfetch.Fetch(db, `select login, created from users where id = ?`).
+//		Args(id).
+//		One(ctx, &login, &created)
+func (f *fetcher) One(ctx context.Context, dst ...any) error {
+	f = f.call()
+	f.initCols = nil
+	f.dst = dst
+	return f.one(ctx)
+}
+
+// Scalar runs the query of f and retrieves its only value.
+// The result must consist of a single row with a single column.
+// It reports ErrNoRows if there are no rows and ErrTooManyRows if there are more than one.
+// Query arguments are set with Args.
+//
+//	count, err := dbfetch.Scalar[int64](ctx, dbfetch.Fetch(db, `select count(*) from users`))
+func Scalar[T any](ctx context.Context, f *fetcher) (T, error) {
+	f = f.call()
+	var v T
+	f.InitColumns(func(cts []*sql.ColumnType, err error) error {
+		if err != nil {
+			return err
+		}
+		if len(cts) != 1 {
+			return fmt.Errorf("expected a single column, got %d", len(cts))
+		}
+		f.dst = []any{&v}
+		return nil
+	})
+	if err := f.one(ctx); err != nil {
+		var zero T
+		return zero, err
+	}
+	return v, nil
+}
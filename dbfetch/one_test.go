@@ -0,0 +1,57 @@
+package dbfetch
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+var countQueries = map[string]dbfetchtest.Result{
+	"select count": {
+		Columns: dbfetchtest.Columns("count"),
+		Rows:    [][]any{{int64(3)}},
+	},
+	"select nothing": {
+		Columns: dbfetchtest.Columns("count"),
+	},
+}
+
+func TestOne(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	var (
+		id    int64
+		login string
+	)
+	if err := Fetch(db, "select admin").One(ctx, &id, new(bool)); err != nil || id != 1 {
+		t.Errorf("got %d, %v, want 1", id, err)
+	}
+	if err := Fetch(db, "select none").One(ctx, &id, &login); !errors.Is(err, ErrNoRows) || !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("got error %v, want ErrNoRows", err)
+	}
+	err := Fetch(db, "select users").One(ctx, &id, &login)
+	if !errors.Is(err, ErrTooManyRows) {
+		t.Errorf("got error %v, want ErrTooManyRows", err)
+	}
+	if id != 1 || login != "alice" {
+		t.Errorf("got %d, %q, want the first row", id, login)
+	}
+}
+
+func TestScalar(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, countQueries)
+	if n, err := Scalar[int64](ctx, Fetch(db, "select count")); err != nil || n != 3 {
+		t.Errorf("got %d, %v, want 3", n, err)
+	}
+	if _, err := Scalar[int64](ctx, Fetch(db, "select nothing")); !errors.Is(err, ErrNoRows) {
+		t.Errorf("got error %v, want ErrNoRows", err)
+	}
+	db = dbfetchtest.Open(t, usersQuery)
+	if _, err := Scalar[int64](ctx, Fetch(db, "select users")); err == nil {
+		t.Errorf("no error for two columns")
+	}
+}
@@ -0,0 +1,385 @@
+// Package pgxfetch runs dbfetch on a pgxpool.Pool with the native protocol of pgx.
+//
+// Open returns a *sql.DB usable with Fetch, FetchAll, InTx and all other functions of dbfetch.
+// Its connections are taken from the pool, queries run on pgx directly and rows are decoded by pgx
+// in the binary format where pgx supports it. Columns report their PostgreSQL type name, e.g. "JSONB",
+// and the Go type pgx decodes them to as scan type, so derived scans get int32 for INT4 instead of any.
+//
+//	db := pgxfetch.Open(pool)
+//	defer db.Close()
+//	users, err := dbfetch.FetchAll[user](ctx, db, `select id, login from users where id = any($1)`, []int64{1, 2})
+//
+// Arguments are passed to pgx unchanged, so slices, maps and pgtype values work as with pgx.
+// Values decoded to types database/sql can not convert, e.g. numeric or arrays,
+// are scanned into any, into the pgtype types or into matching Go types.
+// JSON, JSONB and UUID values are passed as bytes, for dbfetch.DecodeJSON and dbfetch.DecodeUUID.
+// dbfetch.BulkInsert uses the native COPY FROM of pgx on a database returned by Open.
+package pgxfetch
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/arnehormann/goof/dbfetch"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Open returns a database running queries on connections of pool.
+// The database keeps no idle connections, pool manages them; closing it does not close pool.
+func Open(pool *pgxpool.Pool) *sql.DB {
+	db := sql.OpenDB(&connector{pool: pool})
+	db.SetMaxIdleConns(0)
+	return db
+}
+
+// connector acquires connections from a pool.
+type connector struct {
+	pool *pgxpool.Pool
+}
+
+var _ driver.Connector = (*connector)(nil)
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	pc, err := c.pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{pc: pc}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return poolDriver{}
+}
+
+// poolDriver is the driver of connector; it can only connect through a pool.
+type poolDriver struct{}
+
+var _ dbfetch.DollarDriver = poolDriver{}
+
+func (poolDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("connections can only be opened from a pool, use Open")
+}
+
+// DollarPlaceholders reports that PostgreSQL requires $1, $2, ..., e.g. for dbfetch.BulkInsert.
+func (poolDriver) DollarPlaceholders() bool {
+	return true
+}
+
+// conn is a connection acquired from the pool, released when database/sql closes it.
+type conn struct {
+	pc *pgxpool.Conn
+}
+
+var (
+	_ driver.Conn               = (*conn)(nil)
+	_ driver.ConnBeginTx        = (*conn)(nil)
+	_ driver.ConnPrepareContext = (*conn)(nil)
+	_ driver.QueryerContext     = (*conn)(nil)
+	_ driver.ExecerContext      = (*conn)(nil)
+	_ driver.NamedValueChecker  = (*conn)(nil)
+	_ driver.Pinger             = (*conn)(nil)
+	_ driver.SessionResetter    = (*conn)(nil)
+	_ driver.Validator          = (*conn)(nil)
+	_ dbfetch.CopyFromConn      = (*conn)(nil)
+)
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext only keeps the query, pgx prepares and caches statements on its own.
+func (c *conn) PrepareContext(_ context.Context, query string) (driver.Stmt, error) {
+	return &stmt{c: c, query: query}, nil
+}
+
+func (c *conn) Close() error {
+	c.pc.Release()
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	var txOpts pgx.TxOptions
+	switch sql.IsolationLevel(opts.Isolation) {
+	case sql.LevelDefault:
+	case sql.LevelReadUncommitted:
+		txOpts.IsoLevel = pgx.ReadUncommitted
+	case sql.LevelReadCommitted:
+		txOpts.IsoLevel = pgx.ReadCommitted
+	case sql.LevelRepeatableRead, sql.LevelSnapshot:
+		txOpts.IsoLevel = pgx.RepeatableRead
+	case sql.LevelSerializable:
+		txOpts.IsoLevel = pgx.Serializable
+	default:
+		return nil, fmt.Errorf("unsupported isolation level %v", sql.IsolationLevel(opts.Isolation))
+	}
+	if opts.ReadOnly {
+		txOpts.AccessMode = pgx.ReadOnly
+	}
+	t, err := c.pc.BeginTx(ctx, txOpts)
+	if err != nil {
+		return nil, err
+	}
+	return &tx{t: t}, nil
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rs, err := c.pc.Query(ctx, query, values(args)...)
+	if err != nil {
+		return nil, err
+	}
+	return &rows{
+		rows:    rs,
+		fields:  rs.FieldDescriptions(),
+		typeMap: c.pc.Conn().TypeMap(),
+	}, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	tag, err := c.pc.Exec(ctx, query, values(args)...)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(tag.RowsAffected()), nil
+}
+
+// CheckNamedValue accepts all arguments, pgx encodes them itself.
+func (c *conn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	return c.pc.Ping(ctx)
+}
+
+func (c *conn) ResetSession(context.Context) error {
+	if c.pc.Conn().IsClosed() {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	return !c.pc.Conn().IsClosed()
+}
+
+// CopyFrom inserts rows with the native COPY FROM of pgx, see dbfetch.BulkInsert.
+// table can be qualified with a schema; like in statements, names are folded to lower case unless they are quoted.
+func (c *conn) CopyFrom(ctx context.Context, table string, columns []string, rows iter.Seq[[]any]) (int64, error) {
+	next, stop := iter.Pull(rows)
+	defer stop()
+	n := 0
+	src := pgx.CopyFromFunc(func() ([]any, error) {
+		row, ok := next()
+		if !ok {
+			return nil, nil
+		}
+		if len(row) != len(columns) {
+			return nil, fmt.Errorf("row %d has %d values for %d columns", n, len(row), len(columns))
+		}
+		n++
+		return row, nil
+	})
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = identName(col)
+	}
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		parts[i] = identName(part)
+	}
+	return c.pc.CopyFrom(ctx, pgx.Identifier(parts), names, src)
+}
+
+// identName retrieves the name of an identifier in a statement; pgx quotes the names it receives.
+func identName(ident string) string {
+	if len(ident) >= 2 && ident[0] == '"' && ident[len(ident)-1] == '"' {
+		return strings.ReplaceAll(ident[1:len(ident)-1], `""`, `"`)
+	}
+	return strings.ToLower(ident)
+}
+
+// values retrieves the values of args; pgx only supports positional arguments.
+func values(args []driver.NamedValue) []any {
+	vals := make([]any, len(args))
+	for i, arg := range args {
+		vals[i] = arg.Value
+	}
+	return vals
+}
+
+// stmt is a query run on its connection.
+type stmt struct {
+	c     *conn
+	query string
+}
+
+var (
+	_ driver.Stmt             = (*stmt)(nil)
+	_ driver.StmtQueryContext = (*stmt)(nil)
+	_ driver.StmtExecContext  = (*stmt)(nil)
+)
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+// NumInput is unknown, pgx checks the number of arguments.
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), named(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), named(args))
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.c.ExecContext(ctx, s.query, args)
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.c.QueryContext(ctx, s.query, args)
+}
+
+// named converts the arguments of the deprecated Stmt methods.
+func named(args []driver.Value) []driver.NamedValue {
+	nvs := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		nvs[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return nvs
+}
+
+// tx is a transaction on a connection.
+type tx struct {
+	t pgx.Tx
+}
+
+func (t *tx) Commit() error {
+	return t.t.Commit(context.Background())
+}
+
+func (t *tx) Rollback() error {
+	return t.t.Rollback(context.Background())
+}
+
+// rows passes the values decoded by pgx to database/sql.
+type rows struct {
+	rows    pgx.Rows
+	fields  []pgconn.FieldDescription
+	typeMap *pgtype.Map
+}
+
+var (
+	_ driver.Rows                           = (*rows)(nil)
+	_ driver.RowsColumnTypeDatabaseTypeName = (*rows)(nil)
+	_ driver.RowsColumnTypeScanType         = (*rows)(nil)
+)
+
+func (r *rows) Columns() []string {
+	names := make([]string, len(r.fields))
+	for i, fd := range r.fields {
+		names[i] = fd.Name
+	}
+	return names
+}
+
+func (r *rows) Close() error {
+	r.rows.Close()
+	return r.rows.Err()
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+	vals, err := r.rows.Values()
+	if err != nil {
+		return err
+	}
+	raw := r.rows.RawValues()
+	for i, v := range vals {
+		if v == nil {
+			dest[i] = nil
+			continue
+		}
+		switch fd := r.fields[i]; fd.DataTypeOID {
+		case pgtype.JSONOID, pgtype.JSONBOID:
+			v = jsonText(raw[i], fd.Format)
+		case pgtype.UUIDOID:
+			if id, ok := v.([16]byte); ok {
+				v = id[:]
+			}
+		}
+		dest[i] = v
+	}
+	return nil
+}
+
+// jsonText retrieves the JSON text of a JSON or JSONB value as received from the server.
+// The binary format of JSONB starts with a version byte.
+func jsonText(raw []byte, format int16) []byte {
+	if format == pgtype.BinaryFormatCode && len(raw) > 0 && raw[0] == 1 {
+		raw = raw[1:]
+	}
+	return bytes.Clone(raw)
+}
+
+// ColumnTypeDatabaseTypeName reports the upper case PostgreSQL type name, e.g. "INT8" or "_TEXT" for text[].
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	if t, ok := r.typeMap.TypeForOID(r.fields[index].DataTypeOID); ok {
+		return strings.ToUpper(t.Name)
+	}
+	return ""
+}
+
+// ColumnTypeScanType reports the Go type pgx decodes the column to, any for types not listed in scanTypes.
+func (r *rows) ColumnTypeScanType(index int) reflect.Type {
+	if t, ok := scanTypes[r.fields[index].DataTypeOID]; ok {
+		return t
+	}
+	return reflect.TypeFor[any]()
+}
+
+// scanTypes maps the OIDs of common types to the Go types of their values in rows.
+var scanTypes = map[uint32]reflect.Type{
+	pgtype.BoolOID:        reflect.TypeFor[bool](),
+	pgtype.Int2OID:        reflect.TypeFor[int16](),
+	pgtype.Int4OID:        reflect.TypeFor[int32](),
+	pgtype.Int8OID:        reflect.TypeFor[int64](),
+	pgtype.Float4OID:      reflect.TypeFor[float32](),
+	pgtype.Float8OID:      reflect.TypeFor[float64](),
+	pgtype.TextOID:        reflect.TypeFor[string](),
+	pgtype.VarcharOID:     reflect.TypeFor[string](),
+	pgtype.BPCharOID:      reflect.TypeFor[string](),
+	pgtype.NameOID:        reflect.TypeFor[string](),
+	pgtype.ByteaOID:       reflect.TypeFor[[]byte](),
+	pgtype.JSONOID:        reflect.TypeFor[[]byte](),
+	pgtype.JSONBOID:       reflect.TypeFor[[]byte](),
+	pgtype.UUIDOID:        reflect.TypeFor[[]byte](),
+	pgtype.DateOID:        reflect.TypeFor[time.Time](),
+	pgtype.TimestampOID:   reflect.TypeFor[time.Time](),
+	pgtype.TimestamptzOID: reflect.TypeFor[time.Time](),
+}
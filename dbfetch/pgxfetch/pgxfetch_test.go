@@ -0,0 +1,152 @@
+package pgxfetch
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/arnehormann/goof/dbfetch"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// openTest opens the database in PGX_TEST_DATABASE, the variable used by the tests of pgx.
+func openTest(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	dsn := os.Getenv("PGX_TEST_DATABASE")
+	if dsn == "" {
+		t.Skip("PGX_TEST_DATABASE is not set")
+	}
+	pool, err := pgxpool.New(context.Background(), dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestFetchAll(t *testing.T) {
+	db := Open(openTest(t))
+	defer db.Close()
+	type row struct {
+		ID    int32
+		Login *string
+		Tags  []any
+		Meta  map[string]any
+	}
+	dbfetch.RegisterDecoder("JSONB", dbfetch.DecodeJSON)
+	defer dbfetch.RegisterDecoder("JSONB", nil)
+	got, err := dbfetch.FetchAll[row](context.Background(), db, `
+		select id, login, tags, meta
+		from (values
+			(1, 'alice', array['a', 'b'], '{"admin": true}'::jsonb),
+			(2, null, array['c'], '{}'::jsonb)
+		) as users(id, login, tags, meta)
+		where id = any($1)
+		order by id`, []int32{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := "alice"
+	want := []row{
+		{1, &alice, []any{"a", "b"}, map[string]any{"admin": true}},
+		{2, nil, []any{"c"}, map[string]any{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestColumnTypes(t *testing.T) {
+	db := Open(openTest(t))
+	defer db.Close()
+	cols, err := dbfetch.Fetch(db, `select 1::int4 as id, 'x'::text as name, '{}'::jsonb as meta`).Describe(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []struct {
+		dbType, goType string
+	}{
+		{"INT4", "int32"},
+		{"TEXT", "string"},
+		{"JSONB", "[]uint8"},
+	} {
+		if cols[i].DatabaseType != want.dbType || cols[i].GoType != want.goType {
+			t.Errorf("column %d: got %s %s, want %s %s", i, cols[i].DatabaseType, cols[i].GoType, want.dbType, want.goType)
+		}
+	}
+}
+
+func TestBulkInsertPlaceholders(t *testing.T) {
+	// the driver is known without connecting
+	db := Open(nil)
+	defer db.Close()
+	d, ok := db.Driver().(dbfetch.DollarDriver)
+	if !ok || !d.DollarPlaceholders() {
+		t.Errorf("driver %T does not report dollar placeholders", db.Driver())
+	}
+}
+
+func TestBulkInsert(t *testing.T) {
+	db := Open(openTest(t))
+	defer db.Close()
+	ctx := context.Background()
+	// not temporary, BulkInsert may run on another connection
+	if _, err := db.ExecContext(ctx, `create table pgxfetch_bulk (id int8, name text)`); err != nil {
+		t.Fatal(err)
+	}
+	defer db.ExecContext(ctx, `drop table pgxfetch_bulk`)
+	rows := func(yield func([]any) bool) {
+		for i := range 3 {
+			if !yield([]any{int64(i), "row"}) {
+				return
+			}
+		}
+	}
+	n, err := dbfetch.BulkInsert(ctx, db, "pgxfetch_bulk", []string{"id", "name"}, rows, &dbfetch.BulkOptions{BatchSize: 2})
+	if err != nil || n != 3 {
+		t.Fatalf("got %d, %v, want 3 rows", n, err)
+	}
+	ids, err := dbfetch.FetchAll[int64](ctx, db, `select id from pgxfetch_bulk order by id`)
+	if err != nil || !reflect.DeepEqual(ids, []int64{0, 1, 2}) {
+		t.Errorf("got %v, %v, want [0 1 2]", ids, err)
+	}
+}
+
+func TestIdentName(t *testing.T) {
+	for ident, want := range map[string]string{
+		"Events":       "events",
+		`"Events"`:     "Events",
+		`"say ""hi"""`: `say "hi"`,
+		`"`:            `"`,
+	} {
+		if got := identName(ident); got != want {
+			t.Errorf("%s: got %q, want %q", ident, got, want)
+		}
+	}
+}
+
+func TestInTx(t *testing.T) {
+	db := Open(openTest(t))
+	defer db.Close()
+	ctx := context.Background()
+	err := dbfetch.InTx(ctx, db, nil, func(tx dbfetch.Querier) error {
+		if _, err := tx.ExecContext(ctx, `create temporary table pgxfetch_test (id int8) on commit drop`); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `insert into pgxfetch_test values ($1), ($2)`, 1, 2); err != nil {
+			return err
+		}
+		ids, err := dbfetch.FetchAll[int64](ctx, tx, `select id from pgxfetch_test order by id`)
+		if err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(ids, []int64{1, 2}) {
+			t.Errorf("got %v, want [1 2]", ids)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,21 @@
+package dbfetch
+
+import (
+	"time"
+)
+
+// Progress sets fn to be called by Run every time another every rows were read,
+// with the number of rows read so far and the time since the query was started.
+// fn is called before the row is yielded; zero or less for every disables it.
+//
+//	err := dbfetch.Fetch(db, `select * from events`).
+//		Progress(100_000, func(rows int64, elapsed time.Duration) {
+//			log.Printf("%d of %d events after %v", rows, total, elapsed)
+//		}).
+//		Yield(export).
+//		Run(ctx)
+func (f *fetcher) Progress(every int, fn func(rows int64, elapsed time.Duration)) *fetcher {
+	f.progressEvery = int64(every)
+	f.progress = fn
+	return f
+}
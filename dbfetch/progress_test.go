@@ -0,0 +1,33 @@
+package dbfetch
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+func TestProgress(t *testing.T) {
+	ctx := context.Background()
+	rows := make([][]any, 10)
+	for i := range rows {
+		rows[i] = []any{int64(i)}
+	}
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"select numbers": {Columns: dbfetchtest.Columns("n"), Rows: rows},
+	})
+	var got []int64
+	_, err := Fetch(db, "select numbers").
+		Progress(4, func(rows int64, elapsed time.Duration) {
+			if elapsed < 0 {
+				t.Errorf("negative elapsed time %v", elapsed)
+			}
+			got = append(got, rows)
+		}).
+		All(ctx)
+	if want := []int64{4, 8}; err != nil || !slices.Equal(got, want) {
+		t.Errorf("got %v, %v, want %v", got, err, want)
+	}
+}
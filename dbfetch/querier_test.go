@@ -0,0 +1,48 @@
+package dbfetch
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+func TestQuerier(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for name, q := range map[string]Querier{"db": db, "tx": tx, "conn": conn} {
+		for _, asStmt := range []bool{false, true} {
+			var got []user
+			if err := Fetch(q, "select users").UseStmt(asStmt).AllInto(ctx, &got); err != nil || !slices.Equal(got, users) {
+				t.Errorf("%s, statement %v: got %v, %v, want %v", name, asStmt, got, err, users)
+			}
+		}
+	}
+}
+
+func TestFetchStmt(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	stmt, err := db.PrepareContext(ctx, "select users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	for range 2 {
+		var got []user
+		if err := FetchStmt(stmt).AllInto(ctx, &got); err != nil || !slices.Equal(got, users) {
+			t.Errorf("got %v, %v, want %v", got, err, users)
+		}
+	}
+}
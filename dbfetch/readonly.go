@@ -0,0 +1,198 @@
+package dbfetch
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrNotReadOnly is reported by Run and Exec with ReadOnly for statements which could modify data.
+var ErrNotReadOnly = errors.New("dbfetch: statement is not read only")
+
+// readOnlyStarts are the keywords read only statements start with.
+var readOnlyStarts = []string{"SELECT", "WITH", "VALUES", "TABLE", "SHOW", "EXPLAIN", "DESCRIBE", "DESC"}
+
+// writeKeywords are rejected anywhere in read only statements,
+// e.g. in data modifying CTEs, SELECT ... INTO and SELECT ... FOR UPDATE.
+var writeKeywords = []string{
+	"INSERT", "UPDATE", "DELETE", "MERGE", "UPSERT", "INTO",
+	"CREATE", "DROP", "ALTER", "TRUNCATE", "GRANT", "REVOKE",
+	"CALL", "EXEC", "EXECUTE", "COPY", "LOCK",
+}
+
+// ReadOnly rejects statements which are not read only before they are sent to the database,
+// reported as ErrNotReadOnly. forbidden are further keywords to reject as dialect hints, e.g. "REPLACE" for MySQL.
+// See CheckReadOnly for the rules. Statements of FetchStmt are not checked.
+func (f *fetcher) ReadOnly(enforce bool, forbidden ...string) *fetcher {
+	f.readOnly = enforce
+	f.forbidden = forbidden
+	return f
+}
+
+// checkReadOnly checks the query of f if ReadOnly is enforced.
+func (f *fetcher) checkReadOnly(args []any) error {
+	if !f.readOnly || f.stmt != nil {
+		return nil
+	}
+	if err := CheckReadOnly(f.query, f.forbidden...); err != nil {
+		return newQueryError(f.query, args, PhasePrepare, err)
+	}
+	return nil
+}
+
+// CheckReadOnly checks with a lightweight parser whether query is a single read only statement.
+// It must start with SELECT, WITH, VALUES, TABLE, SHOW, EXPLAIN or DESCRIBE
+// and must not contain keywords of modifying statements like INSERT, UPDATE, DELETE, INTO or CREATE
+// or the further keywords in forbidden, outside of string literals, quoted identifiers and comments.
+// Quotes and comments are interpreted in the ways of all common databases; a query is rejected if any interpretation finds a problem.
+// The contents of MySQL comments starting with /*! or /*+ are checked like the rest of the query, the server runs them.
+// Side effects of functions are not detected, combine it with read only transactions where they matter.
+func CheckReadOnly(query string, forbidden ...string) error {
+	for i := range 1 << 4 {
+		q := sqlQuoting{
+			backslash:     i&1 != 0,
+			dollar:        i&2 != 0,
+			brackets:      i&4 != 0,
+			mysqlComments: i&8 != 0,
+		}
+		if err := checkWords(query, q, forbidden); err != nil {
+			return fmt.Errorf("%w: %v", ErrNotReadOnly, err)
+		}
+	}
+	return nil
+}
+
+// sqlQuoting is an interpretation of quotes and comments in SQL.
+type sqlQuoting struct {
+	// backslash escapes quotes in strings as in MySQL
+	backslash bool
+	// dollar quotes strings like $tag$...$tag$ as in PostgreSQL
+	dollar bool
+	// brackets quote identifiers like [name] as in SQL Server and SQLite
+	brackets bool
+	// mysqlComments starts comments with # and with -- only if it is followed by whitespace as in MySQL
+	mysqlComments bool
+}
+
+// checkWords checks the words of query with the interpretation q.
+func checkWords(query string, q sqlQuoting, forbidden []string) error {
+	words, err := sqlWords(query, q)
+	if err != nil {
+		return err
+	}
+	if len(words) == 0 {
+		return errors.New("empty statement")
+	}
+	if !slices.Contains(readOnlyStarts, words[0]) {
+		return fmt.Errorf("starts with %s", words[0])
+	}
+	for i, w := range words {
+		if w == ";" {
+			if i < len(words)-1 {
+				return errors.New("multiple statements")
+			}
+			continue
+		}
+		if slices.Contains(writeKeywords, w) || slices.ContainsFunc(forbidden, func(kw string) bool { return strings.EqualFold(kw, w) }) {
+			return fmt.Errorf("contains %s", w)
+		}
+	}
+	return nil
+}
+
+// sqlWords retrieves the unquoted words of query in upper case and semicolons, skipping comments and literals.
+func sqlWords(query string, q sqlQuoting) ([]string, error) {
+	var words []string
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '-' && strings.HasPrefix(query[i:], "--") && (!q.mysqlComments || i+2 == len(query) || query[i+2] <= ' '),
+			c == '#' && q.mysqlComments:
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return words, nil
+			}
+			i += end + 1
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return nil, errors.New("unterminated comment")
+			}
+			if body := query[i+2 : i+2+end]; strings.HasPrefix(body, "!") || strings.HasPrefix(body, "+") {
+				// executed by MySQL, /*! optionally with a minimum server version
+				inner, err := sqlWords(strings.TrimLeft(body[1:], "0123456789"), q)
+				if err != nil {
+					return nil, err
+				}
+				words = append(words, inner...)
+			}
+			i += 2 + end + 2
+		case c == '\'' || c == '"' || c == '`':
+			end, err := quoteEnd(query, i, c, q.backslash && c == '\'')
+			if err != nil {
+				return nil, err
+			}
+			i = end
+		case c == '[' && q.brackets:
+			end, err := quoteEnd(query, i, ']', false)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+		case c == '$' && q.dollar && i+1 < len(query) && !isDigit(query[i+1]):
+			tagEnd := strings.IndexByte(query[i+1:], '$')
+			if tagEnd < 0 {
+				return nil, errors.New("unterminated dollar quote")
+			}
+			tag := query[i : i+1+tagEnd+1]
+			end := strings.Index(query[i+len(tag):], tag)
+			if end < 0 {
+				return nil, errors.New("unterminated dollar quote")
+			}
+			i += len(tag) + end + len(tag)
+		case c == ';':
+			words = append(words, ";")
+			i++
+		case isIdentByte(c) || c == '$':
+			start := i
+			for i < len(query) && (isIdentByte(query[i]) || query[i] == '$' && !q.dollar) {
+				i++
+			}
+			if i == start {
+				// a parameter like $1
+				i++
+				continue
+			}
+			words = append(words, strings.ToUpper(query[start:i]))
+		default:
+			i++
+		}
+	}
+	return words, nil
+}
+
+// quoteEnd retrieves the index after the quoted literal or identifier starting at start and ending with quote.
+// Doubled quotes are escapes, with backslash also quotes preceded by a backslash.
+func quoteEnd(query string, start int, quote byte, backslash bool) (int, error) {
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if backslash {
+				i++
+			}
+		case quote:
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1, nil
+		}
+	}
+	return 0, errors.New("unterminated quote")
+}
+
+// isDigit reports whether c is an ASCII digit.
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
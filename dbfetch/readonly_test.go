@@ -0,0 +1,66 @@
+package dbfetch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+func TestCheckReadOnly(t *testing.T) {
+	for query, ok := range map[string]bool{
+		"select id from users":                                       true,
+		"  -- comment\n/* block */ SELECT 1;  ":                      true,
+		"with a as (select 1) select * from a":                       true,
+		"select 'delete from users' as x, \"update\" from t":         true,
+		"select `insert` from t where a = 'it''s'":                   true,
+		"select $$drop table x$$, $1::int":                           true,
+		"explain select * from users":                                true,
+		"select * from users where id = ANY($1) order by id desc":    true,
+		"insert into users (login) values ('x')":                     false,
+		"with d as (delete from users returning id) select * from d": false,
+		"select * into backup from users":                            false,
+		"select * from users for update":                             false,
+		"select 1; drop table users":                                 false,
+		"select 'a\\'; delete from users; --'":                       false,
+		"select $$ $$ ; delete from users; select $$ $$":             false,
+		"select 'unterminated":                                       false,
+		"select [name] from [t]]x]":                                  true,
+		"select a[length(']/*')] into evil from t -- */":             false,
+		"select * from t /*!50000 into outfile '/tmp/x' */":          false,
+		"select /*+ no_index(t) */ * from t":                         true,
+		"select 1 # '\n into outfile 'x' -- '":                       false,
+		"select a --'\n, ' into outfile '/tmp/x' -- '":               false,
+		"":                     false,
+		"/* only a comment */": false,
+	} {
+		err := CheckReadOnly(query)
+		if (err == nil) != ok {
+			t.Errorf("CheckReadOnly(%q) = %v, want ok %v", query, err, ok)
+		}
+		if err != nil && !errors.Is(err, ErrNotReadOnly) {
+			t.Errorf("CheckReadOnly(%q) = %v, want %v", query, err, ErrNotReadOnly)
+		}
+	}
+	if err := CheckReadOnly("select replace(a, 'x', 'y') from t", "replace"); err == nil {
+		t.Errorf("no error for a forbidden keyword")
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"select users": usersQuery["select users"],
+		"delete users": {RowsAffected: 3},
+	})
+	if _, err := Fetch(db, "select users").ReadOnly(true).All(ctx); err != nil {
+		t.Errorf("read only query rejected: %v", err)
+	}
+	if _, err := Fetch(db, "delete users").ReadOnly(true).Exec(ctx); !errors.Is(err, ErrNotReadOnly) {
+		t.Errorf("got %v, want %v", err, ErrNotReadOnly)
+	}
+	if calls := callLog(db); len(calls) != 1 {
+		t.Errorf("got calls %v, want only the query", calls)
+	}
+}
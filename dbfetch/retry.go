@@ -0,0 +1,84 @@
+package dbfetch
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"time"
+)
+
+// RetryPolicy configures retries of failed queries, see Retry.
+type RetryPolicy struct {
+	// Attempts is the maximum number of attempts including the first one.
+	Attempts int
+	// Backoff is the delay before the first retry, it doubles for each further retry.
+	Backoff time.Duration
+	// MaxBackoff limits the delay between retries, zero for no limit.
+	MaxBackoff time.Duration
+	// Retryable reports transient errors worth a retry.
+	// IsTransient is used if it is nil.
+	Retryable func(error) bool
+}
+
+// IsTransient reports whether err is likely to go away when the query is repeated:
+// broken connections, timeouts, serialization failures and deadlocks (see IsSerializationFailure)
+// and errors with a SQLSTATE of class 08 (connection exception).
+// Drivers without SQLState() string, e.g. for MySQL, need a custom RetryPolicy.Retryable.
+func IsTransient(err error) bool {
+	var ne net.Error
+	return isConnectionError(err) || errors.As(err, &ne) && ne.Timeout() || IsSerializationFailure(err)
+}
+
+// Retry repeats Run and Exec after transient errors with exponential backoff and jitter.
+// Run is only repeated if no row was passed to the yield func yet, rows are never delivered twice;
+// a later failure is returned.
+func (f *fetcher) Retry(policy RetryPolicy) *fetcher {
+	f.retry = &policy
+	return f
+}
+
+// delay retrieves the backoff before retry number n, starting at 1, with a random jitter of up to -50%.
+func (p *RetryPolicy) delay(n int) time.Duration {
+	d := p.Backoff
+	for range n - 1 {
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			break
+		}
+		d *= 2
+	}
+	if p.MaxBackoff > 0 {
+		d = min(d, p.MaxBackoff)
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// retried calls attempt until it succeeds or the retry policy of s gives up.
+// attempt sets delivered once a row was passed on, it is never repeated afterwards.
+func (f *fetcher) retried(ctx context.Context, s *settings, attempt func(delivered *bool) error) error {
+	delivered := false
+	err := attempt(&delivered)
+	p := s.retry
+	if p == nil {
+		return err
+	}
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IsTransient
+	}
+	for n := 1; n < p.Attempts && err != nil && !delivered && retryable(err); n++ {
+		f.retrying(ctx, n+1, err)
+		timer := time.NewTimer(p.delay(n))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+		err = attempt(&delivered)
+	}
+	return err
+}
@@ -0,0 +1,76 @@
+package dbfetch
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	results := map[string]dbfetchtest.Result{
+		"select users": usersQuery["select users"],
+		"select broken": {
+			Columns: dbfetchtest.Columns("id"),
+			Rows:    [][]any{{int64(1)}},
+			Err:     syscall.ECONNRESET,
+		},
+		"delete users": {RowsAffected: 3},
+	}
+	db := dbfetchtest.Open(t, results)
+	policy := RetryPolicy{Attempts: 3, Backoff: time.Millisecond}
+
+	db.Fail(syscall.ECONNRESET, sqlStateError("40001"))
+	var got []user
+	if err := Fetch(db, "select users").Retry(policy).AllInto(ctx, &got); err != nil || !slices.Equal(got, users) {
+		t.Errorf("got %v, %v, want %v", got, err, users)
+	}
+	if calls := callLog(db); len(calls) != 3 {
+		t.Errorf("got calls %v, want 3 attempts", calls)
+	}
+
+	db.Fail(syscall.ECONNRESET, syscall.ECONNRESET, syscall.ECONNRESET)
+	if err := Fetch(db, "select users").Retry(policy).Run(ctx); !errors.Is(err, syscall.ECONNRESET) {
+		t.Errorf("got error %v after all attempts failed", err)
+	}
+	if calls := callLog(db); len(calls) != 3 {
+		t.Errorf("got calls %v, want 3 attempts", calls)
+	}
+
+	db.Fail(errors.New("syntax error"))
+	if err := Fetch(db, "select users").Retry(policy).Run(ctx); err == nil {
+		t.Errorf("no error for a permanent failure")
+	}
+	if calls := callLog(db); len(calls) != 1 {
+		t.Errorf("got calls %v, want a single attempt for a permanent failure", calls)
+	}
+
+	var ids []int64
+	if err := Fetch(db, "select broken").Retry(policy).Column(ctx, &ids); !errors.Is(err, syscall.ECONNRESET) {
+		t.Errorf("got error %v, want the failure after the first row", err)
+	}
+	if calls := callLog(db); len(calls) != 1 {
+		t.Errorf("got calls %v, rows must not be delivered twice", calls)
+	}
+
+	db.Fail(driver.ErrBadConn)
+	if n, err := Fetch(db, "delete users").Retry(policy).ExecAffected(ctx); err != nil || n != 3 {
+		t.Errorf("got %d, %v, want 3 after a retry", n, err)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	p := RetryPolicy{Backoff: 10 * time.Millisecond, MaxBackoff: 25 * time.Millisecond}
+	for n, max := range []time.Duration{10, 20, 25, 25} {
+		max *= time.Millisecond
+		if d := p.delay(n + 1); d < max/2 || d > max {
+			t.Errorf("retry %d: got delay %v, want between %v and %v", n+1, d, max/2, max)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package dbfetch
+
+import (
+	"context"
+	"iter"
+)
+
+// Rows runs the query when the returned sequence is iterated and yields the values of each row.
+// The values are read from the scan destinations like in All, the slice is not reused.
+// Errors are yielded with a nil slice and end the sequence.
+// Errors after the iteration was ended early, e.g. of a session reset, are dropped.
+// Ending the iteration early closes the rows and cancels the query.
+// It replaces yield funcs set before.
+//
+//	for row, err := range dbfetch.Fetch(db, `select login, created from users`).Rows(ctx) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(row[0], row[1])
+//	}
+func (f *fetcher) Rows(ctx context.Context, args ...any) iter.Seq2[[]any, error] {
+	return func(yield func([]any, error) bool) {
+		f := f.call()
+		stopped := false
+		f.yield = func(r *run) error {
+			row := make([]any, len(r.dst))
+			for i, ptr := range r.dst {
+				row[i] = scannedValue(ptr)
+			}
+			if !yield(row, nil) {
+				stopped = true
+				return ErrStop
+			}
+			return nil
+		}
+		if err := f.Run(ctx, args...); err != nil && !stopped {
+			yield(nil, err)
+		}
+	}
+}
+
+// RowsAs runs the query of f when the returned sequence is iterated and yields every row as T.
+// Rows are mapped to T like in FetchAll.
+// Errors are yielded with the zero value of T and end the sequence,
+// errors after the iteration was ended early are dropped.
+// Ending the iteration early closes the rows and cancels the query.
+func RowsAs[T any](ctx context.Context, f *fetcher, args ...any) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var row T
+		stopped := false
+		err := f.call().scanInto(&row).
+			Yield(func() error {
+				if !yield(row, nil) {
+					stopped = true
+					return ErrStop
+				}
+				return nil
+			}).
+			Run(ctx, args...)
+		if err != nil && !stopped {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package dbfetch
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+func TestRows(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	var got [][]any
+	for row, err := range Fetch(db, "select users").Rows(ctx) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, row)
+	}
+	if len(got) != len(users) {
+		t.Fatalf("got %d rows, want %d", len(got), len(users))
+	}
+	for i, u := range users {
+		if want := []any{u.ID, u.Login}; !slices.Equal(got[i], want) {
+			t.Errorf("row %d: got %v, want %v", i, got[i], want)
+		}
+	}
+	n := 0
+	for range Fetch(db, "select users").Rows(ctx) {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("got %d iterations after break, want 1", n)
+	}
+	for _, err := range Fetch(db, "select unknown").Rows(ctx) {
+		if err == nil {
+			t.Errorf("no error for an unknown query")
+		}
+	}
+}
+
+func TestRowsAs(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	var got []user
+	for u, err := range RowsAs[user](ctx, Fetch(db, "select users")) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, u)
+	}
+	if !slices.Equal(got, users) {
+		t.Errorf("got %v, want %v", got, users)
+	}
+}
+
+func TestRowsErrorAfterBreak(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	// the failing reset is reported after the loop body stopped the iteration
+	failingReset := &SessionOptions{Reset: []string{"RESET unknown"}}
+	for range Fetch(db, "select users").Session(failingReset).Rows(ctx) {
+		break
+	}
+	for range RowsAs[user](ctx, Fetch(db, "select users").Session(failingReset)) {
+		break
+	}
+	for _, err := range Fetch(db, "select users").Session(failingReset).Rows(ctx) {
+		if err != nil {
+			return
+		}
+	}
+	t.Errorf("no error for a failing reset without break")
+}
@@ -0,0 +1,79 @@
+package dbfetch
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// Runner runs a query. It is implemented by the fetchers created with Fetch and FetchStmt.
+type Runner interface {
+	Run(ctx context.Context, args ...any) error
+}
+
+var _ Runner = (*fetcher)(nil)
+
+// RunAll runs independent queries concurrently with up to GOMAXPROCS at once, see RunAllN.
+//
+//	var users, orders int64
+//	err := dbfetch.RunAll(ctx,
+//		dbfetch.Fetch(db, `select count(*) from users`).ScanInto(&users),
+//		dbfetch.Fetch(db, `select count(*) from orders`).ScanInto(&orders),
+//	)
+func RunAll(ctx context.Context, fetchers ...Runner) error {
+	return RunAllN(ctx, 0, fetchers...)
+}
+
+// RunAllN runs independent queries concurrently with up to n at once, GOMAXPROCS if n is not positive.
+// The fetchers must be set up completely, they are run without arguments.
+// The first failure cancels the other queries, queries not started yet are skipped.
+// The errors of all failed queries are joined, those caused by the cancellation are left out.
+func RunAllN(ctx context.Context, n int, fetchers ...Runner) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		errs    []error
+		skipped bool
+		sem     = make(chan struct{}, n)
+	)
+	for _, f := range fetchers {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			skipped = true
+			break
+		}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			err := f.Run(ctx)
+			if err == nil {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if len(errs) > 0 && errors.Is(err, context.Canceled) {
+				// cancelled because of an earlier failure
+				return
+			}
+			errs = append(errs, err)
+			cancel(err)
+		})
+	}
+	wg.Wait()
+	if len(errs) == 0 && skipped {
+		// only the parent context can be done without a failure
+		return context.Cause(ctx)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,51 @@
+package dbfetch
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+// runnerFunc is a Runner calling itself.
+type runnerFunc func(ctx context.Context) error
+
+func (r runnerFunc) Run(ctx context.Context, args ...any) error {
+	return r(ctx)
+}
+
+func TestRunAll(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	var a, b []user
+	err := RunAll(ctx,
+		Fetch(db, "select users").scanInto(new(user)).Yield(func() error { a = append(a, user{}); return nil }),
+		Fetch(db, "select users").scanInto(new(user)).Yield(func() error { b = append(b, user{}); return nil }),
+	)
+	if err != nil || len(a) != len(users) || len(b) != len(users) {
+		t.Errorf("got %d and %d rows, %v, want %d each", len(a), len(b), err, len(users))
+	}
+
+	errFail := errors.New("fail")
+	var running, peak, started atomic.Int32
+	block := runnerFunc(func(ctx context.Context) error {
+		started.Add(1)
+		peak.Store(max(peak.Load(), running.Add(1)))
+		defer running.Add(-1)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	fail := runnerFunc(func(ctx context.Context) error {
+		started.Add(1)
+		return errFail
+	})
+	err = RunAllN(ctx, 2, block, fail, block, block)
+	if !errors.Is(err, errFail) || errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want only %v", err, errFail)
+	}
+	if peak.Load() > 2 || started.Load() > 3 {
+		t.Errorf("%d at once and %d started, want at most 2 and 3", peak.Load(), started.Load())
+	}
+}
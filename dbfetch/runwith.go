@@ -0,0 +1,65 @@
+package dbfetch
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// RunOption overrides a setting of a fetcher for a single call of RunWith or ExecWith.
+type RunOption func(*settings)
+
+// Timeout overrides the timeout of the fetcher, see its method Timeout.
+func Timeout(d time.Duration) RunOption {
+	return func(s *settings) {
+		s.timeout = d
+	}
+}
+
+// Cancel overrides the number of rows after which the query is cancelled, see the method Cancel.
+func Cancel(onRow int) RunOption {
+	return func(s *settings) {
+		s.cancelRow = onRow
+	}
+}
+
+// MaxRows overrides the row limit of the fetcher, see its method MaxRows.
+func MaxRows(n int, truncate bool) RunOption {
+	return func(s *settings) {
+		s.maxRows = max(n, 0)
+		s.truncate = truncate
+	}
+}
+
+// Retry overrides the retry policy of the fetcher, see its method Retry.
+// A policy with less than two Attempts disables retries.
+func Retry(policy RetryPolicy) RunOption {
+	return func(s *settings) {
+		s.retry = &policy
+	}
+}
+
+// RunWith is Run with settings overridden by opts. The settings of f are not modified,
+// so runs tuned differently do not affect each other or later calls of Run.
+// Each run keeps its own state like the connection of a Session and derived scan destinations,
+// so concurrent runs are safe if f has no scan destinations set with ScanInto and its yield func is,
+// e.g. with YieldColumns or YieldMap and a func safe for concurrent use.
+//
+//	f := dbfetch.Fetch(db, `select id, payload from events where id > ?`).Yield(handle)
+//	err := f.RunWith(ctx, []any{last}, dbfetch.Timeout(time.Second), dbfetch.MaxRows(100, true))
+func (f *fetcher) RunWith(ctx context.Context, args []any, opts ...RunOption) error {
+	s := f.settings
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return f.runWith(ctx, &s, args)
+}
+
+// ExecWith is Exec with settings overridden by opts, see RunWith.
+func (f *fetcher) ExecWith(ctx context.Context, args []any, opts ...RunOption) (sql.Result, error) {
+	s := f.settings
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return f.execWith(ctx, &s, args)
+}
@@ -0,0 +1,105 @@
+package dbfetch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+func TestRunWith(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"select users": usersQuery["select users"],
+		"delete users": {RowsAffected: 3},
+	})
+	n := 0
+	f := Fetch(db, "select users").
+		ScanInto(new(int64), new(string)).
+		Yield(func() error {
+			n++
+			return nil
+		})
+	if err := f.RunWith(ctx, nil, MaxRows(1, true)); err != nil || n != 1 {
+		t.Errorf("got %d rows, %v, want 1", n, err)
+	}
+	n = 0
+	if err := f.RunWith(ctx, nil, MaxRows(1, false)); !errors.Is(err, ErrTooManyRows) {
+		t.Errorf("got %v, want %v", err, ErrTooManyRows)
+	}
+	n = 0
+	if err := f.Run(ctx); err != nil || n != len(users) || f.maxRows != 0 {
+		t.Errorf("got %d rows, %v, want %d without a limit", n, err, len(users))
+	}
+	n = 0
+	if err := f.RunWith(ctx, nil, Cancel(2)); err != nil || n != 2 {
+		t.Errorf("got %d rows, %v, want 2", n, err)
+	}
+	slow := Fetch(db, "select users").Yield(func() error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	})
+	var te *TimeoutError
+	if err := slow.RunWith(ctx, nil, Timeout(5*time.Millisecond)); !errors.As(err, &te) {
+		t.Errorf("got %v, want a timeout", err)
+	}
+	callLog(db)
+
+	db.Fail(syscall.ECONNRESET)
+	del := Fetch(db, "delete users")
+	if _, err := del.ExecWith(ctx, nil, Retry(RetryPolicy{Attempts: 2})); err != nil {
+		t.Errorf("not retried: %v", err)
+	}
+	if calls := callLog(db); len(calls) != 2 {
+		t.Errorf("got calls %v, want 2 attempts", calls)
+	}
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Go(func() {
+			if _, err := del.ExecWith(ctx, nil, Timeout(time.Duration(i+1)*time.Second)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+	if del.retry != nil || del.timeout != 0 {
+		t.Errorf("settings of the fetcher were modified")
+	}
+}
+
+func TestRunWithConcurrent(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"select users":         usersQuery["select users"],
+		"SET search_path TO a": {},
+	})
+	var rows atomic.Int64
+	// derived scan destinations, a session and reused buffers are state of each run
+	f := Fetch(db, "select users").
+		Session(&SessionOptions{Setup: []string{"SET search_path TO a"}}).
+		ReuseBuffers(true).
+		YieldColumns(func(row []any) error {
+			if len(row) != 2 {
+				t.Errorf("got row %v", row)
+			}
+			rows.Add(1)
+			return nil
+		})
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			if err := f.RunWith(ctx, nil, Cancel(i%3+1)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+	if want := int64(3*1 + 3*2 + 2*3); rows.Load() != want {
+		t.Errorf("got %d rows, want %d", rows.Load(), want)
+	}
+}
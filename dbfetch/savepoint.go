@@ -0,0 +1,87 @@
+package dbfetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// SavepointDialect contains the statements for savepoints as format strings for the savepoint name.
+// An empty Release is not run.
+type SavepointDialect struct {
+	Save, Rollback, Release string
+}
+
+var (
+	// StandardSavepoints are the savepoint statements of PostgreSQL, MySQL, SQLite and Oracle.
+	StandardSavepoints = SavepointDialect{
+		Save:     "SAVEPOINT %s",
+		Rollback: "ROLLBACK TO SAVEPOINT %s",
+		Release:  "RELEASE SAVEPOINT %s",
+	}
+	// SQLServerSavepoints are the savepoint statements of Microsoft SQL Server, which releases them with the transaction.
+	SQLServerSavepoints = SavepointDialect{
+		Save:     "SAVE TRANSACTION %s",
+		Rollback: "ROLLBACK TRANSACTION %s",
+	}
+)
+
+// WithSavepoint runs fn inside the transaction tx after creating the savepoint name with StandardSavepoints.
+// See SavepointDialect.WithSavepoint.
+//
+//	err := dbfetch.InTx(ctx, db, nil, func(tx dbfetch.Querier) error {
+//		if err := createOrder(ctx, tx, order); err != nil {
+//			return err
+//		}
+//		// sending the notification is optional
+//		err := dbfetch.WithSavepoint(ctx, tx, "notify", func(tx dbfetch.Querier) error {
+//			return queueNotification(ctx, tx, order)
+//		})
+//		if err != nil {
+//			log.Printf("no notification for order %d: %v", order.ID, err)
+//		}
+//		return nil
+//	})
+func WithSavepoint(ctx context.Context, tx Querier, name string, fn func(tx Querier) error) error {
+	return StandardSavepoints.WithSavepoint(ctx, tx, name, fn)
+}
+
+// WithSavepoint runs fn inside the transaction tx after creating the savepoint name.
+// If fn returns an error, the changes of fn are rolled back to the savepoint and the error is returned;
+// the transaction itself stays usable. Otherwise the savepoint is released.
+// Savepoints can be nested with different names. name must be a plain identifier.
+func (d SavepointDialect) WithSavepoint(ctx context.Context, tx Querier, name string, fn func(tx Querier) error) error {
+	if !isIdentifier(name) {
+		return fmt.Errorf("invalid savepoint name %q", name)
+	}
+	if _, err := Fetch(tx, fmt.Sprintf(d.Save, name)).Exec(ctx); err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		if _, rerr := Fetch(tx, fmt.Sprintf(d.Rollback, name)).Exec(ctx); rerr != nil {
+			return errors.Join(err, rerr)
+		}
+		return err
+	}
+	if d.Release == "" {
+		return nil
+	}
+	_, err := Fetch(tx, fmt.Sprintf(d.Release, name)).Exec(ctx)
+	return err
+}
+
+// isIdentifier reports whether name consists of ASCII letters, digits and underscores
+// and does not start with a digit.
+func isIdentifier(name string) bool {
+	if name == "" || '0' <= name[0] && name[0] <= '9' {
+		return false
+	}
+	for _, c := range []byte(name) {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
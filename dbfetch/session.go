@@ -0,0 +1,90 @@
+package dbfetch
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+)
+
+// SessionOptions configure a pinned session, see Session.
+type SessionOptions struct {
+	// Setup are statements run on the connection before the query, e.g. "SET search_path TO app".
+	Setup []string
+	// Reset are statements run after the query to restore the session before the connection is reused.
+	Reset []string
+	// Discard closes the connection after the query instead of returning it to the pool.
+	Discard bool
+}
+
+// Session runs Run and Exec on a single connection of the pool after running the setup statements of opts.
+// The connection is released afterwards, the session settings stay active unless opts.Reset or opts.Discard is used.
+// Without a pool, e.g. with a *sql.Tx, the statements run on the fetcher's Querier.
+// A StmtCache is not used in sessions, UseStmt prepares statements on the connection;
+// statements of FetchStmt are not pinned.
+// nil disables the session.
+//
+//	err := dbfetch.Fetch(db, `select id, name from accounts`).
+//		Session(&dbfetch.SessionOptions{
+//			Setup: []string{"SET search_path TO tenant_42"},
+//			Reset: []string{"RESET search_path"},
+//		}).
+//		AllInto(ctx, &accounts)
+func (f *fetcher) Session(opts *SessionOptions) *fetcher {
+	f.session = opts
+	return f
+}
+
+// inSession calls attempt in the session of f if it has one.
+// The connection of the session is used by the run r.
+func (f *fetcher) inSession(ctx context.Context, r *run, attempt func() error) (err error) {
+	opts := f.session
+	if opts == nil {
+		return attempt()
+	}
+	pool, ok := r.db.(interface {
+		Conn(ctx context.Context) (*sql.Conn, error)
+	})
+	if !ok {
+		if err := execAll(ctx, r.db, opts.Setup); err != nil {
+			return err
+		}
+		return errors.Join(attempt(), execAll(ctx, r.db, opts.Reset))
+	}
+	conn, err := pool.Conn(ctx)
+	if err != nil {
+		return newQueryError(f.query, nil, PhaseQuery, err)
+	}
+	discard := opts.Discard
+	defer func() {
+		if discard {
+			// the pool closes connections reported as bad instead of reusing them
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}()
+	db, cache := r.db, r.cache
+	r.db, r.cache = conn, nil
+	defer func() { r.db, r.cache = db, cache }()
+	if err := execAll(ctx, conn, opts.Setup); err != nil {
+		discard = true
+		return err
+	}
+	err = attempt()
+	if rerr := execAll(ctx, conn, opts.Reset); rerr != nil {
+		// do not reuse a session which could not be restored
+		discard = true
+		return errors.Join(err, rerr)
+	}
+	return err
+}
+
+// execAll runs the statements on db.
+func execAll(ctx context.Context, db Querier, statements []string) error {
+	for _, stmt := range statements {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return newQueryError(stmt, nil, PhaseExec, err)
+		}
+	}
+	return nil
+}
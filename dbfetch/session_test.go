@@ -0,0 +1,47 @@
+package dbfetch
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+func TestSession(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"select users":         usersQuery["select users"],
+		"SET search_path TO a": {},
+		"RESET search_path":    {},
+		"delete users":         {RowsAffected: 3},
+	})
+	opts := &SessionOptions{
+		Setup: []string{"SET search_path TO a"},
+		Reset: []string{"RESET search_path"},
+	}
+	var got []user
+	if err := Fetch(db, "select users").Session(opts).AllInto(ctx, &got); err != nil || !slices.Equal(got, users) {
+		t.Errorf("got %v, %v, want %v", got, err, users)
+	}
+	want := []string{"exec SET search_path TO a", "query select users", "exec RESET search_path"}
+	if calls := callLog(db); !slices.Equal(calls, want) {
+		t.Errorf("got calls %v, want %v", calls, want)
+	}
+
+	err := InTx(ctx, db, nil, func(tx Querier) error {
+		_, err := Fetch(tx, "delete users").Session(&SessionOptions{Setup: opts.Setup, Discard: true}).Exec(ctx)
+		return err
+	})
+	want = []string{"begin", "exec SET search_path TO a", "exec delete users", "commit"}
+	if calls := callLog(db); err != nil || !slices.Equal(calls, want) {
+		t.Errorf("got calls %v, %v, want %v", calls, err, want)
+	}
+
+	if err := Fetch(db, "select users").Session(&SessionOptions{Setup: []string{"SET unknown"}}).Run(ctx); err == nil {
+		t.Errorf("no error for a failed setup")
+	}
+	if calls := callLog(db); slices.Contains(calls, "query select users") {
+		t.Errorf("query ran after a failed setup: %v", calls)
+	}
+}
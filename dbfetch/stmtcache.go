@@ -0,0 +1,146 @@
+package dbfetch
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+// StmtCacheMetrics are counters of a StmtCache.
+type StmtCacheMetrics struct {
+	// Hits and Misses count statements served from the cache and newly prepared ones.
+	Hits, Misses int64
+	// Evictions counts statements closed to stay below the size limit.
+	Evictions int64
+	// Size is the number of cached statements.
+	Size int
+}
+
+// StmtCache keeps prepared statements of a database for reuse, keyed by the query text.
+// Its methods are safe for concurrent use.
+type StmtCache struct {
+	db  Querier
+	max int
+
+	mu    sync.Mutex
+	stmts map[string]*list.Element
+	// lru contains the *cachedStmt values, the most recently used first
+	lru     list.List
+	metrics StmtCacheMetrics
+}
+
+// cachedStmt is a statement in a StmtCache.
+type cachedStmt struct {
+	query string
+	stmt  *sql.Stmt
+	// refs counts the runs using stmt, it is closed when it is evicted and unused
+	refs    int
+	evicted bool
+}
+
+// NewStmtCache creates a StmtCache preparing statements on db.
+// If more than max statements are cached, the least recently used one is closed; max <= 0 is no limit.
+// Statements still in use by a query are closed when the query is done.
+func NewStmtCache(db Querier, max int) *StmtCache {
+	return &StmtCache{
+		db:    db,
+		max:   max,
+		stmts: make(map[string]*list.Element),
+	}
+}
+
+// Metrics retrieves the current counters.
+func (c *StmtCache) Metrics() StmtCacheMetrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m := c.metrics
+	m.Size = c.lru.Len()
+	return m
+}
+
+// Close closes all cached statements and empties the cache.
+// Statements still in use are closed when the queries using them are done.
+func (c *StmtCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var errs []error
+	for e := c.lru.Front(); e != nil; e = e.Next() {
+		errs = append(errs, c.evict(e.Value.(*cachedStmt)))
+	}
+	c.lru.Init()
+	clear(c.stmts)
+	return errors.Join(errs...)
+}
+
+// evict closes cs if it is unused or marks it to be closed on its last release.
+// The caller must hold c.mu.
+func (c *StmtCache) evict(cs *cachedStmt) error {
+	cs.evicted = true
+	if cs.refs > 0 {
+		return nil
+	}
+	return cs.stmt.Close()
+}
+
+// acquire retrieves the statement of cs and a func to call when it is not used anymore.
+// The caller must hold c.mu.
+func (c *StmtCache) acquire(cs *cachedStmt) (*sql.Stmt, func()) {
+	cs.refs++
+	return cs.stmt, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if cs.refs--; cs.refs == 0 && cs.evicted {
+			cs.stmt.Close()
+		}
+	}
+}
+
+// prepare retrieves the cached statement for query or prepares it.
+// The statement stays open until release is called.
+func (c *StmtCache) prepare(ctx context.Context, query string) (stmt *sql.Stmt, release func(), err error) {
+	c.mu.Lock()
+	if e, ok := c.stmts[query]; ok {
+		c.lru.MoveToFront(e)
+		c.metrics.Hits++
+		defer c.mu.Unlock()
+		stmt, release = c.acquire(e.Value.(*cachedStmt))
+		return stmt, release, nil
+	}
+	c.metrics.Misses++
+	c.mu.Unlock()
+	// do not block other queries while preparing
+	stmt, err = c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.stmts[query]; ok {
+		// prepared concurrently
+		stmt.Close()
+		c.lru.MoveToFront(e)
+		stmt, release = c.acquire(e.Value.(*cachedStmt))
+		return stmt, release, nil
+	}
+	cs := &cachedStmt{query: query, stmt: stmt}
+	c.stmts[query] = c.lru.PushFront(cs)
+	stmt, release = c.acquire(cs)
+	for c.max > 0 && c.lru.Len() > c.max {
+		oldest := c.lru.Remove(c.lru.Back()).(*cachedStmt)
+		delete(c.stmts, oldest.query)
+		c.evict(oldest)
+		c.metrics.Evictions++
+	}
+	return stmt, release, nil
+}
+
+// UseStmtCache runs the query as a prepared statement from cache.
+// The statement is prepared on the database of the cache; inside a transaction
+// on the fetcher's *sql.Tx, the cached statement is bound to it with StmtContext.
+// A nil cache disables it.
+func (f *fetcher) UseStmtCache(cache *StmtCache) *fetcher {
+	f.cache = cache
+	return f
+}
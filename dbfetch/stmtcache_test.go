@@ -0,0 +1,80 @@
+package dbfetch
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+func TestStmtCache(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	cache := NewStmtCache(db, 1)
+	defer cache.Close()
+	for range 3 {
+		var got []user
+		if err := Fetch(db, "select users").UseStmtCache(cache).AllInto(ctx, &got); err != nil || !slices.Equal(got, users) {
+			t.Fatalf("got %v, %v, want %v", got, err, users)
+		}
+	}
+	want := []string{"prepare select users", "query select users", "query select users", "query select users"}
+	if calls := callLog(db); !slices.Equal(calls, want) {
+		t.Errorf("got calls %v, want %v", calls, want)
+	}
+	if err := Fetch(db, "select none").UseStmtCache(cache).Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if m, want := cache.Metrics(), (StmtCacheMetrics{Hits: 2, Misses: 2, Evictions: 1, Size: 1}); m != want {
+		t.Errorf("got metrics %+v, want %+v", m, want)
+	}
+
+	err := InTx(ctx, db, nil, func(tx Querier) error {
+		return Fetch(tx, "select none").UseStmtCache(cache).Run(ctx)
+	})
+	if err != nil {
+		t.Errorf("cached statement in a transaction failed: %v", err)
+	}
+}
+
+func TestStmtCacheConcurrentEviction(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	cache := NewStmtCache(db, 1)
+	defer cache.Close()
+	stmt, release, err := cache.prepare(ctx, "select users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// evicts the statement in use
+	_, releaseOther, err := cache.prepare(ctx, "select none")
+	if err != nil {
+		t.Fatal(err)
+	}
+	releaseOther()
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		t.Fatalf("evicted statement was closed while in use: %v", err)
+	}
+	rows.Close()
+	release()
+	if _, err := stmt.QueryContext(ctx); err == nil {
+		t.Errorf("evicted statement was not closed after its release")
+	}
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			for j := range 200 {
+				// alternate queries to evict the statement used by other goroutines
+				query := []string{"select users", "select none"}[(i+j)%2]
+				if err := Fetch(db, query).UseStmtCache(cache).Run(ctx); err != nil {
+					t.Errorf("%s failed: %v", query, err)
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
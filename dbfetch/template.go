@@ -0,0 +1,153 @@
+package dbfetch
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// templateFuncs are the only funcs whose results templates may output.
+var templateFuncs = []string{"ident", "arg", "args"}
+
+// Template renders queries with text/template without interpolating values into the SQL.
+// Every output of the template must be the result of one of these funcs:
+//
+//   - ident name: name if it is one of the allowed identifiers, an error otherwise
+//   - arg value: a placeholder for value, which becomes a query argument
+//   - args values: comma separated placeholders for the elements of a slice, e.g. for IN lists
+//
+// Other outputs like {{.Name}} are rejected when the template is parsed; constant text is kept as it is.
+//
+//	t, err := dbfetch.ParseTemplate(`select id, login from users where true`+
+//		`{{if .Login}} and login = {{arg .Login}}{{end}}`+
+//		`{{if .IDs}} and id in ({{args .IDs}}){{end}}`+
+//		` order by {{ident .Order}}`, "login", "created")
+//	...
+//	query, args, err := t.Render(filter)
+type Template struct {
+	// Dollar uses the placeholders $1, $2, ... instead of ? as required by PostgreSQL.
+	Dollar bool
+
+	tmpl   *template.Template
+	idents []string
+}
+
+// ParseTemplate parses text as a query template allowing identifiers to be interpolated with ident.
+func ParseTemplate(text string, identifiers ...string) (*Template, error) {
+	funcs := template.FuncMap{}
+	for _, name := range templateFuncs {
+		funcs[name] = func(any) (string, error) {
+			return "", fmt.Errorf("%s is only available in Render", name)
+		}
+	}
+	tmpl, err := template.New("query").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		if err := checkOutputs(t.Tree.Root); err != nil {
+			return nil, fmt.Errorf("template %s: %w", t.Name(), err)
+		}
+	}
+	return &Template{tmpl: tmpl, idents: slices.Clone(identifiers)}, nil
+}
+
+// checkOutputs reports actions below node which output anything but the results of templateFuncs.
+func checkOutputs(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkOutputs(child); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) > 0 {
+			// assignments do not output anything
+			return nil
+		}
+		cmds := n.Pipe.Cmds
+		last := cmds[len(cmds)-1]
+		if id, ok := last.Args[0].(*parse.IdentifierNode); ok && slices.Contains(templateFuncs, id.Ident) {
+			return nil
+		}
+		return fmt.Errorf("%s outputs a value, use ident, arg or args", n)
+	case *parse.IfNode:
+		return checkBranches(n.List, n.ElseList)
+	case *parse.RangeNode:
+		return checkBranches(n.List, n.ElseList)
+	case *parse.WithNode:
+		return checkBranches(n.List, n.ElseList)
+	}
+	return nil
+}
+
+// checkBranches calls checkOutputs for both branches of a control structure.
+func checkBranches(list, elseList *parse.ListNode) error {
+	if err := checkOutputs(list); err != nil {
+		return err
+	}
+	return checkOutputs(elseList)
+}
+
+// Render executes the template with data and retrieves the query and its arguments.
+func (t *Template) Render(data any) (query string, args []any, err error) {
+	placeholder := func(v any) string {
+		args = append(args, v)
+		if t.Dollar {
+			return "$" + strconv.Itoa(len(args))
+		}
+		return "?"
+	}
+	tmpl, err := t.tmpl.Clone()
+	if err != nil {
+		return "", nil, err
+	}
+	tmpl.Funcs(template.FuncMap{
+		"ident": func(name string) (string, error) {
+			if !slices.Contains(t.idents, name) {
+				return "", fmt.Errorf("identifier %q is not allowed", name)
+			}
+			return name, nil
+		},
+		"arg": placeholder,
+		"args": func(values any) (string, error) {
+			v := reflect.ValueOf(values)
+			if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+				return "", fmt.Errorf("args expects a slice, got %T", values)
+			}
+			if v.Len() == 0 {
+				return "", fmt.Errorf("args expects at least one value")
+			}
+			ps := make([]string, v.Len())
+			for i := range v.Len() {
+				ps[i] = placeholder(v.Index(i).Interface())
+			}
+			return strings.Join(ps, ", "), nil
+		},
+	})
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", nil, err
+	}
+	return sb.String(), args, nil
+}
+
+// Fetch renders the template with data and creates a fetcher for the query with its arguments set.
+func (t *Template) Fetch(db Querier, data any) (*fetcher, error) {
+	query, args, err := t.Render(data)
+	if err != nil {
+		return nil, err
+	}
+	return Fetch(db, query).Args(args...), nil
+}
@@ -0,0 +1,55 @@
+package dbfetch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate(`select id from users where true`+
+		`{{if .Login}} and login = {{arg .Login}}{{end}}`+
+		`{{with .IDs}} and id in ({{args .}}){{end}}`+
+		` order by {{ident .Order}}`, "login", "created")
+	if err != nil {
+		t.Fatal(err)
+	}
+	type filter struct {
+		Login string
+		IDs   []int
+		Order string
+	}
+	for _, tc := range []struct {
+		data   filter
+		dollar bool
+		query  string
+		args   []any
+	}{
+		{filter{Order: "login"}, false, "select id from users where true order by login", nil},
+		{filter{Login: "x' or 1=1 --", IDs: []int{1, 2}, Order: "created"}, false,
+			"select id from users where true and login = ? and id in (?, ?) order by created", []any{"x' or 1=1 --", 1, 2}},
+		{filter{Login: "alice", IDs: []int{3}, Order: "created"}, true,
+			"select id from users where true and login = $1 and id in ($2) order by created", []any{"alice", 3}},
+	} {
+		tmpl.Dollar = tc.dollar
+		query, args, err := tmpl.Render(tc.data)
+		if err != nil || query != tc.query || !reflect.DeepEqual(args, tc.args) {
+			t.Errorf("%+v: got %q %v, %v, want %q %v", tc.data, query, args, err, tc.query, tc.args)
+		}
+	}
+	if _, _, err := tmpl.Render(filter{Order: "password; drop table users"}); err == nil {
+		t.Errorf("no error for an identifier not allowed")
+	}
+	for _, text := range []string{
+		`select {{.Column}} from users`,
+		`select id from users where login = '{{.Login | printf "%s"}}'`,
+		`{{if true}}{{else}}{{.X}}{{end}}`,
+		`{{define "x"}}{{.}}{{end}}select 1`,
+	} {
+		if _, err := ParseTemplate(text); err == nil {
+			t.Errorf("no error for %s", text)
+		}
+	}
+	if _, err := ParseTemplate(`{{$x := .X}}select {{arg $x}}`); err != nil {
+		t.Errorf("assignment rejected: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package dbfetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TimeoutError reports a query ended by a timeout of the fetcher or of the database server.
+// Cancellations of the caller's context are reported as they are, e.g. as context.Canceled.
+type TimeoutError struct {
+	Query string
+	// Timeout is the duration set with Timeout, it is zero for timeouts of the server.
+	Timeout time.Duration
+	// Server reports a timeout of the database server, e.g. statement_timeout of PostgreSQL.
+	Server bool
+	Err    error
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Server {
+		return fmt.Sprintf("server timeout for query %q: %v", e.Query, e.Err)
+	}
+	return fmt.Sprintf("timeout after %v for query %q: %v", e.Timeout, e.Query, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports true for ErrTimeout.
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrTimeout
+}
+
+// Timeout limits the duration of Run and Exec, including retries, independently of the caller's context.
+// Exceeding it is reported as *TimeoutError.
+func (f *fetcher) Timeout(d time.Duration) *fetcher {
+	f.timeout = d
+	return f
+}
+
+// Cancel stops Run after onRow rows by cancelling the query; Run reports no error.
+// It bounds expensive queries without a LIMIT clause; zero or less reads all rows.
+func (f *fetcher) Cancel(onRow int) *fetcher {
+	f.cancelRow = onRow
+	return f
+}
+
+// withTimeout derives the context for Run and Exec.
+func (s *settings) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.timeout > 0 {
+		return context.WithTimeout(ctx, s.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// isServerTimeout reports errors caused by statement timeouts of the database server,
+// detected by SQLSTATE 57014 (query_canceled) of PostgreSQL.
+func isServerTimeout(err error) bool {
+	return sqlState(err) == "57014"
+}
+
+// timeoutError wraps errors caused by a timeout in *TimeoutError.
+// parent is the caller's context, its cancellation is not a timeout of the fetcher.
+func (f *fetcher) timeoutError(parent context.Context, s *settings, err error) error {
+	if err == nil || parent.Err() != nil {
+		return err
+	}
+	if s.timeout > 0 && errors.Is(err, context.DeadlineExceeded) {
+		return &TimeoutError{Query: f.query, Timeout: s.timeout, Err: err}
+	}
+	if isServerTimeout(err) {
+		return &TimeoutError{Query: f.query, Server: true, Err: err}
+	}
+	return err
+}
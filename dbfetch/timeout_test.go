@@ -0,0 +1,48 @@
+package dbfetch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+func TestTimeout(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	slow := func() error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}
+	err := Fetch(db, "select users").Timeout(5 * time.Millisecond).Yield(slow).Run(ctx)
+	var te *TimeoutError
+	if !errors.As(err, &te) || te.Server || te.Timeout != 5*time.Millisecond || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want a client timeout", err)
+	}
+
+	db.Fail(sqlStateError("57014"))
+	err = Fetch(db, "select users").Run(ctx)
+	if !errors.As(err, &te) || !te.Server {
+		t.Errorf("got error %v, want a server timeout", err)
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	err = Fetch(db, "select users").Timeout(time.Minute).Yield(func() error {
+		cancel()
+		return slow()
+	}).Run(cctx)
+	if !errors.Is(err, context.Canceled) || errors.As(err, &te) {
+		t.Errorf("got error %v, want a cancellation of the caller", err)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	var got []user
+	if err := Fetch(db, "select users").Cancel(2).AllInto(ctx, &got); err != nil || len(got) != 2 {
+		t.Errorf("got %v, %v, want 2 rows", got, err)
+	}
+}
@@ -0,0 +1,115 @@
+package dbfetch
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Duplicates is the policy of ToMap for rows with a key that was seen before.
+type Duplicates int
+
+const (
+	// KeepLast overwrites the value of earlier rows with the same key.
+	KeepLast Duplicates = iota
+	// KeepFirst ignores later rows with the same key.
+	KeepFirst
+	// RejectDuplicates stops the query with a *DuplicateKeyError.
+	RejectDuplicates
+)
+
+// DuplicateKeyError is reported by ToMap with RejectDuplicates for a key contained in more than one row.
+type DuplicateKeyError struct {
+	Key any
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("dbfetch: duplicate key %v", e.Key)
+}
+
+// keyField retrieves the index of the field of struct type t tagged with the option key, e.g. `db:"id,key"`.
+func keyField(t reflect.Type) ([]int, bool) {
+	for _, sf := range reflect.VisibleFields(t) {
+		_, opts, _ := strings.Cut(sf.Tag.Get("db"), ",")
+		if sf.IsExported() && opts == "key" {
+			return sf.Index, true
+		}
+	}
+	return nil, false
+}
+
+// ToMap runs the query of f and collects the rows in a map.
+//
+// If V is a struct, the columns are mapped to its fields like in FetchAll
+// and the key is the field tagged with the option key, e.g. `db:"id,key"`,
+// or the field of the first column if no field has the option.
+// Otherwise, the result must have two columns, the first is the key and the second the value.
+// dup decides which row is kept for duplicate keys.
+//
+//	accessCount, err := dbfetch.ToMap[string, int](ctx,
+//		dbfetch.Fetch(db, `select login, count(*) from accesses group by login`),
+//		dbfetch.RejectDuplicates,
+//	)
+func ToMap[K comparable, V any](ctx context.Context, f *fetcher, dup Duplicates) (map[K]V, error) {
+	f = f.call()
+	var (
+		key K
+		val V
+		// structKey retrieves the key of a struct value, it is nil for two columns
+		structKey func() K
+	)
+	m := make(map[K]V)
+	f.InitColumns(func(cts []*sql.ColumnType, err error) error {
+		if err != nil {
+			return err
+		}
+		v := reflect.ValueOf(&val)
+		if !isStruct(v.Elem().Type()) {
+			if len(cts) != 2 {
+				return fmt.Errorf("expected two columns for key and value, got %d", len(cts))
+			}
+			f.dst = []any{&key, &val}
+			return nil
+		}
+		if f.dst, err = scanTargets(v, cts); err != nil {
+			return err
+		}
+		idx, ok := keyField(v.Elem().Type())
+		if !ok {
+			if len(cts) == 0 {
+				return fmt.Errorf("no columns for the key")
+			}
+			idx, _ = fieldFor(v.Elem().Type(), cts[0].Name())
+		}
+		field := v.Elem().FieldByIndex(idx)
+		keyType := reflect.TypeFor[K]()
+		if !field.Type().ConvertibleTo(keyType) {
+			return fmt.Errorf("key field of type %v can not be converted to %v", field.Type(), keyType)
+		}
+		structKey = func() K {
+			return field.Convert(keyType).Interface().(K)
+		}
+		return nil
+	})
+	f.Yield(func() error {
+		if structKey != nil {
+			key = structKey()
+		}
+		if _, seen := m[key]; seen {
+			switch dup {
+			case KeepFirst:
+				return nil
+			case RejectDuplicates:
+				return &DuplicateKeyError{Key: key}
+			}
+		}
+		m[key] = val
+		return nil
+	})
+	if err := f.Run(ctx); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,65 @@
+package dbfetch
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+var accessQueries = map[string]dbfetchtest.Result{
+	"select access": {
+		Columns: dbfetchtest.Columns("login", "count"),
+		Rows: [][]any{
+			{"alice", int64(1)},
+			{"bob", int64(2)},
+			{"alice", int64(3)},
+		},
+	},
+}
+
+func TestToMap(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, accessQueries)
+	for _, tc := range []struct {
+		dup  Duplicates
+		want map[string]int
+	}{
+		{KeepLast, map[string]int{"alice": 3, "bob": 2}},
+		{KeepFirst, map[string]int{"alice": 1, "bob": 2}},
+	} {
+		got, err := ToMap[string, int](ctx, Fetch(db, "select access"), tc.dup)
+		if err != nil || !maps.Equal(got, tc.want) {
+			t.Errorf("policy %d: got %v, %v, want %v", tc.dup, got, err, tc.want)
+		}
+	}
+	_, err := ToMap[string, int](ctx, Fetch(db, "select access"), RejectDuplicates)
+	var dke *DuplicateKeyError
+	if !errors.As(err, &dke) || dke.Key != "alice" {
+		t.Errorf("got error %v, want a duplicate key error for alice", err)
+	}
+}
+
+func TestToMapStruct(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	byID, err := ToMap[int64, user](ctx, Fetch(db, "select users"), RejectDuplicates)
+	if err != nil || len(byID) != len(users) {
+		t.Fatalf("got %v, %v", byID, err)
+	}
+	for _, u := range users {
+		if byID[u.ID] != u {
+			t.Errorf("got %v for %d, want %v", byID[u.ID], u.ID, u)
+		}
+	}
+	type keyedUser struct {
+		ID    int64
+		Login string `db:"user_login,key"`
+	}
+	byLogin, err := ToMap[string, keyedUser](ctx, Fetch(db, "select users"), RejectDuplicates)
+	if err != nil || byLogin["bob"].ID != 2 {
+		t.Errorf("got %v, %v for a tagged key", byLogin, err)
+	}
+}
@@ -0,0 +1,96 @@
+package dbfetch
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// TxBeginner starts transactions. It is implemented by *sql.DB and *sql.Conn.
+type TxBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+var (
+	_ TxBeginner = (*sql.DB)(nil)
+	_ TxBeginner = (*sql.Conn)(nil)
+)
+
+// TxOptions configure InTx.
+// The zero value runs a transaction with the default isolation level once.
+type TxOptions struct {
+	// Isolation is the isolation level, see sql.TxOptions.
+	Isolation sql.IsolationLevel
+	// ReadOnly starts a read only transaction if the driver supports it.
+	ReadOnly bool
+	// Retries is the number of additional attempts after a failure reported by Retryable.
+	Retries int
+	// Retryable reports errors of a failed attempt worth a retry.
+	// IsSerializationFailure is used if it is nil.
+	Retryable func(error) bool
+}
+
+// IsSerializationFailure reports whether err is caused by a serialization failure or a deadlock,
+// detected by the SQLSTATE codes 40001 and 40P01.
+// The SQLSTATE is retrieved from errors with a method SQLState() string,
+// which are provided by the PostgreSQL drivers lib/pq and pgx.
+func IsSerializationFailure(err error) bool {
+	switch sqlState(err) {
+	case "40001", "40P01":
+		return true
+	}
+	return false
+}
+
+// InTx runs fn in a transaction on db.
+// The transaction is committed if fn returns nil and rolled back otherwise.
+// It is also rolled back if fn panics; the panic is propagated.
+// With opts.Retries, fn is called again in a new transaction
+// as long as fn or the commit fails with an error reported by opts.Retryable.
+// opts can be nil.
+//
+//	err := dbfetch.InTx(ctx, db, nil, func(tx dbfetch.Querier) error {
+//		return dbfetch.Fetch(tx, `select id from jobs where state = 'new' for update`).
+//			Column(ctx, &ids)
+//	})
+func InTx(ctx context.Context, db TxBeginner, opts *TxOptions, fn func(tx Querier) error) error {
+	if opts == nil {
+		opts = &TxOptions{}
+	}
+	retryable := opts.Retryable
+	if retryable == nil {
+		retryable = IsSerializationFailure
+	}
+	for attempt := 0; ; attempt++ {
+		err := runTx(ctx, db, opts, fn)
+		if err == nil || attempt >= opts.Retries || !retryable(err) || ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+// runTx is a single attempt of InTx.
+func runTx(ctx context.Context, db TxBeginner, opts *TxOptions, fn func(tx Querier) error) (err error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: opts.Isolation,
+		ReadOnly:  opts.ReadOnly,
+	})
+	if err != nil {
+		return err
+	}
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		// also runs while panicking
+		if rerr := tx.Rollback(); err != nil && rerr != nil && rerr != sql.ErrTxDone {
+			err = errors.Join(err, rerr)
+		}
+	}()
+	if err = fn(tx); err != nil {
+		return err
+	}
+	committed = true
+	return tx.Commit()
+}
@@ -0,0 +1,142 @@
+package dbfetch
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+// sqlStateError is a driver error with a SQLSTATE code.
+type sqlStateError string
+
+func (e sqlStateError) Error() string {
+	return "sqlstate " + string(e)
+}
+
+func (e sqlStateError) SQLState() string {
+	return string(e)
+}
+
+func TestInTx(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	var got []user
+	err := InTx(ctx, db, nil, func(tx Querier) error {
+		return Fetch(tx, "select users").AllInto(ctx, &got)
+	})
+	if err != nil || !slices.Equal(got, users) {
+		t.Errorf("got %v, %v, want %v", got, err, users)
+	}
+	if calls, want := callLog(db), []string{"begin", "query select users", "commit"}; !slices.Equal(calls, want) {
+		t.Errorf("got calls %v, want %v", calls, want)
+	}
+
+	failed := errors.New("failed")
+	if err := InTx(ctx, db, nil, func(Querier) error { return failed }); err != failed {
+		t.Errorf("got error %v, want %v", err, failed)
+	}
+	if calls, want := callLog(db), []string{"begin", "rollback"}; !slices.Equal(calls, want) {
+		t.Errorf("got calls %v, want %v", calls, want)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("panic was not propagated")
+			}
+		}()
+		InTx(ctx, db, nil, func(Querier) error { panic("boom") })
+	}()
+	if calls, want := callLog(db), []string{"begin", "rollback"}; !slices.Equal(calls, want) {
+		t.Errorf("got calls %v after panic, want %v", calls, want)
+	}
+}
+
+func TestInTxRetry(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	attempts := 0
+	err := InTx(ctx, db, &TxOptions{Retries: 2}, func(Querier) error {
+		attempts++
+		if attempts < 3 {
+			return sqlStateError("40001")
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Errorf("got %d attempts, %v, want 3 and success", attempts, err)
+	}
+	want := []string{"begin", "rollback", "begin", "rollback", "begin", "commit"}
+	if calls := callLog(db); !slices.Equal(calls, want) {
+		t.Errorf("got calls %v, want %v", calls, want)
+	}
+
+	attempts = 0
+	err = InTx(ctx, db, &TxOptions{Retries: 2}, func(Querier) error {
+		attempts++
+		return sqlStateError("23505")
+	})
+	if !errors.Is(err, sqlStateError("23505")) || attempts != 1 {
+		t.Errorf("got %d attempts, %v for an error without retry", attempts, err)
+	}
+}
+
+func TestWithSavepoint(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"SAVEPOINT outer":             {},
+		"SAVEPOINT inner":             {},
+		"ROLLBACK TO SAVEPOINT inner": {},
+		"RELEASE SAVEPOINT outer":     {},
+		"SAVE TRANSACTION step":       {},
+		"ROLLBACK TRANSACTION step":   {},
+		"insert order":                {},
+	})
+	failed := errors.New("failed")
+	err := InTx(ctx, db, nil, func(tx Querier) error {
+		return WithSavepoint(ctx, tx, "outer", func(tx Querier) error {
+			if _, err := Fetch(tx, "insert order").Exec(ctx); err != nil {
+				return err
+			}
+			err := WithSavepoint(ctx, tx, "inner", func(tx Querier) error {
+				return failed
+			})
+			if err != failed {
+				t.Errorf("got %v, want %v", err, failed)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"begin",
+		"exec SAVEPOINT outer",
+		"exec insert order",
+		"exec SAVEPOINT inner",
+		"exec ROLLBACK TO SAVEPOINT inner",
+		"exec RELEASE SAVEPOINT outer",
+		"commit",
+	}
+	if calls := callLog(db); !slices.Equal(calls, want) {
+		t.Errorf("got calls %v, want %v", calls, want)
+	}
+
+	err = InTx(ctx, db, nil, func(tx Querier) error {
+		return SQLServerSavepoints.WithSavepoint(ctx, tx, "step", func(Querier) error { return failed })
+	})
+	want = []string{"begin", "exec SAVE TRANSACTION step", "exec ROLLBACK TRANSACTION step", "rollback"}
+	if calls := callLog(db); err != failed || !slices.Equal(calls, want) {
+		t.Errorf("got calls %v, %v, want %v and %v", calls, err, want, failed)
+	}
+
+	for _, name := range []string{"", "1a", "a b", "a;drop table users"} {
+		if err := WithSavepoint(ctx, db, name, func(Querier) error { return nil }); err == nil {
+			t.Errorf("no error for savepoint name %q", name)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package dbfetch
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"iter"
+	"reflect"
+	"strings"
+)
+
+// scannerType is sql.Scanner; structs implementing it handle their own scanning.
+var scannerType = reflect.TypeFor[sql.Scanner]()
+
+// isStruct reports whether columns are scanned into the fields of t instead of t itself.
+func isStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	// types like time.Time and sql.NullString are scanned as a single column
+	if reflect.PointerTo(t).Implements(scannerType) || t.PkgPath() == "time" {
+		return false
+	}
+	return true
+}
+
+// fieldFor retrieves the index of the field of struct type t matching column.
+// A field matches if its `db` tag equals column or, without a tag, its name equals column ignoring case.
+func fieldFor(t reflect.Type, column string) ([]int, bool) {
+	var byName []int
+	for _, sf := range reflect.VisibleFields(t) {
+		if !sf.IsExported() || sf.Anonymous && isStruct(sf.Type) {
+			continue
+		}
+		tag, hasTag := sf.Tag.Lookup("db")
+		tag, _, _ = strings.Cut(tag, ",")
+		switch {
+		case tag == "-":
+			continue
+		case hasTag && tag != "":
+			if tag == column {
+				return sf.Index, true
+			}
+		case byName == nil && strings.EqualFold(sf.Name, column):
+			byName = sf.Index
+		}
+	}
+	return byName, byName != nil
+}
+
+// scanTargets retrieves pointers into row to scan the columns into.
+// row must be a pointer; columns are scanned into the fields of structs and into row itself otherwise.
+func scanTargets(row reflect.Value, cts []*sql.ColumnType) ([]any, error) {
+	v := row.Elem()
+	if !isStruct(v.Type()) {
+		if len(cts) != 1 {
+			return nil, fmt.Errorf("%d columns can not be scanned into %v", len(cts), v.Type())
+		}
+		return []any{row.Interface()}, nil
+	}
+	dst := make([]any, len(cts))
+	for i, ct := range cts {
+		idx, ok := fieldFor(v.Type(), ct.Name())
+		if !ok {
+			return nil, fmt.Errorf("no field in %v for column %q", v.Type(), ct.Name())
+		}
+		field, err := v.FieldByIndexErr(idx)
+		if err != nil {
+			// embedded through a nil pointer
+			return nil, fmt.Errorf("field for column %q: %w", ct.Name(), err)
+		}
+		dst[i] = field.Addr().Interface()
+	}
+	return dst, nil
+}
+
+// scanInto configures f to scan each row into *row.
+func (f *fetcher) scanInto(row any) *fetcher {
+	return f.InitColumns(func(cts []*sql.ColumnType, err error) error {
+		if err != nil {
+			return err
+		}
+		f.dst, err = scanTargets(reflect.ValueOf(row), cts)
+		return err
+	})
+}
+
+// FetchAll runs query on db and retrieves all rows as a slice of T.
+//
+// If T is a struct, each column is scanned into the exported field with a matching `db:"name"` tag
+// or, for fields without a tag, into the field with the same name ignoring case.
+// Options in the tag after a comma are ignored here, see ToMap.
+// A column without a matching field is an error.
+// Other types, including time.Time and implementations of sql.Scanner, require a single column.
+//
+//	type user struct {
+//		ID    int64
+//		Login string `db:"user_login"`
+//	}
+//	users, err := dbfetch.FetchAll[user](ctx, db, `select id, user_login from users`)
+func FetchAll[T any](ctx context.Context, db Querier, query string, args ...any) ([]T, error) {
+	var (
+		row  T
+		rows []T
+	)
+	err := Fetch(db, query).
+		scanInto(&row).
+		Yield(func() error {
+			rows = append(rows, row)
+			return nil
+		}).
+		Run(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// FetchSeq runs query on db when the returned sequence is iterated and yields every row as T.
+// Rows are mapped to T like in FetchAll.
+// Errors are yielded with the zero value of T and end the sequence.
+// Ending the iteration early closes the rows.
+func FetchSeq[T any](ctx context.Context, db Querier, query string, args ...any) iter.Seq2[T, error] {
+	return RowsAs[T](ctx, Fetch(db, query), args...)
+}
+
+// FetchOne runs query on db and retrieves the first row as T.
+// Rows are mapped to T like in FetchAll.
+// It returns sql.ErrNoRows if the query has no results, further rows are ignored.
+func FetchOne[T any](ctx context.Context, db Querier, query string, args ...any) (T, error) {
+	var row T
+	found := false
+	err := Fetch(db, query).
+		scanInto(&row).
+		Yield(func() error {
+			found = true
+			return ErrStop
+		}).
+		Run(ctx, args...)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if !found {
+		return row, newQueryError(query, args, PhaseRows, ErrNoRows)
+	}
+	return row, nil
+}
@@ -0,0 +1,107 @@
+package dbfetch
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/arnehormann/goof/dbfetch/dbfetchtest"
+)
+
+type user struct {
+	ID    int64
+	Login string `db:"user_login"`
+	Admin bool   `db:"-"`
+}
+
+var usersQuery = map[string]dbfetchtest.Result{
+	"select users": {
+		Columns: dbfetchtest.Columns("id", "user_login"),
+		Rows: [][]any{
+			{int64(1), "alice"},
+			{int64(2), "bob"},
+			{int64(3), "carol"},
+		},
+	},
+	"select none": {
+		Columns: dbfetchtest.Columns("id", "user_login"),
+	},
+	"select admin": {
+		Columns: dbfetchtest.Columns("id", "admin"),
+		Rows:    [][]any{{int64(1), true}},
+	},
+}
+
+// callLog retrieves the calls received by db since the last call of callLog,
+// formatted as "query select users", "exec delete users" or "begin".
+func callLog(db *dbfetchtest.DB) []string {
+	var calls []string
+	for _, c := range db.Calls() {
+		if c.Query == "" {
+			calls = append(calls, c.Kind)
+			continue
+		}
+		calls = append(calls, c.Kind+" "+c.Query)
+	}
+	return calls
+}
+
+var users = []user{{1, "alice", false}, {2, "bob", false}, {3, "carol", false}}
+
+func TestFetchAll(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	got, err := FetchAll[user](ctx, db, "select users")
+	if err != nil || !slices.Equal(got, users) {
+		t.Errorf("got %v, %v, want %v", got, err, users)
+	}
+	ids, err := FetchAll[int64](ctx, dbfetchtest.Open(t, map[string]dbfetchtest.Result{
+		"select id": {Columns: dbfetchtest.Columns("id"), Rows: [][]any{{int64(1)}, {int64(2)}}},
+	}), "select id")
+	if err != nil || !slices.Equal(ids, []int64{1, 2}) {
+		t.Errorf("got %v, %v for a single column", ids, err)
+	}
+	if _, err := FetchAll[user](ctx, db, "select admin"); err == nil {
+		t.Errorf("no error for a column without field")
+	}
+	if _, err := FetchAll[int64](ctx, db, "select users"); err == nil {
+		t.Errorf("no error for two columns and a non-struct type")
+	}
+}
+
+func TestFetchSeq(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	var got []user
+	for u, err := range FetchSeq[user](ctx, db, "select users") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, u)
+		if len(got) == 2 {
+			break
+		}
+	}
+	if !slices.Equal(got, users[:2]) {
+		t.Errorf("got %v, want %v", got, users[:2])
+	}
+	for _, err := range FetchSeq[user](ctx, db, "select unknown") {
+		if err == nil {
+			t.Errorf("no error for an unknown query")
+		}
+	}
+}
+
+func TestFetchOne(t *testing.T) {
+	ctx := context.Background()
+	db := dbfetchtest.Open(t, usersQuery)
+	got, err := FetchOne[user](ctx, db, "select users")
+	if err != nil || got != users[0] {
+		t.Errorf("got %v, %v, want %v", got, err, users[0])
+	}
+	if _, err := FetchOne[user](ctx, db, "select none"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("got error %v, want sql.ErrNoRows", err)
+	}
+}
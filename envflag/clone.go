@@ -0,0 +1,81 @@
+package envflag
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+)
+
+// snapshot copies the value of field so it can be restored later.
+// Pointers are copied with a copy of the value they point to,
+// e.g. for pointers to structs implementing Value.
+func snapshot(field reflect.Value) reflect.Value {
+	c := reflect.New(field.Type()).Elem()
+	if field.Kind() == reflect.Ptr && !field.IsNil() {
+		p := reflect.New(field.Type().Elem())
+		p.Elem().Set(field.Elem())
+		c.Set(p)
+		return c
+	}
+	c.Set(field)
+	return c
+}
+
+// restore sets field to a value copied by snapshot.
+// The pointer in field is kept; it may be referenced by a flag.
+func restore(field, snap reflect.Value) {
+	if field.Kind() == reflect.Ptr && !field.IsNil() && !snap.IsNil() {
+		field.Elem().Set(snap.Elem())
+		return
+	}
+	field.Set(snap)
+}
+
+func (ps *parameters) Clone(vars ...Vars) Parameters {
+	if len(vars) != len(ps.registered) {
+		panic(fmt.Errorf("clone needs %d struct pointers, got %d", len(ps.registered), len(vars)))
+	}
+	c := ps.Env.WithParameters(ps.name).(*parameters)
+	c.parsers = maps.Clone(ps.parsers)
+	c.resolvers = maps.Clone(ps.resolvers)
+	c.expansion = ps.expansion
+	for i, dst := range vars {
+		src := ps.registered[i]
+		if reflect.TypeOf(dst) != reflect.TypeOf(src) || reflect.ValueOf(dst).IsNil() {
+			panic(fmt.Errorf("clone needs a non-nil %T, got %T", src, dst))
+		}
+		dv := reflect.ValueOf(dst)
+		for dv.Kind() == reflect.Ptr {
+			dv = dv.Elem()
+		}
+		for _, ref := range ps.values {
+			if ref.base == src {
+				dv.FieldByName(ref.name).Set(snapshot(ref.def))
+			}
+		}
+		c.Register(dst)
+	}
+	for _, b := range ps.bindings {
+		if err := c.BindURL(b.urlKey, b.parts); err != nil {
+			// all keys were already checked in the original
+			panic(err)
+		}
+	}
+	return c
+}
+
+func (ps *parameters) Reset() error {
+	errs := &errors{}
+	for _, ref := range ps.values {
+		v := ps.Lookup(ref.arg).Value
+		old := v.String()
+		restore(ref.field, ref.def)
+		ref.explicit = false
+		ref.template = ""
+		errs.add(ref.changed(old, v.String()))
+	}
+	if errs.has() {
+		return errs.get()
+	}
+	return nil
+}
@@ -29,7 +29,7 @@ func (e *errors) get() error {
 	for i, err := range e.errs {
 		msgs[i] = err.Error()
 	}
-	return fmt.Errorf(strings.Join(msgs, "\n"))
+	return fmt.Errorf("%s", strings.Join(msgs, "\n"))
 }
 
 // Parameter describes a configurable part of the application.
@@ -87,6 +87,9 @@ type ParameterValue struct {
 //
 // In addition to the tag based configuration, the field name and type are used and
 // the current value on registration is used as the default value.
+//
+// Fields of types not supported by the flag package must implement Value
+// or have a Parser registered for their type.
 type Vars any
 
 // Value is the interface to the dynamic value stored in a flag. (The default value is represented as a string.)
@@ -196,6 +199,12 @@ type Parameters interface {
 	// The current values of each field are used as default values.
 	Register(vars Vars)
 
+	// RegisterParser registers a Parser for fields of type t in structs passed to Register.
+	// It takes precedence over parsers registered with the package level RegisterParser.
+	//
+	// It must be called before Register and panics if Parse or Format is nil.
+	RegisterParser(t reflect.Type, p Parser)
+
 	// Keys retrieves a slice of parameter keys for all managed parameters.
 	Keys() []string
 
@@ -232,13 +241,93 @@ type Parameters interface {
 	// Explore retrieves a slice of all managed parameters with additional information.
 	// Use Explore as the central source to generate documentation.
 	Explore() []Parameter
+
+	// Get retrieves the current value of the parameter identified by the given key.
+	// It uses flag.Getter if the parameter Value implements it and returns the Value
+	// itself otherwise.
+	// The boolean result reports whether the key is managed.
+	Get(key string) (any, bool)
+
+	// GetString retrieves the value of the string parameter identified by the given key.
+	// The boolean result is false if the key is unknown or the value is not a string.
+	GetString(key string) (string, bool)
+
+	// GetInt retrieves the value of the int parameter identified by the given key.
+	// The boolean result is false if the key is unknown or the value is not an int.
+	GetInt(key string) (int, bool)
+
+	// GetDuration retrieves the value of the time.Duration parameter identified by the given key.
+	// The boolean result is false if the key is unknown or the value is not a time.Duration.
+	GetDuration(key string) (time.Duration, bool)
+
+	// OnSet registers a callback invoked whenever the value of the parameter identified
+	// by the given key changes, no matter whether it was set by SetValues or Parse.
+	// The callback receives the old and new value in string form.
+	// An error returned by it is reported by the call that set the value.
+	//
+	// It returns an error if the key is unknown.
+	OnSet(key string, fn func(old, new string) error) error
+
+	// BindURL decomposes the URL-valued parameter identified by urlKey into the
+	// parameters named in parts whenever it changes.
+	// Parameters set directly by any source take precedence over URL components,
+	// independent of the order in which they are set.
+	//
+	// It returns an error if any of the keys is unknown.
+	BindURL(urlKey string, parts URLParts) error
+
+	// Resolve registers a Resolver for values of the form "scheme:reference".
+	// These values are replaced by the resolved value whenever they are set by
+	// SetValues or Parse, so secrets do not have to be passed directly.
+	// Values using a scheme without a registered Resolver are used as is.
+	//
+	// Example:
+	//
+	//	ps.Resolve("file", envflag.FileResolver)
+	//	ps.Resolve("env", envflag.EnvResolver(os.Getenv))
+	//	// MYAPP_PASSWORD=file:/run/secrets/password
+	//	// MYAPP_TOKEN=env:CI_JOB_TOKEN
+	Resolve(scheme string, r Resolver)
+
+	// Expand enables the expansion of variables in values set by SetValues or Parse.
+	// "${name}" is replaced with the value of the parameter with the key or ARG name,
+	// otherwise with the result of getenv for name.
+	// "$$" is replaced with "$" to allow literal "${".
+	//
+	// Values are expanded after all values from the source are set, so the order of
+	// arguments does not matter. Expansion happens before references are resolved.
+	// Cyclic references are reported as errors.
+	//
+	// Example:
+	//
+	//	myapp --data-dir=/var/lib/myapp --log-file='${data-dir}/app.log'
+	Expand(getenv func(string) string)
+
+	// Clone creates a copy of the parameters bound to the given struct pointers.
+	// They must match the types of the structs passed to Register in the same order
+	// and are set to the default values before they are registered.
+	// Parsers, resolvers, expansion and URL bindings are copied, OnSet callbacks are not.
+	//
+	// It panics if the struct pointers do not match the registered ones.
+	Clone(vars ...Vars) Parameters
+
+	// Reset restores the default values of all parameters.
+	// It returns the errors of OnSet callbacks for changed values.
+	Reset() error
 }
 
 type parameters struct {
 	Env
 	flag.FlagSet
-	name   string
-	values map[string]*reference
+	name      string
+	values    map[string]*reference
+	parsers   map[reflect.Type]Parser
+	resolvers map[string]Resolver
+	expansion *expansion
+	// registered contains all Vars in order of registration
+	registered []Vars
+	// bindings contains all BindURL arguments for Clone
+	bindings []binding
 }
 
 type reference struct {
@@ -248,6 +337,16 @@ type reference struct {
 	arg     string
 	tag     string
 	aliases []string
+	// onSet contains callbacks for value changes
+	onSet []func(old, new string) error
+	// explicit is set once the value was set directly by a source
+	explicit bool
+	// template is the unexpanded value if it contains variables
+	template string
+	// field is the struct field of the parameter
+	field reflect.Value
+	// def is a copy of the default value of field
+	def reflect.Value
 }
 
 func (ps *parameters) Register(vars Vars) {
@@ -262,6 +361,7 @@ func (ps *parameters) Register(vars Vars) {
 	if pt.Kind() != reflect.Struct {
 		panic(fmt.Errorf("%T must be a *struct", vars))
 	}
+	ps.registered = append(ps.registered, vars)
 	errs := &errors{}
 	if pt.Kind() != reflect.Struct {
 		panic(fmt.Errorf("%T must be a *struct", vars))
@@ -271,8 +371,14 @@ func (ps *parameters) Register(vars Vars) {
 		value := pv.Field(i)
 		valueptr := value.Addr().Interface()
 		name, key, desc, tag, rawargs := parseField(&field)
-		var refarg string
-		var aliases []string
+		ref := &reference{
+			base:  vars,
+			ptr:   valueptr,
+			name:  name,
+			tag:   tag,
+			field: value,
+			def:   snapshot(value),
+		}
 		for j, raw := range rawargs {
 			arg := ps.keyToArg(raw)
 			switch val := valueptr.(type) {
@@ -291,10 +397,14 @@ func (ps *parameters) Register(vars Vars) {
 			case *time.Duration:
 				ps.DurationVar(val, arg, *val, desc)
 			default:
+				if parser, ok := lookupParser(ps.parsers, value.Type()); ok {
+					ps.Var(&parsedValue{field: value, parser: parser}, arg, desc)
+					break
+				}
 				paramVal, ok := value.Interface().(flag.Value)
 				if !ok {
 					err := fmt.Errorf(
-						"type error in %T: %q must implement Value",
+						"type error in %T: %q must implement Value or have a registered Parser",
 						vars, name,
 					)
 					errs.add(err)
@@ -302,21 +412,16 @@ func (ps *parameters) Register(vars Vars) {
 				}
 				ps.Var(paramVal, arg, desc)
 			}
+			pflag := ps.Lookup(arg)
+			pflag.Value = &observed{Value: pflag.Value, ps: ps, ref: ref}
 			if j == 0 {
-				refarg = arg
+				ref.arg = arg
 				desc = "-> alias for -" + arg
 			} else {
-				aliases = append(aliases, arg)
+				ref.aliases = append(ref.aliases, arg)
 			}
 		}
-		ps.values[key] = &reference{
-			base:    vars,
-			ptr:     valueptr,
-			name:    name,
-			arg:     refarg,
-			tag:     tag,
-			aliases: aliases,
-		}
+		ps.values[key] = ref
 	}
 	if !errs.has() {
 		return
@@ -326,6 +431,14 @@ func (ps *parameters) Register(vars Vars) {
 	panic(errs.get())
 }
 
+func (ps *parameters) RegisterParser(t reflect.Type, p Parser) {
+	mustBeValid(t, p)
+	if ps.parsers == nil {
+		ps.parsers = make(map[reflect.Type]Parser)
+	}
+	ps.parsers[t] = p
+}
+
 func parseField(field *reflect.StructField) (name, key, desc, tag string, args []string) {
 	name = field.Name
 	paramTag := field.Tag
@@ -378,6 +491,7 @@ func (ps *parameters) SetValues(env func(string) string) error {
 			errs.add(ps.Set(v.arg, val))
 		}
 	}
+	errs.add(ps.expandAll())
 	if errs.has() {
 		return errs.get()
 	}
@@ -389,7 +503,10 @@ func (ps *parameters) Parse(args []string) error {
 	if err == flag.ErrHelp {
 		return nil
 	}
-	return err
+	if err != nil {
+		return err
+	}
+	return ps.expandAll()
 }
 
 func (ps *parameters) ArgRest() []string {
@@ -412,7 +529,7 @@ func (ps *parameters) Explore() []Parameter {
 		p.DefaultValue = pflag.DefValue
 		p.Description = pflag.Usage
 		p.Tag = v.tag
-		if enum, ok := pflag.Value.(Enumerator); ok {
+		if enum, ok := unwrap(pflag.Value).(Enumerator); ok {
 			values := enum.Values()
 			p.Options = make([]ParameterValue, len(values))
 			for i, value := range values {
@@ -425,3 +542,53 @@ func (ps *parameters) Explore() []Parameter {
 	}
 	return params
 }
+
+func (ps *parameters) Get(key string) (any, bool) {
+	v, ok := ps.values[key]
+	if !ok {
+		return nil, false
+	}
+	pflag := ps.Lookup(v.arg)
+	if pflag == nil {
+		return nil, false
+	}
+	if getter, ok := pflag.Value.(flag.Getter); ok {
+		return getter.Get(), true
+	}
+	return pflag.Value, true
+}
+
+func (ps *parameters) GetString(key string) (string, bool) {
+	return get[string](ps, key)
+}
+
+func (ps *parameters) GetInt(key string) (int, bool) {
+	return get[int](ps, key)
+}
+
+func (ps *parameters) GetDuration(key string) (time.Duration, bool) {
+	return get[time.Duration](ps, key)
+}
+
+// get retrieves a parameter value and asserts its type.
+func get[T any](ps *parameters, key string) (T, bool) {
+	var zero T
+	val, ok := ps.Get(key)
+	if !ok {
+		return zero, false
+	}
+	typed, ok := val.(T)
+	if !ok {
+		return zero, false
+	}
+	return typed, true
+}
+
+func (ps *parameters) OnSet(key string, fn func(old, new string) error) error {
+	v, ok := ps.values[key]
+	if !ok {
+		return fmt.Errorf("unknown parameter %q", key)
+	}
+	v.onSet = append(v.onSet, fn)
+	return nil
+}
@@ -0,0 +1,266 @@
+package envflag
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testVars struct {
+	Name    string        `desc:"a name"`
+	Count   int           `args:"n"`
+	Timeout time.Duration `key:"timeout"`
+	Verbose bool
+}
+
+func testParameters(t *testing.T, vars *testVars) Parameters {
+	t.Helper()
+	ps := Environment("test").WithParameters("test")
+	ps.Register(vars)
+	return ps
+}
+
+func TestGet(t *testing.T) {
+	vars := &testVars{Name: "default", Count: 1}
+	ps := testParameters(t, vars)
+	err := ps.Parse([]string{"-name=changed", "-n=3", "-timeout=2s"})
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if v, ok := ps.GetString("Name"); !ok || v != "changed" {
+		t.Errorf("GetString: expected %q, got %q (%v)", "changed", v, ok)
+	}
+	if v, ok := ps.GetInt("Count"); !ok || v != 3 {
+		t.Errorf("GetInt: expected %d, got %d (%v)", 3, v, ok)
+	}
+	if v, ok := ps.GetDuration("timeout"); !ok || v != 2*time.Second {
+		t.Errorf("GetDuration: expected %v, got %v (%v)", 2*time.Second, v, ok)
+	}
+	if v, ok := ps.Get("Verbose"); !ok || v != false {
+		t.Errorf("Get: expected false, got %v (%v)", v, ok)
+	}
+	if _, ok := ps.GetInt("Name"); ok {
+		t.Errorf("GetInt succeeded for a string parameter")
+	}
+	if _, ok := ps.Get("Missing"); ok {
+		t.Errorf("Get succeeded for an unknown key")
+	}
+}
+
+type level int
+
+type parserVars struct {
+	Level level
+}
+
+func TestRegisterParser(t *testing.T) {
+	levels := []string{"debug", "info", "warn"}
+	ps := Environment("test").WithParameters("test")
+	ps.RegisterParser(reflect.TypeOf(level(0)), Parser{
+		Parse: func(s string) (any, error) {
+			for i, l := range levels {
+				if l == s {
+					return level(i), nil
+				}
+			}
+			return nil, fmt.Errorf("unknown level %q", s)
+		},
+		Format: func(v any) string {
+			return levels[v.(level)]
+		},
+	})
+	vars := &parserVars{Level: 1}
+	ps.Register(vars)
+	if err := ps.Parse([]string{"-level=warn"}); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if vars.Level != 2 {
+		t.Errorf("expected level 2, got %d", vars.Level)
+	}
+	params := ps.Explore()
+	if len(params) != 1 || params[0].DefaultValue != "info" || params[0].Value != "warn" {
+		t.Errorf("unexpected parameter description: %#v", params)
+	}
+	if err := ps.Parse([]string{"-level=trace"}); err == nil {
+		t.Errorf("parse succeeded for an invalid level")
+	}
+}
+
+func TestOnSet(t *testing.T) {
+	vars := &testVars{Name: "default"}
+	ps := testParameters(t, vars)
+	var changes []string
+	err := ps.OnSet("Count", func(old, new string) error {
+		changes = append(changes, old+"->"+new)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("OnSet failed: %v", err)
+	}
+	err = ps.OnSet("Verbose", func(old, new string) error {
+		return fmt.Errorf("rejected %s", new)
+	})
+	if err != nil {
+		t.Fatalf("OnSet failed: %v", err)
+	}
+	if err := ps.OnSet("Missing", nil); err == nil {
+		t.Errorf("OnSet succeeded for an unknown key")
+	}
+	env := map[string]string{"TEST_COUNT": "2"}
+	if err := ps.SetValues(func(k string) string { return env[k] }); err != nil {
+		t.Fatalf("SetValues failed: %v", err)
+	}
+	if err := ps.Parse([]string{"-n=2", "-count=5"}); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if fmt.Sprint(changes) != "[0->2 2->5]" {
+		t.Errorf("unexpected changes: %v", changes)
+	}
+	if err := ps.Parse([]string{"-verbose"}); err == nil {
+		t.Errorf("callback error was not reported")
+	}
+}
+
+type dbVars struct {
+	DatabaseUrl string
+	DbHost      string
+	DbPort      int
+	DbUser      string
+	DbPassword  string
+	DbName      string
+}
+
+func TestBindURL(t *testing.T) {
+	vars := &dbVars{DbHost: "localhost", DbPort: 5432}
+	ps := Environment("test").WithParameters("test")
+	ps.Register(vars)
+	err := ps.BindURL("DatabaseUrl", URLParts{
+		Host:     "DbHost",
+		Port:     "DbPort",
+		User:     "DbUser",
+		Password: "DbPassword",
+		Name:     "DbName",
+	})
+	if err != nil {
+		t.Fatalf("BindURL failed: %v", err)
+	}
+	if err := ps.BindURL("DatabaseUrl", URLParts{Host: "Missing"}); err == nil {
+		t.Errorf("BindURL succeeded for an unknown key")
+	}
+	if err := ps.BindURL("MissingUrl", URLParts{Host: "DbHost"}); err == nil {
+		t.Errorf("BindURL succeeded for an unknown URL key")
+	}
+	if err := ps.BindURL("DatabaseUrl", URLParts{User: "DbName", Name: "DbName"}); err == nil {
+		t.Errorf("BindURL succeeded for two parts with the same key")
+	}
+	if len(ps.(*parameters).bindings) != 1 {
+		t.Errorf("failed calls of BindURL were kept for Clone")
+	}
+	env := map[string]string{
+		"TEST_DB_USER":      "override",
+		"TEST_DATABASE_URL": "postgres://app:s%40cret@db:6543/orders",
+	}
+	if err := ps.SetValues(func(k string) string { return env[k] }); err != nil {
+		t.Fatalf("SetValues failed: %v", err)
+	}
+	if err := ps.Parse([]string{"-db-name=archive"}); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	expected := dbVars{
+		DatabaseUrl: "postgres://app:s%40cret@db:6543/orders",
+		DbHost:      "db",
+		DbPort:      6543,
+		DbUser:      "override",
+		DbPassword:  "s@cret",
+		DbName:      "archive",
+	}
+	if *vars != expected {
+		t.Errorf("expected %#v, got %#v", expected, *vars)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secret, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	vars := &testVars{}
+	ps := testParameters(t, vars)
+	ps.Resolve("file", FileResolver)
+	ps.Resolve("env", EnvResolver(func(k string) string {
+		return map[string]string{"OTHER": "3"}[k]
+	}))
+	if err := ps.Parse([]string{"-name=file:" + secret, "-n=env:OTHER"}); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if vars.Name != "from-file" || vars.Count != 3 {
+		t.Errorf("references were not resolved: %#v", vars)
+	}
+	if err := ps.Parse([]string{"-name=unknown:value"}); err != nil || vars.Name != "unknown:value" {
+		t.Errorf("value without resolver was changed: %q (%v)", vars.Name, err)
+	}
+	if err := ps.Parse([]string{"-name=env:MISSING"}); err == nil {
+		t.Errorf("unresolvable reference was accepted")
+	}
+}
+
+type pathVars struct {
+	DataDir string
+	LogFile string
+	Cycle1  string
+	Cycle2  string
+}
+
+func TestExpand(t *testing.T) {
+	vars := &pathVars{DataDir: "/tmp"}
+	ps := Environment("test").WithParameters("test")
+	ps.Register(vars)
+	ps.Expand(func(k string) string {
+		return map[string]string{"HOME": "/home/test"}[k]
+	})
+	err := ps.Parse([]string{
+		"-log-file=${data-dir}/app.log $${escaped} $$ $HOME",
+		"-data-dir=${HOME}/data",
+	})
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	expected := "/home/test/data/app.log ${escaped} $ $HOME"
+	if vars.LogFile != expected {
+		t.Errorf("expected %q, got %q", expected, vars.LogFile)
+	}
+	if err := ps.Parse([]string{"-log-file=$${literal}"}); err != nil || vars.LogFile != "${literal}" {
+		t.Errorf("expected %q, got %q (%v)", "${literal}", vars.LogFile, err)
+	}
+	if err := ps.Parse([]string{"-cycle1=${Cycle2}", "-cycle2=x${cycle1}"}); err == nil {
+		t.Errorf("cyclic expansion was accepted")
+	}
+}
+
+func TestCloneAndReset(t *testing.T) {
+	vars := &testVars{Name: "default", Count: 1}
+	ps := testParameters(t, vars)
+	if err := ps.Parse([]string{"-name=changed", "-n=2"}); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	cloned := &testVars{Name: "ignored"}
+	clone := ps.Clone(cloned)
+	if *cloned != (testVars{Name: "default", Count: 1}) {
+		t.Errorf("clone does not use defaults: %#v", cloned)
+	}
+	if err := clone.Parse([]string{"-n=3"}); err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if vars.Count != 2 || cloned.Count != 3 {
+		t.Errorf("clone is not independent: %d, %d", vars.Count, cloned.Count)
+	}
+	if err := ps.Reset(); err != nil {
+		t.Fatalf("reset failed: %v", err)
+	}
+	if *vars != (testVars{Name: "default", Count: 1}) {
+		t.Errorf("reset did not restore defaults: %#v", vars)
+	}
+}
@@ -0,0 +1,115 @@
+package envflag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// expansion contains the state for the expansion of values referencing other values.
+type expansion struct {
+	getenv func(string) string
+}
+
+func (ps *parameters) Expand(getenv func(string) string) {
+	if getenv == nil {
+		getenv = func(string) string { return "" }
+	}
+	ps.expansion = &expansion{getenv: getenv}
+}
+
+// lookupRef retrieves a parameter reference by its key or one of its args.
+func (ps *parameters) lookupRef(name string) (*reference, bool) {
+	if ref, ok := ps.values[name]; ok {
+		return ref, true
+	}
+	if pflag := ps.Lookup(name); pflag != nil {
+		if o, ok := pflag.Value.(*observed); ok {
+			return o.ref, true
+		}
+	}
+	return nil, false
+}
+
+// expandAll sets all parameters with templates to their expanded values.
+func (ps *parameters) expandAll() error {
+	if ps.expansion == nil {
+		return nil
+	}
+	errs := &errors{}
+	for _, ref := range ps.values {
+		if ref.template == "" {
+			continue
+		}
+		val, err := ps.expandRef(ref, nil)
+		if err != nil {
+			errs.add(err)
+			continue
+		}
+		val, err = ps.resolve(val)
+		if err != nil {
+			errs.add(err)
+			continue
+		}
+		errs.add(ps.Lookup(ref.arg).Value.(*observed).set(val, true))
+	}
+	if errs.has() {
+		return errs.get()
+	}
+	return nil
+}
+
+// expandRef retrieves the expanded value of a parameter.
+// visiting contains all parameters currently being expanded to detect cycles.
+func (ps *parameters) expandRef(ref *reference, visiting []*reference) (string, error) {
+	if ref.template == "" {
+		return ps.Lookup(ref.arg).Value.String(), nil
+	}
+	for _, v := range visiting {
+		if v == ref {
+			return "", fmt.Errorf("cycle in expansion of -%s", ref.arg)
+		}
+	}
+	visiting = append(visiting, ref)
+	return ps.expand(ref.template, visiting)
+}
+
+// expand replaces "${name}" in s with the value of the parameter with the key
+// or arg name or the environment variable name.
+// "$$" is replaced with "$", so "$${name}" is not expanded.
+func (ps *parameters) expand(s string, visiting []*reference) (string, error) {
+	var sb strings.Builder
+	for {
+		i := strings.IndexByte(s, '$')
+		if i < 0 || i == len(s)-1 {
+			sb.WriteString(s)
+			return sb.String(), nil
+		}
+		sb.WriteString(s[:i])
+		switch s[i+1] {
+		case '$':
+			sb.WriteByte('$')
+			s = s[i+2:]
+			continue
+		case '{':
+		default:
+			sb.WriteByte('$')
+			s = s[i+1:]
+			continue
+		}
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated variable in %q", s)
+		}
+		name := s[i+2 : i+end]
+		s = s[i+end+1:]
+		if ref, ok := ps.lookupRef(name); ok {
+			val, err := ps.expandRef(ref, visiting)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(val)
+			continue
+		}
+		sb.WriteString(ps.expansion.getenv(name))
+	}
+}
@@ -0,0 +1,84 @@
+package envflag
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Parser converts between the string form of a parameter and values of a type.
+// It enables parameters of types not implementing Value.
+type Parser struct {
+	// Parse converts the string form to a value assignable to the registered type.
+	Parse func(string) (any, error)
+	// Format converts a value of the registered type to its string form.
+	Format func(any) string
+}
+
+var (
+	parsersMu sync.RWMutex
+	parsers   = make(map[reflect.Type]Parser)
+)
+
+// RegisterParser registers a Parser for all struct fields of type t.
+// Parsers registered with Parameters.RegisterParser take precedence.
+//
+// It panics if Parse or Format is nil.
+func RegisterParser(t reflect.Type, p Parser) {
+	mustBeValid(t, p)
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+	parsers[t] = p
+}
+
+func mustBeValid(t reflect.Type, p Parser) {
+	if t == nil || p.Parse == nil || p.Format == nil {
+		panic(fmt.Errorf("invalid parser for type %v", t))
+	}
+}
+
+// lookupParser retrieves the Parser for type t from local or the package registry.
+func lookupParser(local map[reflect.Type]Parser, t reflect.Type) (Parser, bool) {
+	if p, ok := local[t]; ok {
+		return p, true
+	}
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+	p, ok := parsers[t]
+	return p, ok
+}
+
+// parsedValue is a Value for a struct field that uses a Parser.
+type parsedValue struct {
+	field  reflect.Value
+	parser Parser
+}
+
+func (v *parsedValue) String() string {
+	if v == nil || !v.field.IsValid() {
+		// the flag package calls String on zero values
+		return ""
+	}
+	return v.parser.Format(v.field.Interface())
+}
+
+func (v *parsedValue) Set(s string) error {
+	val, err := v.parser.Parse(s)
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(val)
+	if !rv.IsValid() {
+		v.field.SetZero()
+		return nil
+	}
+	if !rv.Type().AssignableTo(v.field.Type()) {
+		return fmt.Errorf("parser returned %T, expected %v", val, v.field.Type())
+	}
+	v.field.Set(rv)
+	return nil
+}
+
+func (v *parsedValue) Get() any {
+	return v.field.Interface()
+}
@@ -0,0 +1,72 @@
+package envflag
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Resolver retrieves the value a reference points to.
+// A reference is the part of a value following the scheme, e.g. "/run/secrets/x"
+// for "file:/run/secrets/x".
+type Resolver interface {
+	Resolve(ref string) (string, error)
+}
+
+// ResolverFunc is a function usable as a Resolver.
+type ResolverFunc func(ref string) (string, error)
+
+func (f ResolverFunc) Resolve(ref string) (string, error) {
+	return f(ref)
+}
+
+// FileResolver resolves references by reading the file they name.
+// Trailing line breaks are removed from the file content.
+var FileResolver Resolver = ResolverFunc(func(ref string) (string, error) {
+	data, err := os.ReadFile(ref)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+})
+
+// EnvResolver creates a Resolver retrieving references as variables with getenv.
+// It fails for unset or empty variables.
+//
+// To resolve references from environment variables, the argument should be
+//
+//	os.Getenv
+func EnvResolver(getenv func(string) string) Resolver {
+	return ResolverFunc(func(ref string) (string, error) {
+		v := getenv(ref)
+		if v == "" {
+			return "", fmt.Errorf("environment variable %q is not set", ref)
+		}
+		return v, nil
+	})
+}
+
+func (ps *parameters) Resolve(scheme string, r Resolver) {
+	if ps.resolvers == nil {
+		ps.resolvers = make(map[string]Resolver)
+	}
+	ps.resolvers[scheme] = r
+}
+
+// resolve replaces a reference with the value it points to.
+// Values without a scheme with a registered Resolver are returned unchanged.
+func (ps *parameters) resolve(val string) (string, error) {
+	scheme, ref, ok := strings.Cut(val, ":")
+	if !ok {
+		return val, nil
+	}
+	r, ok := ps.resolvers[scheme]
+	if !ok {
+		return val, nil
+	}
+	resolved, err := r.Resolve(ref)
+	if err != nil {
+		return "", fmt.Errorf("could not resolve %q: %w", val, err)
+	}
+	return resolved, nil
+}
@@ -0,0 +1,100 @@
+package envflag
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// URLParts contains the keys of parameters set from the components of a URL.
+// Empty keys are ignored.
+//
+// Example for a DATABASE_URL with the value "postgres://app:secret@db:5432/orders":
+//
+//	URLParts{Host: "DBHost", Port: "DBPort", User: "DBUser", Password: "DBPassword", Name: "DBName"}
+//
+// sets DBHost to "db", DBPort to "5432", DBUser to "app", DBPassword to "secret" and DBName to "orders".
+type URLParts struct {
+	// Scheme receives the URL scheme, e.g. "postgres".
+	Scheme string
+	// Host receives the host name without port.
+	Host string
+	// Port receives the port.
+	Port string
+	// User receives the user name.
+	User string
+	// Password receives the password.
+	Password string
+	// Name receives the path without leading "/", e.g. the database name.
+	Name string
+}
+
+// binding contains the arguments of a call to BindURL.
+type binding struct {
+	urlKey string
+	parts  URLParts
+}
+
+func (ps *parameters) BindURL(urlKey string, parts URLParts) error {
+	if _, ok := ps.values[urlKey]; !ok {
+		return fmt.Errorf("unknown parameter %q", urlKey)
+	}
+	targets := make(map[string]func(*url.URL) string)
+	for _, t := range []struct {
+		key  string
+		part func(*url.URL) string
+	}{
+		{parts.Scheme, func(u *url.URL) string { return u.Scheme }},
+		{parts.Host, func(u *url.URL) string { return u.Hostname() }},
+		{parts.Port, func(u *url.URL) string { return u.Port() }},
+		{parts.User, func(u *url.URL) string { return u.User.Username() }},
+		{parts.Password, func(u *url.URL) string {
+			p, _ := u.User.Password()
+			return p
+		}},
+		{parts.Name, func(u *url.URL) string { return strings.TrimPrefix(u.Path, "/") }},
+	} {
+		if t.key == "" {
+			continue
+		}
+		if _, ok := ps.values[t.key]; !ok {
+			return fmt.Errorf("unknown parameter %q", t.key)
+		}
+		if _, dup := targets[t.key]; dup {
+			return fmt.Errorf("parameter %q receives more than one part of %q", t.key, urlKey)
+		}
+		targets[t.key] = t.part
+	}
+	if err := ps.OnSet(urlKey, func(_, raw string) error {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return err
+		}
+		errs := &errors{}
+		for key, part := range targets {
+			v := part(u)
+			if v == "" {
+				continue
+			}
+			errs.add(ps.derive(key, v))
+		}
+		if errs.has() {
+			return errs.get()
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	ps.bindings = append(ps.bindings, binding{urlKey: urlKey, parts: parts})
+	return nil
+}
+
+// derive sets the parameter identified by key unless it was set explicitly.
+func (ps *parameters) derive(key, val string) error {
+	ref := ps.values[key]
+	if ref.explicit {
+		return nil
+	}
+	pflag := ps.Lookup(ref.arg)
+	return pflag.Value.(*observed).set(val, false)
+}
@@ -0,0 +1,95 @@
+package envflag
+
+import (
+	"flag"
+	"strings"
+)
+
+// observed wraps the Value of each flag registered for a parameter.
+// All aliases of a parameter share the reference notified of changes.
+type observed struct {
+	flag.Value
+	ps  *parameters
+	ref *reference
+}
+
+// assert observed keeps the optional interfaces used by the flag package
+var (
+	_ flag.Getter = (*observed)(nil)
+	_ interface {
+		IsBoolFlag() bool
+	} = (*observed)(nil)
+)
+
+// unwrap retrieves the Value wrapped by observed.
+func unwrap(v flag.Value) flag.Value {
+	if w, ok := v.(*observed); ok {
+		return w.Value
+	}
+	return v
+}
+
+func (v *observed) String() string {
+	if v == nil || v.Value == nil {
+		// the flag package calls String on zero values
+		return ""
+	}
+	return v.Value.String()
+}
+
+func (v *observed) Set(s string) error {
+	if v.ps.expansion != nil {
+		if strings.Contains(s, "$") {
+			// expanded by expandAll when all sources are processed, also to unescape "$$"
+			v.ref.template = s
+			v.ref.explicit = true
+			return nil
+		}
+		v.ref.template = ""
+	}
+	s, err := v.ps.resolve(s)
+	if err != nil {
+		return err
+	}
+	return v.set(s, true)
+}
+
+// set sets the value; explicit marks it as configured directly
+// instead of being derived from another parameter.
+func (v *observed) set(s string, explicit bool) error {
+	old := v.Value.String()
+	if err := v.Value.Set(s); err != nil {
+		return err
+	}
+	if explicit {
+		v.ref.explicit = true
+	}
+	return v.ref.changed(old, v.Value.String())
+}
+
+func (v *observed) Get() any {
+	if getter, ok := v.Value.(flag.Getter); ok {
+		return getter.Get()
+	}
+	return v.Value
+}
+
+func (v *observed) IsBoolFlag() bool {
+	bf, ok := v.Value.(interface {
+		IsBoolFlag() bool
+	})
+	return ok && bf.IsBoolFlag()
+}
+
+// changed calls all registered callbacks if the value changed.
+func (r *reference) changed(old, new string) error {
+	if old == new {
+		return nil
+	}
+	for _, fn := range r.onSet {
+		if err := fn(old, new); err != nil {
+			return err
+		}
+	}
+	return nil
+}
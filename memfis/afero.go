@@ -0,0 +1,265 @@
+package memfis
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// AferoFs adapts a file system to the method set of afero.Fs (github.com/spf13/afero)
+// without a dependency of memfis on afero.
+// Write operations fail unless the file system is a MemWriteFS.
+//
+// Methods returning files use AferoFile instead of afero.File, which has the same method set.
+// The module github.com/arnehormann/goof/memfis/aferofs completes the adapter:
+//
+//	var fs afero.Fs = aferofs.New(fsys)
+type AferoFs struct {
+	fsys fs.FS
+}
+
+// AferoFile has the method set of afero.File.
+type AferoFile interface {
+	io.Closer
+	io.Reader
+	io.ReaderAt
+	io.Seeker
+	io.Writer
+	io.WriterAt
+	Name() string
+	Readdir(count int) ([]os.FileInfo, error)
+	Readdirnames(n int) ([]string, error)
+	Stat() (os.FileInfo, error)
+	Sync() error
+	Truncate(size int64) error
+	WriteString(s string) (int, error)
+}
+
+// NewAferoFs creates an AferoFs for fsys.
+func NewAferoFs(fsys fs.FS) *AferoFs {
+	return &AferoFs{fsys: fsys}
+}
+
+// aferoPath converts an operating system style path to an io/fs path.
+// Paths are relative to the root of the file system, even if they start with "/".
+func aferoPath(name string) string {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+// writable retrieves the MemWriteFS or an error for read only file systems.
+func (a *AferoFs) writable(op, name string) (MemWriteFS, error) {
+	if w, ok := a.fsys.(MemWriteFS); ok {
+		return w, nil
+	}
+	return nil, fsPathError(op, name, syscall.EROFS)
+}
+
+func (a *AferoFs) Name() string {
+	return "memfis"
+}
+
+func (a *AferoFs) Create(name string) (AferoFile, error) {
+	return a.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o666)
+}
+
+func (a *AferoFs) Open(name string) (AferoFile, error) {
+	f, err := a.fsys.Open(aferoPath(name))
+	if err != nil {
+		return nil, err
+	}
+	return &aferoFile{File: f, name: name}, nil
+}
+
+func (a *AferoFs) OpenFile(name string, flag int, perm os.FileMode) (AferoFile, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) == 0 {
+		return a.Open(name)
+	}
+	w, err := a.writable("open", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := w.OpenFile(aferoPath(name), flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &aferoFile{File: f, name: name}, nil
+}
+
+func (a *AferoFs) Mkdir(name string, perm os.FileMode) error {
+	w, err := a.writable("mkdir", name)
+	if err != nil {
+		return err
+	}
+	return w.Mkdir(aferoPath(name), perm)
+}
+
+func (a *AferoFs) MkdirAll(name string, perm os.FileMode) error {
+	w, err := a.writable("mkdir", name)
+	if err != nil {
+		return err
+	}
+	return w.MkdirAll(aferoPath(name), perm)
+}
+
+func (a *AferoFs) Remove(name string) error {
+	w, err := a.writable("remove", name)
+	if err != nil {
+		return err
+	}
+	return w.Remove(aferoPath(name))
+}
+
+// RemoveAll removes name and everything it contains.
+// Like os.RemoveAll, it succeeds if name does not exist.
+func (a *AferoFs) RemoveAll(name string) error {
+	w, err := a.writable("removeall", name)
+	if err != nil {
+		return err
+	}
+	var names []string
+	err = fs.WalkDir(w, aferoPath(name), func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		names = append(names, name)
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// remove the contents of directories first
+	for i := len(names) - 1; i >= 0; i-- {
+		if err := w.Remove(names[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *AferoFs) Rename(oldname, newname string) error {
+	w, err := a.writable("rename", oldname)
+	if err != nil {
+		return err
+	}
+	return w.Rename(aferoPath(oldname), aferoPath(newname))
+}
+
+func (a *AferoFs) Stat(name string) (os.FileInfo, error) {
+	return fs.Stat(a.fsys, aferoPath(name))
+}
+
+// Chmod is not supported.
+func (a *AferoFs) Chmod(name string, mode os.FileMode) error {
+	return fsPathError("chmod", name, errors.ErrUnsupported)
+}
+
+// Chown is not supported.
+func (a *AferoFs) Chown(name string, uid, gid int) error {
+	return fsPathError("chown", name, errors.ErrUnsupported)
+}
+
+// Chtimes is not supported.
+func (a *AferoFs) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	return fsPathError("chtimes", name, errors.ErrUnsupported)
+}
+
+// aferoFile is the AferoFile of AferoFs.
+type aferoFile struct {
+	fs.File
+	name string
+}
+
+var _ AferoFile = (*aferoFile)(nil)
+
+func (f *aferoFile) Name() string {
+	return f.name
+}
+
+func (f *aferoFile) ReadAt(p []byte, off int64) (int, error) {
+	if r, ok := f.File.(io.ReaderAt); ok {
+		return r.ReadAt(p, off)
+	}
+	return 0, fsPathError("read", f.name, errors.ErrUnsupported)
+}
+
+func (f *aferoFile) Seek(offset int64, whence int) (int64, error) {
+	if s, ok := f.File.(io.Seeker); ok {
+		return s.Seek(offset, whence)
+	}
+	return 0, fsPathError("seek", f.name, errors.ErrUnsupported)
+}
+
+func (f *aferoFile) Write(p []byte) (int, error) {
+	if w, ok := f.File.(io.Writer); ok {
+		return w.Write(p)
+	}
+	return 0, fsPathError("write", f.name, syscall.EBADF)
+}
+
+func (f *aferoFile) WriteAt(p []byte, off int64) (int, error) {
+	if w, ok := f.File.(io.WriterAt); ok {
+		return w.WriteAt(p, off)
+	}
+	return 0, fsPathError("write", f.name, errors.ErrUnsupported)
+}
+
+func (f *aferoFile) WriteString(s string) (int, error) {
+	return f.Write([]byte(s))
+}
+
+func (f *aferoFile) Readdir(count int) ([]os.FileInfo, error) {
+	d, ok := f.File.(fs.ReadDirFile)
+	if !ok {
+		return nil, fsPathError("readdir", f.name, syscall.ENOTDIR)
+	}
+	entries, err := d.ReadDir(count)
+	infos := make([]os.FileInfo, 0, len(entries))
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			return infos, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, err
+}
+
+func (f *aferoFile) Readdirnames(n int) ([]string, error) {
+	d, ok := f.File.(fs.ReadDirFile)
+	if !ok {
+		return nil, fsPathError("readdir", f.name, syscall.ENOTDIR)
+	}
+	entries, err := d.ReadDir(n)
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name()
+	}
+	return names, err
+}
+
+// Sync makes written data visible in the file system; it does nothing for read only files.
+func (f *aferoFile) Sync() error {
+	if w, ok := f.File.(WriteFile); ok {
+		return w.Sync()
+	}
+	return nil
+}
+
+func (f *aferoFile) Truncate(size int64) error {
+	if w, ok := f.File.(WriteFile); ok {
+		return w.Truncate(size)
+	}
+	return fsPathError("truncate", f.name, syscall.EBADF)
+}
@@ -0,0 +1,76 @@
+package memfis
+
+import (
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"syscall"
+	"testing"
+)
+
+func TestAferoFs(t *testing.T) {
+	fsys, err := FromMap(map[string]string{
+		"a/b": "Hello",
+		"a/c": "Hi",
+	})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	ro := NewAferoFs(fsys)
+	f, err := ro.Open("/a/b")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil || string(data) != "Hello" {
+		t.Errorf("expected Hello, got %q (%v)", data, err)
+	}
+	if _, err := f.WriteString("!"); err == nil {
+		t.Errorf("wrote to a read only file")
+	}
+	f.Close()
+	d, err := ro.Open("a")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	names, err := d.Readdirnames(-1)
+	if err != nil || !slices.Equal(names, []string{"b", "c"}) {
+		t.Errorf("unexpected names %q (%v)", names, err)
+	}
+	if _, err := ro.Create("new"); !errors.Is(err, syscall.EROFS) {
+		t.Errorf("expected read only error, got %v", err)
+	}
+	if err := ro.RemoveAll("a"); !errors.Is(err, syscall.EROFS) {
+		t.Errorf("expected read only error, got %v", err)
+	}
+
+	w, err := MakeMemWriteFS()
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	rw := NewAferoFs(w)
+	if err := rw.MkdirAll("/x/y", 0o755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	f, err = rw.OpenFile("x/y/z", os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	if _, err := f.WriteString("content!"); err != nil {
+		t.Fatalf("WriteString failed: %v", err)
+	}
+	if err := f.Truncate(7); err != nil {
+		t.Fatalf("Truncate failed: %v", err)
+	}
+	f.Close()
+	if info, err := rw.Stat("x/y/z"); err != nil || info.Size() != 7 {
+		t.Errorf("unexpected file info %v (%v)", info, err)
+	}
+	if err := rw.RemoveAll("x"); err != nil {
+		t.Fatalf("RemoveAll failed: %v", err)
+	}
+	if _, err := rw.Stat("x/y/z"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected removed file, got %v", err)
+	}
+}
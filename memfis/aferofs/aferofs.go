@@ -0,0 +1,44 @@
+// Package aferofs exposes memfis file systems and all other fs.FS as afero.Fs.
+// It is a separate module to keep afero out of the dependencies of memfis.
+//
+//	snapshot, err := memfis.FromFS(os.DirFS("testdata"), ".")
+//	if err != nil {
+//		return err
+//	}
+//	var fsys afero.Fs = aferofs.New(snapshot)
+//
+// Write operations fail with syscall.EROFS unless the file system is a memfis.MemWriteFS.
+// Chmod, Chown and Chtimes are not supported.
+package aferofs
+
+import (
+	"io/fs"
+	"os"
+
+	"github.com/arnehormann/goof/memfis"
+	"github.com/spf13/afero"
+)
+
+// New creates an afero.Fs for fsys, see memfis.AferoFs.
+func New(fsys fs.FS) afero.Fs {
+	return aferoFs{memfis.NewAferoFs(fsys)}
+}
+
+// aferoFs returns the files of memfis.AferoFs as afero.File.
+type aferoFs struct {
+	*memfis.AferoFs
+}
+
+var _ afero.Fs = aferoFs{}
+
+func (a aferoFs) Create(name string) (afero.File, error) {
+	return a.AferoFs.Create(name)
+}
+
+func (a aferoFs) Open(name string) (afero.File, error) {
+	return a.AferoFs.Open(name)
+}
+
+func (a aferoFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	return a.AferoFs.OpenFile(name, flag, perm)
+}
@@ -0,0 +1,65 @@
+package aferofs
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/arnehormann/goof/memfis"
+	"github.com/spf13/afero"
+)
+
+func TestReadOnly(t *testing.T) {
+	snapshot, err := memfis.FromMap(map[string]string{
+		"a/b": "Hello",
+		"a/c": "Hi",
+	})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	fsys := New(snapshot)
+	data, err := afero.ReadFile(fsys, "/a/b")
+	if err != nil || string(data) != "Hello" {
+		t.Errorf("expected Hello, got %q (%v)", data, err)
+	}
+	var names []string
+	err = afero.Walk(fsys, "a", func(name string, info os.FileInfo, err error) error {
+		names = append(names, name)
+		return err
+	})
+	if err != nil || len(names) != 3 {
+		t.Errorf("unexpected walk %q (%v)", names, err)
+	}
+	if err := afero.WriteFile(fsys, "new", nil, 0o644); !errors.Is(err, syscall.EROFS) {
+		t.Errorf("expected read only error, got %v", err)
+	}
+}
+
+func TestWritable(t *testing.T) {
+	w, err := memfis.MakeMemWriteFS()
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	fsys := New(w)
+	if err := fsys.MkdirAll("x/y", 0o755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	if err := afero.WriteFile(fsys, "x/y/z", []byte("content!"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	f, err := fsys.OpenFile("x/y/z", os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	if err := f.Truncate(7); err != nil {
+		t.Fatalf("Truncate failed: %v", err)
+	}
+	f.Close()
+	if data, err := w.ReadFile("x/y/z"); err != nil || string(data) != "content" {
+		t.Errorf("expected content, got %q (%v)", data, err)
+	}
+	if ok, err := afero.Exists(fsys, "x/y/z"); !ok || err != nil {
+		t.Errorf("file does not exist (%v)", err)
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/arnehormann/goof/memfis/aferofs
+
+go 1.25
+
+require (
+	github.com/arnehormann/goof v0.0.0
+	github.com/spf13/afero v1.11.0
+)
+
+require golang.org/x/text v0.14.0 // indirect
+
+replace github.com/arnehormann/goof => ../..
@@ -0,0 +1,144 @@
+package memfis
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"io"
+	"io/fs"
+	"time"
+)
+
+// ArchiveOptions configure the archive export of a MemFS.
+// The zero value is usable, it is used by WriteZip and WriteTar.
+type ArchiveOptions struct {
+	// ModTime is the modification time of all entries.
+	// If it is zero, the modification time of the entry is used or the Unix epoch if that is zero, too.
+	ModTime time.Time
+}
+
+// WriteZip writes all files and directories of fsys to w as a zip archive.
+// Entries are written in lexical order.
+func WriteZip(w io.Writer, fsys MemFS) error {
+	return ArchiveOptions{}.WriteZip(w, fsys)
+}
+
+// WriteTar writes all files and directories of fsys to w as an uncompressed tar archive.
+// Entries are written in lexical order.
+func WriteTar(w io.Writer, fsys MemFS) error {
+	return ArchiveOptions{}.WriteTar(w, fsys)
+}
+
+// modTime retrieves the modification time for an archive entry.
+func (o ArchiveOptions) modTime(info fs.FileInfo) time.Time {
+	if !o.ModTime.IsZero() {
+		return o.ModTime
+	}
+	if t := info.ModTime(); !t.IsZero() {
+		return t
+	}
+	return time.Unix(0, 0).UTC()
+}
+
+// entries calls fn for all files and directories in fsys except ".".
+func entries(fsys fs.FS, fn func(name string, info fs.FileInfo) error) error {
+	return fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || name == "." {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return fn(name, info)
+	})
+}
+
+// copyFile writes the content of the named file in fsys to w.
+func copyFile(w io.Writer, fsys fs.FS, name string) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// WriteZip writes all files and directories of fsys to w as a zip archive.
+// Entries are written in lexical order.
+func (o ArchiveOptions) WriteZip(w io.Writer, fsys MemFS) error {
+	zw := zip.NewWriter(w)
+	err := entries(fsys, func(name string, info fs.FileInfo) error {
+		h, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		h.Name = name
+		h.Modified = o.modTime(info)
+		if info.IsDir() {
+			h.Name += "/"
+			h.Method = zip.Store
+		} else {
+			h.Method = zip.Deflate
+		}
+		fw, err := zw.CreateHeader(h)
+		if err != nil || info.IsDir() {
+			return err
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			// the content of a symbolic link is its target
+			target, err := fs.ReadLink(fsys, name)
+			if err != nil {
+				return err
+			}
+			_, err = io.WriteString(fw, target)
+			return err
+		}
+		return copyFile(fw, fsys, name)
+	})
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// WriteTar writes all files and directories of fsys to w as an uncompressed tar archive.
+// Entries are written in lexical order.
+// Extended attributes in the *Metadata of files are written as PAX records.
+func (o ArchiveOptions) WriteTar(w io.Writer, fsys MemFS) error {
+	tw := tar.NewWriter(w)
+	err := entries(fsys, func(name string, info fs.FileInfo) error {
+		var target string
+		if info.Mode()&fs.ModeSymlink != 0 {
+			var err error
+			if target, err = fs.ReadLink(fsys, name); err != nil {
+				return err
+			}
+		}
+		h, err := tar.FileInfoHeader(info, target)
+		if err != nil {
+			return err
+		}
+		h.Name = name
+		h.ModTime = o.modTime(info)
+		if info.IsDir() {
+			h.Name += "/"
+		}
+		if md, ok := info.Sys().(*Metadata); ok && md != nil && len(md.Xattrs) > 0 {
+			if h.PAXRecords == nil {
+				h.PAXRecords = make(map[string]string, len(md.Xattrs))
+			}
+			for k, v := range md.Xattrs {
+				h.PAXRecords[paxXattr+k] = v
+			}
+		}
+		if err := tw.WriteHeader(h); err != nil || !info.Mode().IsRegular() {
+			return err
+		}
+		return copyFile(tw, fsys, name)
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
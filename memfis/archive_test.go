@@ -0,0 +1,117 @@
+package memfis
+
+import (
+	"bytes"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	fsys, err := FromMap(map[string]string{
+		"a/b": "Hello",
+		"a/c": "",
+		"d/":  "",
+		"e":   "Hi",
+	})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	names := []string{"a/b", "a/c", "d", "e"}
+	opts := ArchiveOptions{ModTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	var zipped, zipped2 bytes.Buffer
+	if err := opts.WriteZip(&zipped, fsys); err != nil {
+		t.Fatalf("WriteZip failed: %v", err)
+	}
+	if err := opts.WriteZip(&zipped2, fsys); err != nil {
+		t.Fatalf("WriteZip failed: %v", err)
+	}
+	if !bytes.Equal(zipped.Bytes(), zipped2.Bytes()) {
+		t.Errorf("WriteZip is not deterministic")
+	}
+	unzipped, err := FromZip(bytes.NewReader(zipped.Bytes()), int64(zipped.Len()))
+	if err != nil {
+		t.Fatalf("FromZip failed: %v", err)
+	}
+	if err := fstest.TestFS(unzipped, names...); err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+
+	var tarred bytes.Buffer
+	if err := WriteTar(&tarred, fsys); err != nil {
+		t.Fatalf("WriteTar failed: %v", err)
+	}
+	untarred, err := FromTar(&tarred)
+	if err != nil {
+		t.Fatalf("FromTar failed: %v", err)
+	}
+	if err := fstest.TestFS(untarred, names...); err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+	if data, _ := untarred.ReadFile("a/b"); string(data) != "Hello" {
+		t.Errorf("unexpected content %q", data)
+	}
+}
+
+// modedFile is a File with custom permissions.
+type modedFile struct {
+	name string
+	mode fs.FileMode
+}
+
+func (f modedFile) GetName() string {
+	return f.name
+}
+
+func (f modedFile) GetContent() string {
+	return "#!/bin/sh"
+}
+
+func (f modedFile) Mode() fs.FileMode {
+	return f.mode
+}
+
+func TestArchiveModes(t *testing.T) {
+	fsys, err := MakeMemFS(
+		modedFile{name: "bin/run", mode: 0o755},
+		modedFile{name: "ro", mode: 0o444},
+		modedFile{name: "default"},
+	)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	expected := map[string]fs.FileMode{
+		"bin/run": 0o755,
+		"ro":      0o444,
+		"default": 0o640,
+	}
+	check := func(kind string, fsys fs.FS) {
+		t.Helper()
+		for name, mode := range expected {
+			info, err := fs.Stat(fsys, name)
+			if err != nil || info.Mode() != mode {
+				t.Errorf("%s: expected mode %v for %q, got %v (%v)", kind, mode, name, info.Mode(), err)
+			}
+		}
+	}
+	check("memfs", fsys)
+	var zipped, tarred bytes.Buffer
+	if err := WriteZip(&zipped, fsys); err != nil {
+		t.Fatalf("WriteZip failed: %v", err)
+	}
+	unzipped, err := FromZip(bytes.NewReader(zipped.Bytes()), int64(zipped.Len()))
+	if err != nil {
+		t.Fatalf("FromZip failed: %v", err)
+	}
+	check("zip", unzipped)
+	if err := WriteTar(&tarred, fsys); err != nil {
+		t.Fatalf("WriteTar failed: %v", err)
+	}
+	untarred, err := FromTar(&tarred)
+	if err != nil {
+		t.Fatalf("FromTar failed: %v", err)
+	}
+	check("tar", untarred)
+}
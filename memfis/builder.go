@@ -0,0 +1,151 @@
+package memfis
+
+import (
+	"io/fs"
+	"path"
+)
+
+// Builder incrementally collects files and directories for a MemFS.
+// Names are validated when they are added, so errors are reported early.
+// The zero value is an empty Builder ready to use.
+type Builder struct {
+	files map[string]File
+	// dirs contains all directories; true for directories created with MkdirAll
+	dirs map[string]bool
+}
+
+func (b *Builder) init() {
+	if b.files == nil {
+		b.files = make(map[string]File)
+		b.dirs = make(map[string]bool)
+	}
+}
+
+// checkParents reports an error if any parent directory of name is a file.
+func (b *Builder) checkParents(op, name string) error {
+	for i := 0; i < len(name); i++ {
+		if name[i] != pathSeparator {
+			continue
+		}
+		if _, ok := b.files[name[:i]]; ok {
+			return fsPathError(op, name, errParentIsFile)
+		}
+	}
+	return nil
+}
+
+// addParents registers all parent directories of name.
+func (b *Builder) addParents(name string) {
+	for i := len(name) - 1; i >= 0; i-- {
+		if name[i] != pathSeparator {
+			continue
+		}
+		dir := name[:i]
+		if _, ok := b.dirs[dir]; ok {
+			// parents were already added
+			return
+		}
+		b.dirs[dir] = false
+	}
+}
+
+// AddFile adds a file with the given name and content.
+// It fails if name is not a valid io/fs path or is already used.
+func (b *Builder) AddFile(name, content string) error {
+	return b.add(entry{name: name, content: content})
+}
+
+// add adds a File.
+func (b *Builder) add(f File) error {
+	b.init()
+	name := f.GetName()
+	if !validName(name) {
+		return fsPathError("add", name, fs.ErrInvalid)
+	}
+	if _, ok := b.files[name]; ok {
+		return fsPathError("add", name, fs.ErrExist)
+	}
+	if _, ok := b.dirs[name]; ok {
+		return fsPathError("add", name, fs.ErrExist)
+	}
+	if err := b.checkParents("add", name); err != nil {
+		return err
+	}
+	b.files[name] = f
+	b.addParents(name)
+	return nil
+}
+
+// MkdirAll adds a directory and all missing parents.
+// Directories are only kept by Freeze if they are empty.
+func (b *Builder) MkdirAll(name string) error {
+	b.init()
+	if name == "." {
+		return nil
+	}
+	if !validName(name) {
+		return fsPathError("mkdir", name, fs.ErrInvalid)
+	}
+	if _, ok := b.files[name]; ok {
+		return fsPathError("mkdir", name, fs.ErrExist)
+	}
+	if err := b.checkParents("mkdir", name); err != nil {
+		return err
+	}
+	b.dirs[name] = true
+	b.addParents(name)
+	return nil
+}
+
+// AddFS adds all files, directories and symbolic links in fsys with their path prefixed with prefix.
+// It stops at the first error.
+func (b *Builder) AddFS(prefix string, fsys fs.FS) error {
+	if prefix != "." && prefix != "" {
+		if err := b.MkdirAll(prefix); err != nil {
+			return err
+		}
+	}
+	return fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		target := path.Join(prefix, name)
+		if d.IsDir() {
+			return b.MkdirAll(target)
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			link, err := fs.ReadLink(fsys, name)
+			if err != nil {
+				return err
+			}
+			return b.add(MakeSymlink(target, link))
+		}
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		return b.AddFile(target, string(content))
+	})
+}
+
+// Freeze creates a MemFS containing all files and empty directories added so far.
+// The Builder can still be used afterwards, it does not affect the MemFS.
+func (b *Builder) Freeze() (MemFS, error) {
+	files := make([]File, 0, len(b.files))
+	// parents contains all directories with children
+	parents := make(map[string]bool)
+	for name, f := range b.files {
+		files = append(files, f)
+		parents[parent(name)] = true
+	}
+	for dir := range b.dirs {
+		parents[parent(dir)] = true
+	}
+	for dir, explicit := range b.dirs {
+		dir = toDir(dir)
+		if explicit && !parents[dir] {
+			files = append(files, entry{name: dir})
+		}
+	}
+	return MakeMemFS(files...)
+}
@@ -0,0 +1,37 @@
+package memfis
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestBuilder(t *testing.T) {
+	var b Builder
+	check := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	check(b.AddFile("a/b", "Hello"))
+	check(b.MkdirAll("a/c/d"))
+	check(b.MkdirAll("e"))
+	check(b.AddFS("f", fstest.MapFS{
+		"g/h": {Data: []byte("Hi")},
+		"i":   {Data: []byte("Ho")},
+	}))
+	for _, name := range []string{"a/b", "a", "a/b/c", "../x", "/x"} {
+		if err := b.AddFile(name, ""); err == nil {
+			t.Errorf("AddFile succeeded for %q", name)
+		}
+	}
+	if err := b.MkdirAll("a/b/c"); err == nil {
+		t.Errorf("MkdirAll succeeded in a file")
+	}
+	fsys, err := b.Freeze()
+	check(err)
+	err = fstest.TestFS(fsys, "a/b", "a/c/d", "e", "f/g/h", "f/i")
+	if err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+}
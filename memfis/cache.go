@@ -0,0 +1,262 @@
+package memfis
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"io/fs"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// CacheOptions configure a CacheFS.
+// The zero value caches all files forever.
+type CacheOptions struct {
+	// TTL is the duration files are served from the cache before they are read again, zero for no expiry.
+	TTL time.Duration
+	// MaxBytes limits the total size of cached files by evicting the least recently used ones,
+	// zero for no limit. Larger files are never cached.
+	MaxBytes int64
+}
+
+// CacheMetrics are counters of a CacheFS.
+type CacheMetrics struct {
+	// Hits and Misses count accesses of files served from the cache and from the source.
+	Hits, Misses int64
+	// Evictions counts files removed to stay below CacheOptions.MaxBytes.
+	Evictions int64
+	// Files and Bytes are the number and total size of the cached files.
+	Files int
+	Bytes int64
+}
+
+// CacheFS is a file system caching the files of a slow source in memory, see Cache.
+// Its methods are safe for concurrent use.
+type CacheFS struct {
+	src  fs.FS
+	opts CacheOptions
+	// now retrieves the current time, it is replaced in tests
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// lru contains the *cacheEntry values, the most recently used first
+	lru     list.List
+	metrics CacheMetrics
+}
+
+var (
+	_ fs.ReadDirFS  = (*CacheFS)(nil)
+	_ fs.ReadFileFS = (*CacheFS)(nil)
+	_ fs.StatFS     = (*CacheFS)(nil)
+)
+
+// cacheEntry is a file in a CacheFS.
+type cacheEntry struct {
+	name    string
+	data    []byte
+	info    fs.FileInfo
+	expires time.Time
+}
+
+// cachedFile is the File used to read a cacheEntry.
+type cachedFile struct {
+	*cacheEntry
+}
+
+var (
+	_ BytesFile = cachedFile{}
+	_ FileModer = cachedFile{}
+	_ FileSyser = cachedFile{}
+)
+
+func (f cachedFile) GetName() string {
+	return f.name
+}
+
+func (f cachedFile) GetContent() string {
+	return string(f.data)
+}
+
+func (f cachedFile) Bytes() []byte {
+	return f.data
+}
+
+func (f cachedFile) Mode() fs.FileMode {
+	return f.info.Mode().Perm()
+}
+
+func (f cachedFile) Sys() any {
+	return f.info.Sys()
+}
+
+// Cache creates a file system reading each file of src completely on first access and serving it
+// from memory afterwards, e.g. for network file systems, archives or generators.
+// Directories are always read from src.
+func Cache(src fs.FS, opts CacheOptions) *CacheFS {
+	return &CacheFS{
+		src:     src,
+		opts:    opts,
+		now:     time.Now,
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Metrics retrieves the current counters.
+func (c *CacheFS) Metrics() CacheMetrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m := c.metrics
+	m.Files = c.lru.Len()
+	return m
+}
+
+// Invalidate removes the named file from the cache, it is read from the source on next access.
+func (c *CacheFS) Invalidate(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[name]; ok {
+		c.remove(e)
+	}
+}
+
+// remove drops a cached file, the caller must hold c.mu.
+func (c *CacheFS) remove(e *list.Element) {
+	ce := c.lru.Remove(e).(*cacheEntry)
+	delete(c.entries, ce.name)
+	c.metrics.Bytes -= int64(len(ce.data))
+}
+
+// fresh reports if a cached file has not expired.
+func (c *CacheFS) fresh(ce *cacheEntry) bool {
+	return ce.expires.IsZero() || c.now().Before(ce.expires)
+}
+
+// get retrieves a fresh cached file and counts the access.
+func (c *CacheFS) get(name string) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[name]
+	if ok {
+		ce := e.Value.(*cacheEntry)
+		if c.fresh(ce) {
+			c.lru.MoveToFront(e)
+			c.metrics.Hits++
+			return ce
+		}
+		c.remove(e)
+	}
+	c.metrics.Misses++
+	return nil
+}
+
+// put adds a file read from the source and evicts files exceeding MaxBytes.
+func (c *CacheFS) put(ce *cacheEntry) {
+	size := int64(len(ce.data))
+	if c.opts.MaxBytes > 0 && size > c.opts.MaxBytes {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.opts.TTL > 0 {
+		ce.expires = c.now().Add(c.opts.TTL)
+	}
+	if e, ok := c.entries[ce.name]; ok {
+		// read concurrently
+		c.remove(e)
+	}
+	c.entries[ce.name] = c.lru.PushFront(ce)
+	c.metrics.Bytes += size
+	for c.opts.MaxBytes > 0 && c.metrics.Bytes > c.opts.MaxBytes {
+		c.remove(c.lru.Back())
+		c.metrics.Evictions++
+	}
+}
+
+// load retrieves the named file from the cache or the source.
+// It retrieves the opened source file instead if name is a directory.
+func (c *CacheFS) load(op, name string) (*cacheEntry, fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, nil, fsPathError(op, name, fs.ErrInvalid)
+	}
+	if ce := c.get(name); ce != nil {
+		return ce, nil, nil
+	}
+	f, err := c.src.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if info.IsDir() {
+		return nil, f, nil
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	ce := &cacheEntry{name: name, data: data, info: info}
+	c.put(ce)
+	return ce, nil, nil
+}
+
+func (c *CacheFS) Open(name string) (fs.File, error) {
+	ce, dir, err := c.load("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if dir != nil {
+		return dir, nil
+	}
+	return &cacheFile{memFile: makeFile(cachedFile{ce}), info: ce.info}, nil
+}
+
+// cacheFile is an open cached file reporting the FileInfo of the source.
+type cacheFile struct {
+	*memFile
+	info fs.FileInfo
+}
+
+func (f *cacheFile) Stat() (fs.FileInfo, error) {
+	if _, err := f.memFile.Stat(); err != nil {
+		return nil, err
+	}
+	return f.info, nil
+}
+
+func (c *CacheFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, fsPathError("stat", name, fs.ErrInvalid)
+	}
+	c.mu.Lock()
+	e, ok := c.entries[name]
+	c.mu.Unlock()
+	if ok {
+		// not counted, Stat does not read the content
+		if ce := e.Value.(*cacheEntry); c.fresh(ce) {
+			return ce.info, nil
+		}
+	}
+	return fs.Stat(c.src, name)
+}
+
+func (c *CacheFS) ReadFile(name string) ([]byte, error) {
+	ce, dir, err := c.load("readfile", name)
+	if err != nil {
+		return nil, err
+	}
+	if dir != nil {
+		dir.Close()
+		return nil, fsPathError("readfile", name, syscall.EISDIR)
+	}
+	return bytes.Clone(ce.data), nil
+}
+
+func (c *CacheFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(c.src, name)
+}
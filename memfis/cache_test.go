@@ -0,0 +1,63 @@
+package memfis
+
+import (
+	"io"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	src := fstest.MapFS{
+		"a/b":   {Data: []byte("Hello")},
+		"c":     {Data: []byte("World!")},
+		"large": {Data: []byte("0123456789")},
+	}
+	now := time.Unix(0, 0)
+	cache := Cache(src, CacheOptions{TTL: time.Minute, MaxBytes: 11})
+	cache.now = func() time.Time { return now }
+	if err := fstest.TestFS(cache, "a/b", "c", "large"); err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+	cache = Cache(src, CacheOptions{TTL: time.Minute, MaxBytes: 11})
+	cache.now = func() time.Time { return now }
+	read := func(name, want string) {
+		t.Helper()
+		f, err := cache.Open(name)
+		if err != nil {
+			t.Fatalf("Open failed: %v", err)
+		}
+		defer f.Close()
+		data, err := io.ReadAll(f)
+		if err != nil || string(data) != want {
+			t.Errorf("read %q from %s, expected %q (%v)", data, name, want, err)
+		}
+	}
+	expect := func(want CacheMetrics) {
+		t.Helper()
+		if m := cache.Metrics(); m != want {
+			t.Errorf("expected metrics %+v, got %+v", want, m)
+		}
+	}
+	read("a/b", "Hello")
+	read("a/b", "Hello")
+	expect(CacheMetrics{Hits: 1, Misses: 1, Files: 1, Bytes: 5})
+	src["a/b"] = &fstest.MapFile{Data: []byte("Changed")}
+	read("a/b", "Hello")
+	read("c", "World!")
+	expect(CacheMetrics{Hits: 2, Misses: 2, Files: 2, Bytes: 11})
+	read("large", "0123456789")
+	// evicts both other files
+	expect(CacheMetrics{Hits: 2, Misses: 3, Evictions: 2, Files: 1, Bytes: 10})
+	read("a/b", "Changed")
+	expect(CacheMetrics{Hits: 2, Misses: 4, Evictions: 3, Files: 1, Bytes: 7})
+	now = now.Add(time.Minute)
+	src["a/b"] = &fstest.MapFile{Data: []byte("Expired")}
+	read("a/b", "Expired")
+	expect(CacheMetrics{Hits: 2, Misses: 5, Evictions: 3, Files: 1, Bytes: 7})
+	cache.Invalidate("a/b")
+	expect(CacheMetrics{Hits: 2, Misses: 5, Evictions: 3})
+	if _, err := cache.ReadFile("a"); err == nil {
+		t.Errorf("read directory")
+	}
+}
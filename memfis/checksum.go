@@ -0,0 +1,150 @@
+package memfis
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"sync"
+)
+
+// Checksum is the hash of a file content.
+type Checksum struct {
+	// Algorithm is the name of a hash registered with RegisterHash.
+	// "md5", "sha1", "sha256" and "sha512" are always registered.
+	Algorithm string
+	// Sum is the hash of the content.
+	Sum []byte
+}
+
+func (c Checksum) String() string {
+	return c.Algorithm + ":" + hex.EncodeToString(c.Sum)
+}
+
+// FileHasher is a file with a known checksum of its content.
+type FileHasher interface {
+	File
+	Checksum() Checksum
+}
+
+// Metadata is the value of FileInfo.Sys for files without FileSyser.
+type Metadata struct {
+	// Checksum is provided by a FileHasher; it is nil for other files.
+	Checksum *Checksum
+	// Xattrs are the extended attributes provided by an XattrFile; they are nil for other files.
+	Xattrs map[string]string
+}
+
+// metadata retrieves the Metadata of f and reports whether it has any.
+func metadata(f File) (*Metadata, bool) {
+	var md Metadata
+	if h, ok := f.(FileHasher); ok {
+		cs := h.Checksum()
+		md.Checksum = &cs
+	}
+	if x, ok := f.(XattrFile); ok {
+		md.Xattrs = x.Xattrs()
+	}
+	if md.Checksum == nil && md.Xattrs == nil {
+		return nil, false
+	}
+	return &md, true
+}
+
+var (
+	hashesMu sync.RWMutex
+	hashes   = map[string]func() hash.Hash{
+		"md5":    md5.New,
+		"sha1":   sha1.New,
+		"sha256": sha256.New,
+		"sha512": sha512.New,
+	}
+)
+
+// RegisterHash registers a hash for checksums with algorithm.
+//
+// It panics if h is nil.
+func RegisterHash(algorithm string, h func() hash.Hash) {
+	if h == nil {
+		panic("memfis: nil hash for algorithm " + algorithm)
+	}
+	hashesMu.Lock()
+	defer hashesMu.Unlock()
+	hashes[algorithm] = h
+}
+
+var errUnknownAlgorithm = errors.New("unknown hash algorithm")
+
+// ChecksumError reports a file with a content not matching its checksum.
+type ChecksumError struct {
+	Name     string
+	Expected Checksum
+	Actual   Checksum
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s: expected %v, got %v", e.Name, e.Expected, e.Actual)
+}
+
+// Verify recomputes the checksums of all files in fsys providing one in their Metadata.
+// All mismatches are reported as *ChecksumError, joined with other errors.
+func Verify(fsys MemFS) error {
+	var errs []error
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		md, ok := info.Sys().(*Metadata)
+		if !ok || md == nil || md.Checksum == nil {
+			return nil
+		}
+		actual, err := checksum(fsys, name, md.Checksum.Algorithm)
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		if !bytes.Equal(actual.Sum, md.Checksum.Sum) {
+			errs = append(errs, &ChecksumError{
+				Name:     name,
+				Expected: *md.Checksum,
+				Actual:   actual,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// checksum computes the checksum of the named file.
+func checksum(fsys fs.FS, name, algorithm string) (Checksum, error) {
+	hashesMu.RLock()
+	newHash, ok := hashes[algorithm]
+	hashesMu.RUnlock()
+	if !ok {
+		return Checksum{}, fsPathError("verify", name, fmt.Errorf("%w %q", errUnknownAlgorithm, algorithm))
+	}
+	f, err := fsys.Open(name)
+	if err != nil {
+		return Checksum{}, err
+	}
+	defer f.Close()
+	h := newHash()
+	if _, err := io.Copy(h, f); err != nil {
+		return Checksum{}, err
+	}
+	return Checksum{Algorithm: algorithm, Sum: h.Sum(nil)}, nil
+}
@@ -0,0 +1,58 @@
+package memfis
+
+import (
+	"crypto/sha256"
+	"errors"
+	"testing"
+)
+
+// hashedFile is a File with a sha256 checksum.
+type hashedFile struct {
+	entry
+	sum []byte
+}
+
+func (f hashedFile) Checksum() Checksum {
+	return Checksum{Algorithm: "sha256", Sum: f.sum}
+}
+
+func TestVerify(t *testing.T) {
+	sum := sha256.Sum256([]byte("Hello"))
+	fsys, err := MakeMemFS(
+		hashedFile{entry{name: "a/ok", content: "Hello"}, sum[:]},
+		hashedFile{entry{name: "a/bad", content: "Hallo"}, sum[:]},
+		entry{name: "plain", content: "Hi"},
+	)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	info, err := fsys.Stat("a/ok")
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if md, ok := info.Sys().(*Metadata); !ok || md.Checksum == nil || md.Checksum.Algorithm != "sha256" {
+		t.Errorf("expected checksum in metadata, got %#v", info.Sys())
+	}
+	if info, _ := fsys.Stat("plain"); info.Sys() != nil {
+		t.Errorf("unexpected metadata %#v", info.Sys())
+	}
+	err = Verify(fsys)
+	var ce *ChecksumError
+	if !errors.As(err, &ce) || ce.Name != "a/bad" {
+		t.Fatalf("expected checksum error for a/bad, got %v", err)
+	}
+	sub, err := fsys.Sub("a")
+	if err != nil {
+		t.Fatalf("Sub failed: %v", err)
+	}
+	if err := Verify(sub.(MemFS)); err == nil {
+		t.Errorf("Verify did not report the mismatch in a sub file system")
+	}
+	valid, err := MakeMemFS(hashedFile{entry{name: "ok", content: "Hello"}, sum[:]})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	if err := Verify(valid); err != nil {
+		t.Errorf("Verify failed: %v", err)
+	}
+}
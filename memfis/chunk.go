@@ -0,0 +1,175 @@
+package memfis
+
+import (
+	"errors"
+	"io"
+	"sort"
+)
+
+// ReaderAtFile is a file reading its content from an io.ReaderAt, e.g. an os.File or
+// a large file split into chunks. Read, ReadAt and WriteTo of opened files access the
+// requested range only, the content is never held in memory as a whole.
+type ReaderAtFile interface {
+	FileSizer
+	// ReaderAt retrieves the source of the content, it contains Size bytes starting at offset 0.
+	ReaderAt() io.ReaderAt
+}
+
+// readerAtFile is the ReaderAtFile created by MakeReaderAtFile.
+type readerAtFile struct {
+	name string
+	r    io.ReaderAt
+	size int64
+}
+
+var _ ReaderAtFile = readerAtFile{}
+
+// MakeReaderAtFile creates a file with the first size bytes of r as its content.
+func MakeReaderAtFile(name string, r io.ReaderAt, size int64) ReaderAtFile {
+	return readerAtFile{name: name, r: r, size: size}
+}
+
+func (f readerAtFile) GetName() string {
+	return f.name
+}
+
+// GetContent reads the content, it is empty if reading fails.
+func (f readerAtFile) GetContent() string {
+	data, _ := readAllAt(f)
+	return string(data)
+}
+
+func (f readerAtFile) Size() int64 {
+	return f.size
+}
+
+func (f readerAtFile) ReaderAt() io.ReaderAt {
+	return f.r
+}
+
+// chunks is an io.ReaderAt for content split into byte slices.
+type chunks struct {
+	data [][]byte
+	// offsets contains the offset of each chunk in the content
+	offsets []int64
+	size    int64
+}
+
+var (
+	_ io.ReaderAt = (*chunks)(nil)
+	_ rangeWriter = (*chunks)(nil)
+)
+
+func (c *chunks) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errNegativeOffset
+	}
+	if off >= c.size {
+		return 0, io.EOF
+	}
+	n := 0
+	// index of the last chunk starting at or before off
+	i := sort.Search(len(c.offsets), func(i int) bool {
+		return c.offsets[i] > off
+	}) - 1
+	for ; i >= 0 && i < len(c.data) && n < len(p); i++ {
+		n += copy(p[n:], c.data[i][off+int64(n)-c.offsets[i]:])
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// writeTo writes the content starting at off to w one chunk at a time.
+func (c *chunks) writeTo(w io.Writer, off int64) (int, error) {
+	written := 0
+	for i, chunk := range c.data {
+		end := c.offsets[i] + int64(len(chunk))
+		if end <= off {
+			continue
+		}
+		n, err := w.Write(chunk[max(off-c.offsets[i], 0):])
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// MakeChunkedFile creates a file with the concatenated chunks as its content,
+// e.g. for files too large for a single allocation.
+// The chunks are not copied and must not be modified.
+func MakeChunkedFile(name string, chunks ...[]byte) ReaderAtFile {
+	c := newChunks(chunks)
+	return readerAtFile{name: name, r: c, size: c.size}
+}
+
+func newChunks(data [][]byte) *chunks {
+	c := &chunks{
+		data:    make([][]byte, 0, len(data)),
+		offsets: make([]int64, 0, len(data)),
+	}
+	for _, d := range data {
+		if len(d) == 0 {
+			continue
+		}
+		c.data = append(c.data, d)
+		c.offsets = append(c.offsets, c.size)
+		c.size += int64(len(d))
+	}
+	return c
+}
+
+// readAllAt reads the complete content of f.
+func readAllAt(f ReaderAtFile) ([]byte, error) {
+	data := make([]byte, f.Size())
+	n, err := f.ReaderAt().ReadAt(data, 0)
+	if n == len(data) {
+		return data, nil
+	}
+	if err == nil || errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return nil, err
+}
+
+// readAt copies the content of f starting at off into r.
+func readAt(f ReaderAtFile, r []byte, off int64) (int, error) {
+	size := f.Size()
+	if off >= size {
+		return 0, nil
+	}
+	r = r[:min(int64(len(r)), size-off)]
+	n, err := f.ReaderAt().ReadAt(r, off)
+	if n == len(r) {
+		return n, nil
+	}
+	if err == nil || errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// rangeWriter is an io.ReaderAt writing its content without intermediate buffers.
+type rangeWriter interface {
+	// writeTo writes the content starting at off to w.
+	writeTo(w io.Writer, off int64) (int, error)
+}
+
+// writeAtTo writes the content of f starting at off to w.
+func writeAtTo(w io.Writer, f ReaderAtFile, off int64) (int, error) {
+	size := f.Size()
+	if off >= size {
+		return 0, nil
+	}
+	if rw, ok := f.ReaderAt().(rangeWriter); ok {
+		return rw.writeTo(w, off)
+	}
+	n, err := io.Copy(w, io.NewSectionReader(f.ReaderAt(), off, size-off))
+	if err == nil && n < size-off {
+		err = io.ErrUnexpectedEOF
+	}
+	return int(n), err
+}
@@ -0,0 +1,72 @@
+package memfis
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"testing/iotest"
+)
+
+// countingWriter counts the calls of Write.
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestChunkedFile(t *testing.T) {
+	content := "Hello, chunked World!"
+	fsys, err := MakeMemFS(
+		MakeChunkedFile("chunked", []byte("Hello"), nil, []byte(", chunked "), []byte("World!")),
+		MakeReaderAtFile("readerat", strings.NewReader(content+" ignored"), int64(len(content))),
+	)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	if err := fstest.TestFS(fsys, "chunked", "readerat"); err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+	for _, name := range []string{"chunked", "readerat"} {
+		f, err := fsys.Open(name)
+		if err != nil {
+			t.Fatalf("Open failed: %v", err)
+		}
+		if err := iotest.TestReader(f, []byte(content)); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		f.Close()
+		if data, err := fsys.ReadFile(name); err != nil || string(data) != content {
+			t.Errorf("%s: unexpected content %q (%v)", name, data, err)
+		}
+	}
+	f, err := fsys.Open("chunked")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer f.Close()
+	p := make([]byte, 9)
+	if n, err := f.(io.ReaderAt).ReadAt(p, 3); err != nil || string(p[:n]) != "lo, chunk" {
+		t.Errorf("ReadAt across chunks read %q (%v)", p[:n], err)
+	}
+	if _, err := f.(io.Seeker).Seek(7, io.SeekStart); err != nil {
+		t.Fatalf("Seek failed: %v", err)
+	}
+	var w countingWriter
+	if _, err := f.(io.WriterTo).WriteTo(&w); err != nil || w.String() != content[7:] || w.writes != 2 {
+		t.Errorf("WriteTo wrote %q in %d writes (%v)", w.String(), w.writes, err)
+	}
+
+	short, err := MakeMemFS(MakeReaderAtFile("short", strings.NewReader("abc"), 5))
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	if _, err := short.ReadFile("short"); err == nil {
+		t.Errorf("read truncated source without error")
+	}
+}
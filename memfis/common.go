@@ -1,8 +1,10 @@
 package memfis
 
 import (
+	"cmp"
 	"errors"
 	"io/fs"
+	"slices"
 	"strings"
 )
 
@@ -13,8 +15,19 @@ var (
 	errStatClosed     = errors.New("use of closed file")
 	errChangedRoot    = errors.New("subfs changed root directory")
 	errNegativeOffset = errors.New("negative offset")
+	errParentIsFile   = errors.New("parent directory is a file")
+	errWriteAtAppend  = errors.New("invalid use of WriteAt on file opened with O_APPEND")
 )
 
+// maxSizeHint caps the capacity preallocated for sizes claimed by archive headers.
+const maxSizeHint = 1 << 20
+
+// sizeHint converts an untrusted size to a capacity to preallocate for content.
+// Larger content grows the buffer while it is read.
+func sizeHint(size int64) int {
+	return int(max(0, min(size, maxSizeHint)))
+}
+
 // nextSegment returns the next part of path up to and including a "/".
 func nextSegment(path string) string {
 	i := strings.IndexByte(path, pathSeparator)
@@ -56,12 +69,13 @@ func fsPath(path string) string {
 }
 
 // validPath reports if a path in the memfs internal representation is valid according to io/fs.
+// Backslashes are forbidden, too, as documented for File.
 func validPath(path string) bool {
 	if path == "." {
 		// "." is not a valid name in the memfs internal representation
 		return false
 	}
-	return fs.ValidPath(fsPath(path))
+	return fs.ValidPath(fsPath(path)) && !strings.Contains(path, `\`)
 }
 
 // lenCommon retrieves the index of the first byte diffrent in a and b.
@@ -80,23 +94,24 @@ func commonPath(a, b string) string {
 	return a[:strings.LastIndexByte(a[:lenCommon(a, b)], pathSeparator)+1]
 }
 
-// increment a string s by 1 based on its binary representation.
-// Increment can be used to search for upper boundaries.
-func increment(s string) (string, bool) {
-	b := []byte(s)
-	for i := len(b) - 1; i >= 0; i-- {
-		if b[i] == 255 {
-			if i == 0 {
-				// input string already had every bit set
-				return s, false
-			}
-			b[i] = 0
+// comparePath compares two rootpaths like cmp.Compare, but "/" sorts before all other bytes.
+// In this order, the contents of a directory follow it directly and precede siblings using
+// its name as a prefix, e.g. "a/b" < "a.txt". Sorted names are in the order of a depth-first walk.
+func comparePath(a, b string) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		ca, cb := a[i], b[i]
+		switch {
+		case ca == cb:
 			continue
+		case ca == pathSeparator:
+			return -1
+		case cb == pathSeparator:
+			return 1
 		}
-		b[i]++
-		break
+		return cmp.Compare(ca, cb)
 	}
-	return string(b), true
+	return cmp.Compare(len(a), len(b))
 }
 
 // walk all directories and files in m and call fn with their rootpath.
@@ -115,10 +130,22 @@ func walk(rootpath string, fs []File, fn func(rootpath string)) {
 			prevdir = n[:o]
 			fn(prevdir)
 		}
-		fn(n)
+		if !isDir(n) {
+			// directory entries were already reported in the loop
+			fn(n)
+		}
 	}
 }
 
+// search reports if a file with GetName() == rootpath can be found in the sorted files.
+// It will retrieve the index it would be found at (never negative) and whether it existed.
+// idx will be in the inclusive interval [0, len(files)]
+func search(files []File, rootpath string) (idx int, found bool) {
+	return slices.BinarySearchFunc(files, rootpath, func(f File, seek string) int {
+		return comparePath(f.GetName(), seek)
+	})
+}
+
 // fsPathError creates a fs.PathError using the io/fs conformant path
 func fsPathError(op, fspath string, err error) *fs.PathError {
 	return &fs.PathError{
@@ -0,0 +1,215 @@
+package memfis
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+)
+
+// EncodingGzip is the encoding of gzip compressed content; it is always registered.
+const EncodingGzip = "gzip"
+
+var errUnknownEncoding = errors.New("unknown content encoding")
+
+// Decompressor creates a reader for the decompressed data of r.
+type Decompressor func(r io.Reader) (io.ReadCloser, error)
+
+var (
+	decompressorsMu sync.RWMutex
+	decompressors   = map[string]Decompressor{
+		EncodingGzip: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+	}
+)
+
+// RegisterDecompressor registers a Decompressor for CompressedFile contents with encoding.
+// This enables additional formats without a dependency of memfis, e.g. for
+// github.com/klauspost/compress/zstd:
+//
+//	memfis.RegisterDecompressor("zstd", func(r io.Reader) (io.ReadCloser, error) {
+//		d, err := zstd.NewReader(r)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return d.IOReadCloser(), nil
+//	})
+//
+// It panics if d is nil.
+func RegisterDecompressor(encoding string, d Decompressor) {
+	if d == nil {
+		panic("memfis: nil decompressor for encoding " + encoding)
+	}
+	decompressorsMu.Lock()
+	defer decompressorsMu.Unlock()
+	decompressors[encoding] = d
+}
+
+// CompressedFile is a file storing its content compressed.
+// Reading methods decompress the content on the fly,
+// GetContent must retrieve the decompressed content
+// and Size must report the decompressed size.
+type CompressedFile interface {
+	FileSizer
+	// Compressed retrieves the compressed content and its encoding.
+	// The encoding must be registered with RegisterDecompressor.
+	Compressed() (data []byte, encoding string)
+}
+
+// decompress creates a reader for the decompressed content of f.
+func decompress(f CompressedFile) (io.ReadCloser, error) {
+	data, encoding := f.Compressed()
+	decompressorsMu.RLock()
+	d, ok := decompressors[encoding]
+	decompressorsMu.RUnlock()
+	if !ok {
+		return nil, fsPathError("decompress", encoding, errUnknownEncoding)
+	}
+	return d(bytes.NewReader(data))
+}
+
+// decompressAll retrieves the decompressed content of f.
+func decompressAll(f CompressedFile) (string, error) {
+	rc, err := decompress(f)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	var sb strings.Builder
+	sb.Grow(sizeHint(f.Size()))
+	if _, err := io.Copy(&sb, rc); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// gzipFile is the CompressedFile created by MakeGzipFile.
+type gzipFile struct {
+	name string
+	data []byte
+	size int64
+}
+
+var _ CompressedFile = gzipFile{}
+
+// MakeGzipFile creates a CompressedFile storing content gzip compressed.
+func MakeGzipFile(name string, content []byte) (CompressedFile, error) {
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(content); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return gzipFile{
+		name: name,
+		data: buf.Bytes(),
+		size: int64(len(content)),
+	}, nil
+}
+
+func (f gzipFile) GetName() string {
+	return f.name
+}
+
+// GetContent decompresses the content, it is empty if decompression fails.
+func (f gzipFile) GetContent() string {
+	content, _ := decompressAll(f)
+	return content
+}
+
+func (f gzipFile) Size() int64 {
+	return f.size
+}
+
+func (f gzipFile) Compressed() ([]byte, string) {
+	return f.data, EncodingGzip
+}
+
+// seekStream positions the decompressing stream of a memFile at the read offset.
+func (f *memFile) seekStream(c CompressedFile) error {
+	if f.stream != nil && f.spos == f.ridx {
+		return nil
+	}
+	f.closeStream()
+	rc, err := decompress(c)
+	if err != nil {
+		return err
+	}
+	// streams can not seek, skip to the offset
+	if _, err := io.CopyN(io.Discard, rc, int64(f.ridx)); err != nil {
+		rc.Close()
+		return err
+	}
+	f.stream, f.spos = rc, f.ridx
+	return nil
+}
+
+// closeStream closes the decompressing stream of a memFile.
+func (f *memFile) closeStream() {
+	if f.stream != nil {
+		f.stream.Close()
+		f.stream = nil
+	}
+}
+
+func (f *memFile) readCompressed(c CompressedFile, r []byte) (int, error) {
+	if int64(f.ridx) >= c.Size() {
+		return 0, io.EOF
+	}
+	if err := f.seekStream(c); err != nil {
+		return 0, fsPathError("read", f.Name(), err)
+	}
+	n, err := f.stream.Read(r)
+	f.ridx += n
+	f.spos += n
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	if err != nil && err != io.EOF {
+		return n, fsPathError("read", f.Name(), err)
+	}
+	return n, err
+}
+
+func (f *memFile) readCompressedAt(c CompressedFile, r []byte, off int64) (int, error) {
+	if off > c.Size() {
+		return 0, fsPathError("read", f.Name(), io.ErrUnexpectedEOF)
+	}
+	rc, err := decompress(c)
+	if err != nil {
+		return 0, fsPathError("read", f.Name(), err)
+	}
+	defer rc.Close()
+	if _, err := io.CopyN(io.Discard, rc, off); err != nil {
+		return 0, fsPathError("read", f.Name(), err)
+	}
+	n, err := io.ReadFull(rc, r)
+	if err == io.ErrUnexpectedEOF || err == io.EOF {
+		return n, io.EOF
+	}
+	if err != nil {
+		return n, fsPathError("read", f.Name(), err)
+	}
+	return n, nil
+}
+
+func (f *memFile) writeCompressedTo(c CompressedFile, w io.Writer) (int64, error) {
+	if err := f.seekStream(c); err != nil {
+		return 0, fsPathError("read", f.Name(), err)
+	}
+	n, err := io.Copy(w, f.stream)
+	f.ridx += int(n)
+	f.spos += int(n)
+	if err != nil {
+		return n, fsPathError("read", f.Name(), err)
+	}
+	return n, nil
+}
@@ -0,0 +1,79 @@
+package memfis
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// rawFile is a CompressedFile with an unregistered encoding.
+type rawFile struct {
+	gzipFile
+}
+
+func (f rawFile) Compressed() ([]byte, string) {
+	return f.data, "unknown"
+}
+
+func TestCompressedFile(t *testing.T) {
+	content := []byte(strings.Repeat("compressible ", 1000))
+	f, err := MakeGzipFile("a/b.txt", content)
+	if err != nil {
+		t.Fatalf("compression failed: %v", err)
+	}
+	if data, _ := f.Compressed(); len(data) >= len(content) {
+		t.Errorf("content was not compressed: %d >= %d bytes", len(data), len(content))
+	}
+	fsys, err := MakeMemFS(f)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	if err := fstest.TestFS(fsys, "a/b.txt"); err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+	info, err := fsys.Stat("a/b.txt")
+	if err != nil || info.Size() != int64(len(content)) {
+		t.Errorf("expected size %d, got %d (%v)", len(content), info.Size(), err)
+	}
+	file, err := fsys.Open("a/b.txt")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer file.Close()
+	rs := file.(io.ReadSeeker)
+	if _, err := rs.Seek(13, io.SeekStart); err != nil {
+		t.Fatalf("Seek failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(rs); err != nil || !bytes.Equal(buf.Bytes(), content[13:]) {
+		t.Errorf("unexpected content after Seek (%v)", err)
+	}
+	if data, err := fsys.ReadFile("a/b.txt"); err != nil || !bytes.Equal(data, content) {
+		t.Errorf("unexpected content from ReadFile (%v)", err)
+	}
+	raw, err := MakeMemFS(rawFile{f.(gzipFile)})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	if _, err := raw.ReadFile("a/b.txt"); !errors.Is(err, errUnknownEncoding) {
+		t.Errorf("expected unknown encoding error, got %v", err)
+	}
+}
+
+func TestCompressedFileForgedSize(t *testing.T) {
+	content := []byte("Hello")
+	f, err := MakeGzipFile("a", content)
+	if err != nil {
+		t.Fatalf("compression failed: %v", err)
+	}
+	for _, size := range []int64{-1, 0, 1 << 62} {
+		forged := f.(gzipFile)
+		forged.size = size
+		if got, err := decompressAll(forged); err != nil || got != string(content) {
+			t.Errorf("size %d: got %q (%v), want %q", size, got, err, content)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package memfis
+
+import (
+	"io"
+	"sort"
+)
+
+// concatFile is the ReaderAtFile created by Concat.
+type concatFile struct {
+	name  string
+	parts []File
+	// offsets contains the offset of each part in the content
+	offsets []int64
+	size    int64
+}
+
+var (
+	_ ReaderAtFile = (*concatFile)(nil)
+	_ io.ReaderAt  = (*concatFile)(nil)
+	_ rangeWriter  = (*concatFile)(nil)
+)
+
+// Concat creates a file with the concatenated contents of parts, e.g. to join a header and a body
+// without copying them. The names of the parts are ignored.
+// The parts are read as needed, their sizes must not change.
+func Concat(name string, parts ...File) ReaderAtFile {
+	c := &concatFile{
+		name:    name,
+		parts:   make([]File, 0, len(parts)),
+		offsets: make([]int64, 0, len(parts)),
+	}
+	for _, p := range parts {
+		size := fileSize(p)
+		if size == 0 {
+			continue
+		}
+		c.parts = append(c.parts, p)
+		c.offsets = append(c.offsets, c.size)
+		c.size += size
+	}
+	return c
+}
+
+func (c *concatFile) GetName() string {
+	return c.name
+}
+
+// GetContent reads the content, it is empty if reading fails.
+func (c *concatFile) GetContent() string {
+	data, _ := readAllAt(c)
+	return string(data)
+}
+
+func (c *concatFile) Size() int64 {
+	return c.size
+}
+
+func (c *concatFile) ReaderAt() io.ReaderAt {
+	return c
+}
+
+func (c *concatFile) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errNegativeOffset
+	}
+	if off >= c.size {
+		return 0, io.EOF
+	}
+	n := 0
+	// index of the last part starting at or before off
+	i := sort.Search(len(c.offsets), func(i int) bool {
+		return c.offsets[i] > off
+	}) - 1
+	for ; i < len(c.parts) && n < len(p); i++ {
+		read, _, err := readContent(c.parts[i], p[n:], int(off+int64(n)-c.offsets[i]))
+		n += read
+		if err != nil {
+			return n, err
+		}
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// writeTo writes the content starting at off to w one part at a time.
+func (c *concatFile) writeTo(w io.Writer, off int64) (int, error) {
+	written := 0
+	for i, part := range c.parts {
+		end := c.offsets[i] + fileSize(part)
+		if end <= off {
+			continue
+		}
+		n, err := writeContent(w, part, int(max(off-c.offsets[i], 0)))
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
@@ -0,0 +1,47 @@
+package memfis
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"testing/iotest"
+)
+
+func TestConcat(t *testing.T) {
+	header := entry{content: "HEADER\n"}
+	body := MakeChunkedFile("", []byte("body "), []byte("in chunks\n"))
+	content := "HEADER\nbody in chunks\nEND"
+	fsys, err := MakeMemFS(
+		Concat("joined", header, entry{}, body, bytesEntry{content: []byte("END")}),
+		Concat("empty"),
+	)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	if err := fstest.TestFS(fsys, "joined", "empty"); err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+	f, err := fsys.Open("joined")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer f.Close()
+	if err := iotest.TestReader(f, []byte(content)); err != nil {
+		t.Errorf("reading failed: %v", err)
+	}
+	p := make([]byte, 10)
+	if n, err := f.(io.ReaderAt).ReadAt(p, 4); err != nil || string(p[:n]) != content[4:14] {
+		t.Errorf("ReadAt across parts read %q (%v)", p[:n], err)
+	}
+	if info, err := fsys.Stat("joined"); err != nil || info.Size() != int64(len(content)) {
+		t.Errorf("unexpected size %d (%v)", info.Size(), err)
+	}
+	if _, err := f.(io.Seeker).Seek(5, io.SeekStart); err != nil {
+		t.Fatalf("Seek failed: %v", err)
+	}
+	var sb strings.Builder
+	if _, err := f.(io.WriterTo).WriteTo(&sb); err != nil || sb.String() != content[5:] {
+		t.Errorf("WriteTo wrote %q (%v)", sb.String(), err)
+	}
+}
@@ -0,0 +1,180 @@
+package memfis
+
+import (
+	"bytes"
+	"io/fs"
+	"slices"
+	"strings"
+)
+
+// ChangeKind is the kind of a Change.
+type ChangeKind int
+
+const (
+	// Added paths exist only in the second file system.
+	Added ChangeKind = iota + 1
+	// Removed paths exist only in the first file system.
+	Removed
+	// Modified paths exist in both file systems but differ in type, permissions, content or link target.
+	Modified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	}
+	return "unknown"
+}
+
+// Change is a difference between two file systems.
+type Change struct {
+	Kind ChangeKind
+	// Path is the changed file, directory or symbolic link.
+	Path string
+	// Diff describes modified contents if DiffOptions.Content is set.
+	Diff string
+}
+
+func (c Change) String() string {
+	return c.Kind.String() + " " + c.Path
+}
+
+// DiffOptions configure the comparison of file systems by DiffOptions.Diff.
+// The zero value is usable, it is used by Diff.
+type DiffOptions struct {
+	// Content describes the difference of two regular files with different contents at path,
+	// e.g. as a unified diff. The description is stored in Change.Diff.
+	Content func(path string, a, b []byte) string
+	// IgnoreModes disables the comparison of permissions.
+	IgnoreModes bool
+}
+
+// Diff compares the file systems a and b with the default DiffOptions.
+func Diff(a, b fs.FS) ([]Change, error) {
+	return DiffOptions{}.Diff(a, b)
+}
+
+// Diff retrieves the changes turning a into b in the order of a depth-first walk.
+// The contents of added and removed directories and of paths changing between directory and
+// other types are not reported separately.
+// Symbolic links are compared by their targets.
+func (o DiffOptions) Diff(a, b fs.FS) ([]Change, error) {
+	entriesA, err := diffEntries(a)
+	if err != nil {
+		return nil, err
+	}
+	entriesB, err := diffEntries(b)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entriesA)+len(entriesB))
+	for name := range entriesA {
+		names = append(names, name)
+	}
+	for name := range entriesB {
+		if _, ok := entriesA[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.SortFunc(names, comparePath)
+	var changes []Change
+	// skip is the directory prefix of paths covered by a reported change, "" for none
+	skip := ""
+	for _, name := range names {
+		if skip != "" && strings.HasPrefix(name, skip) {
+			continue
+		}
+		skip = ""
+		da, inA := entriesA[name]
+		db, inB := entriesB[name]
+		var c Change
+		switch {
+		case !inB:
+			c = Change{Kind: Removed, Path: name}
+		case !inA:
+			c = Change{Kind: Added, Path: name}
+		default:
+			modified, diff, err := o.compare(a, b, name, da, db)
+			if err != nil {
+				return nil, err
+			}
+			if !modified {
+				continue
+			}
+			c = Change{Kind: Modified, Path: name, Diff: diff}
+		}
+		if (inA && da.IsDir()) || (inB && db.IsDir()) {
+			skip = name + "/"
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
+
+// diffEntries retrieves all entries of fsys except "." by path.
+func diffEntries(fsys fs.FS) (map[string]fs.DirEntry, error) {
+	entries := make(map[string]fs.DirEntry)
+	err := WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || name == "." {
+			return err
+		}
+		entries[name] = d
+		return nil
+	})
+	return entries, err
+}
+
+// compare reports if the entries at name in a and b differ and describes modified contents.
+func (o DiffOptions) compare(a, b fs.FS, name string, da, db fs.DirEntry) (bool, string, error) {
+	if da.Type() != db.Type() {
+		return true, "", nil
+	}
+	infoA, err := da.Info()
+	if err != nil {
+		return false, "", err
+	}
+	infoB, err := db.Info()
+	if err != nil {
+		return false, "", err
+	}
+	switch {
+	case da.IsDir():
+		return o.modeChanged(infoA, infoB), "", nil
+	case da.Type()&fs.ModeSymlink != 0:
+		targetA, err := fs.ReadLink(a, name)
+		if err != nil {
+			return false, "", err
+		}
+		targetB, err := fs.ReadLink(b, name)
+		if err != nil {
+			return false, "", err
+		}
+		return targetA != targetB, "", nil
+	}
+	contentA, err := fs.ReadFile(a, name)
+	if err != nil {
+		return false, "", err
+	}
+	contentB, err := fs.ReadFile(b, name)
+	if err != nil {
+		return false, "", err
+	}
+	if bytes.Equal(contentA, contentB) {
+		return o.modeChanged(infoA, infoB), "", nil
+	}
+	diff := ""
+	if o.Content != nil {
+		diff = o.Content(name, contentA, contentB)
+	}
+	return true, diff, nil
+}
+
+// modeChanged reports if the permissions differ and are compared.
+func (o DiffOptions) modeChanged(a, b fs.FileInfo) bool {
+	return !o.IgnoreModes && a.Mode() != b.Mode()
+}
@@ -0,0 +1,60 @@
+package memfis
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a, err := MakeMemFS(
+		entry{name: "same", content: "same"},
+		entry{name: "changed", content: "old"},
+		entry{name: "mode", content: "x", mode: 0o644},
+		entry{name: "gone/a", content: "a"},
+		entry{name: "gone/b", content: "b"},
+		entry{name: "todir", content: "file"},
+		MakeSymlink("link", "same"),
+	)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	b, err := MakeMemFS(
+		entry{name: "same", content: "same"},
+		entry{name: "changed", content: "new"},
+		entry{name: "mode", content: "x", mode: 0o755},
+		entry{name: "new/deep/file", content: "new"},
+		entry{name: "todir/file", content: "file"},
+		MakeSymlink("link", "changed"),
+	)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	opts := DiffOptions{
+		Content: func(path string, a, b []byte) string {
+			return fmt.Sprintf("-%s\n+%s\n", a, b)
+		},
+	}
+	changes, err := opts.Diff(a, b)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	want := []Change{
+		{Kind: Modified, Path: "changed", Diff: "-old\n+new\n"},
+		{Kind: Removed, Path: "gone"},
+		{Kind: Modified, Path: "link"},
+		{Kind: Modified, Path: "mode"},
+		{Kind: Added, Path: "new"},
+		{Kind: Modified, Path: "todir"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("expected changes %v, got %v", want, changes)
+	}
+	opts.IgnoreModes = true
+	if changes, err := opts.Diff(a, b); err != nil || len(changes) != len(want)-1 {
+		t.Errorf("expected changes without mode, got %v (%v)", changes, err)
+	}
+	if changes, err := Diff(a, a); err != nil || len(changes) != 0 {
+		t.Errorf("expected no changes for the same file system, got %v (%v)", changes, err)
+	}
+}
@@ -0,0 +1,101 @@
+package memfis
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestEmptyDirectories(t *testing.T) {
+	fsys, err := MakeMemFS(
+		entry{name: "a/"},
+		entry{name: "b/c/"},
+		entry{name: "b/d"},
+		entry{name: "b/e/f/"},
+		entry{name: "g"},
+	)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	if err := fstest.TestFS(fsys, "a", "b/c", "b/d", "b/e/f", "g"); err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+	for _, name := range []string{"a", "b/c", "b/e", "b/e/f"} {
+		info, err := fsys.Stat(name)
+		if err != nil || !info.IsDir() {
+			t.Errorf("expected directory %q, got %v (%v)", name, info, err)
+		}
+	}
+	entries, err := fsys.ReadDir("a")
+	if err != nil || len(entries) != 0 {
+		t.Errorf("expected no entries in a, got %v (%v)", entries, err)
+	}
+	f, err := fsys.Open("b/c")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if entries, err := f.(fs.ReadDirFile).ReadDir(1); len(entries) != 0 || err != io.EOF {
+		t.Errorf("expected io.EOF for empty directory, got %v (%v)", entries, err)
+	}
+	f.Close()
+	var names []string
+	for _, e := range must(fsys.ReadDir("b")) {
+		names = append(names, e.Name())
+		if e.Name() != "d" && !e.IsDir() {
+			t.Errorf("expected directory entry for %q", e.Name())
+		}
+	}
+	if !slices.Equal(names, []string{"c", "d", "e"}) {
+		t.Errorf("unexpected entries %q in b", names)
+	}
+	sub, err := fsys.Sub("b/c")
+	if err != nil {
+		t.Fatalf("Sub failed: %v", err)
+	}
+	if err := fstest.TestFS(sub); err != nil {
+		t.Errorf("empty sub file system test failed: %v", err)
+	}
+	matches, err := fsys.Glob("*/*")
+	if err != nil || !slices.Equal(matches, []string{"b/c", "b/d", "b/e"}) {
+		t.Errorf("unexpected matches %q (%v)", matches, err)
+	}
+	if _, err := fsys.ReadFile("a"); err == nil {
+		t.Errorf("ReadFile succeeded for a directory")
+	}
+	if _, err := MakeMemFS(entry{name: "a/", content: "data"}); err == nil {
+		t.Errorf("created a directory with content")
+	}
+	if _, err := MakeMemFS(entry{name: "a/"}, entry{name: "a"}); err == nil {
+		t.Errorf("created a directory and a file with the same name")
+	}
+	if _, err := fsys.Stat("a/missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected missing file in empty directory, got %v", err)
+	}
+
+	var b Builder
+	for _, name := range []string{"x/y/z", "x/w"} {
+		if err := b.MkdirAll(name); err != nil {
+			t.Fatalf("MkdirAll failed: %v", err)
+		}
+	}
+	if err := b.AddFile("x/file", "content"); err != nil {
+		t.Fatalf("AddFile failed: %v", err)
+	}
+	built, err := b.Freeze()
+	if err != nil {
+		t.Fatalf("Freeze failed: %v", err)
+	}
+	if err := fstest.TestFS(built, "x/y/z", "x/w", "x/file"); err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+}
+
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
@@ -1,6 +1,7 @@
 package memfis
 
 import (
+	"bytes"
 	"io"
 	"io/fs"
 	"strings"
@@ -23,6 +24,8 @@ type File interface {
 }
 
 // FileSizer is a file that supports direct retrieval of the file size.
+// Creating a MemFS, Stat, ReadDir, Seek and walking the file system do not call GetContent
+// of a FileSizer, only reading its content does.
 type FileSizer interface {
 	File
 	// Size retrieves the file size in bytes; it must match len(GetContent())
@@ -34,11 +37,137 @@ func fileSize(f File) int64 {
 	if fs, ok := f.(FileSizer); ok {
 		return fs.Size()
 	}
+	if b, ok := f.(BytesFile); ok {
+		return int64(len(b.Bytes()))
+	}
 	return int64(len(f.GetContent()))
 }
 
+// FileModer is a file with custom permissions, e.g. an executable or read only file.
+type FileModer interface {
+	File
+	// Mode retrieves the file mode.
+	// Only the permission bits are used, the file type is always a regular file.
+	// A zero mode is replaced by the default permissions.
+	Mode() fs.FileMode
+}
+
+// fileMode retrieves the mode of a file using Mode() for FileModer.
+func fileMode(f File) fs.FileMode {
+	if fm, ok := f.(FileModer); ok {
+		if perm := fm.Mode().Perm(); perm != 0 {
+			return perm
+		}
+	}
+	return modeFile
+}
+
+// FileSyser is a file providing the value of FileInfo.Sys,
+// e.g. owner and group, the source URL or generator metadata.
+type FileSyser interface {
+	File
+	// Sys retrieves the underlying data source, it can be nil.
+	Sys() any
+}
+
+// fileSys retrieves the Sys value of a file using Sys() for FileSyser
+// and *Metadata for other files with metadata.
+func fileSys(f File) any {
+	if fs, ok := f.(FileSyser); ok {
+		return fs.Sys()
+	}
+	if md, ok := metadata(f); ok {
+		return md
+	}
+	return nil
+}
+
+// BytesFile is a file backed by a byte slice.
+// Reading it does not require a conversion of its content to a string.
+type BytesFile interface {
+	File
+	// Bytes retrieves the content. The returned slice must not be modified.
+	Bytes() []byte
+}
+
+// contentLoader is a File loading its content on first access.
+// It reports errors that GetContent can not return.
+type contentLoader interface {
+	loadContent() (string, error)
+}
+
+// content retrieves the content of f and reports errors of lazily loaded files.
+func content(f File) (string, error) {
+	if l, ok := f.(contentLoader); ok {
+		return l.loadContent()
+	}
+	if c, ok := f.(CompressedFile); ok {
+		return decompressAll(c)
+	}
+	if ra, ok := f.(ReaderAtFile); ok {
+		data, err := readAllAt(ra)
+		return string(data), err
+	}
+	return f.GetContent(), nil
+}
+
+// contentBytes retrieves a copy of the content of f.
+func contentBytes(f File) ([]byte, error) {
+	if b, ok := f.(BytesFile); ok {
+		return bytes.Clone(b.Bytes()), nil
+	}
+	if ra, ok := f.(ReaderAtFile); ok {
+		return readAllAt(ra)
+	}
+	data, err := content(f)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(data), nil
+}
+
+// readContent copies the content of f starting at off into r.
+// It retrieves the number of copied bytes and the size of the content.
+func readContent(f File, r []byte, off int) (n, size int, err error) {
+	if b, ok := f.(BytesFile); ok {
+		data := b.Bytes()
+		if off < len(data) {
+			n = copy(r, data[off:])
+		}
+		return n, len(data), nil
+	}
+	if ra, ok := f.(ReaderAtFile); ok {
+		n, err := readAt(ra, r, int64(off))
+		return n, int(ra.Size()), err
+	}
+	data, err := content(f)
+	if err != nil {
+		return 0, 0, err
+	}
+	if off < len(data) {
+		n = copy(r, data[off:])
+	}
+	return n, len(data), nil
+}
+
+// writeContent writes the content of f starting at off to w.
+func writeContent(w io.Writer, f File, off int) (int, error) {
+	if b, ok := f.(BytesFile); ok {
+		data := b.Bytes()
+		return w.Write(data[min(off, len(data)):])
+	}
+	if ra, ok := f.(ReaderAtFile); ok {
+		return writeAtTo(w, ra, int64(off))
+	}
+	data, err := content(f)
+	if err != nil {
+		return 0, err
+	}
+	return io.WriteString(w, data[min(off, len(data)):])
+}
+
 const (
-	// regular file with read/write for users and read for group members
+	// default for regular files with read/write for users and read for group members
 	modeFile fs.FileMode = 0o640
 	typeFile             = modeFile & fs.ModeType
 )
@@ -51,6 +180,9 @@ type memFile struct {
 	name string
 	// offset into file.GetContent(), negative on close
 	ridx int
+	// stream decompresses a CompressedFile, spos is its offset
+	stream io.ReadCloser
+	spos   int
 }
 
 // for convenience reasons, required interfaces are all implemented by the same read-only
@@ -86,11 +218,17 @@ func (f *memFile) Name() string {
 }
 
 func (f *memFile) Size() int64 {
+	if target, ok := linkTarget(f.file); ok {
+		return int64(len(target))
+	}
 	return fileSize(f.file)
 }
 
 func (f *memFile) Mode() fs.FileMode {
-	return modeFile
+	if _, ok := linkTarget(f.file); ok {
+		return modeSymlink
+	}
+	return fileMode(f.file)
 }
 
 func (f *memFile) ModTime() time.Time {
@@ -102,11 +240,11 @@ func (f *memFile) IsDir() bool {
 }
 
 func (f *memFile) Sys() any {
-	return nil
+	return fileSys(f.file)
 }
 
 func (m *memFile) Type() fs.FileMode {
-	return typeFile
+	return m.Mode() & fs.ModeType
 }
 
 func (m *memFile) Info() (fs.FileInfo, error) {
@@ -120,6 +258,7 @@ func (f *memFile) isClosed() bool {
 func (f *memFile) Close() error {
 	// ridx < 0 as close marker; alternative >= len(f.GetContent()) requires more calls
 	f.ridx = -1
+	f.closeStream()
 	return nil
 }
 
@@ -134,11 +273,16 @@ func (f *memFile) Read(r []byte) (int, error) {
 	if f.isClosed() {
 		return 0, fsPathError("read", f.Name(), fs.ErrClosed)
 	}
-	data := f.file.GetContent()
-	if f.ridx >= len(data) {
+	if c, ok := f.file.(CompressedFile); ok {
+		return f.readCompressed(c, r)
+	}
+	n, size, err := readContent(f.file, r, f.ridx)
+	if err != nil {
+		return 0, fsPathError("read", f.Name(), err)
+	}
+	if f.ridx >= size {
 		return 0, io.EOF
 	}
-	n := copy(r, data[f.ridx:])
 	f.ridx += n
 	return n, nil
 }
@@ -151,12 +295,16 @@ func (f *memFile) ReadAt(r []byte, off int64) (n int, err error) {
 	if f.isClosed() {
 		return 0, fsPathError("read", f.Name(), fs.ErrClosed)
 	}
-	data := f.GetContent()
-	o := int(off)
-	if o > len(data) {
+	if c, ok := f.file.(CompressedFile); ok {
+		return f.readCompressedAt(c, r, off)
+	}
+	n, size, err := readContent(f.file, r, int(off))
+	if err != nil {
+		return 0, fsPathError("read", f.Name(), err)
+	}
+	if int(off) > size {
 		return 0, fsPathError("read", f.Name(), io.ErrUnexpectedEOF)
 	}
-	n = copy(r, data[o:])
 	if n < len(r) {
 		return n, io.EOF
 	}
@@ -167,7 +315,10 @@ func (f *memFile) WriteTo(w io.Writer) (n int64, err error) {
 	if f.isClosed() {
 		return 0, fsPathError("read", f.Name(), fs.ErrClosed)
 	}
-	i, err := io.WriteString(w, f.GetContent())
+	if c, ok := f.file.(CompressedFile); ok {
+		return f.writeCompressedTo(c, w)
+	}
+	i, err := writeContent(w, f.file, f.ridx)
 	f.ridx += i
 	if err != nil {
 		return int64(i), fsPathError("read", f.Name(), err)
@@ -179,7 +330,7 @@ func (f *memFile) Seek(offset int64, whence int) (int64, error) {
 	if f.isClosed() {
 		return 0, fsPathError("seek", f.Name(), fs.ErrClosed)
 	}
-	data := f.GetContent()
+	size := fileSize(f.file)
 	var ridx int64
 	switch whence {
 	case io.SeekStart:
@@ -187,11 +338,11 @@ func (f *memFile) Seek(offset int64, whence int) (int64, error) {
 	case io.SeekCurrent:
 		ridx = int64(f.ridx) + offset
 	case io.SeekEnd:
-		ridx = int64(len(data)) + offset
+		ridx = size + offset
 	default:
 		return 0, fsPathError("seek", f.Name(), fs.ErrInvalid)
 	}
-	if ridx < 0 || ridx > int64(len(data)) {
+	if ridx < 0 || ridx > size {
 		return 0, fsPathError("seek", f.Name(), fs.ErrInvalid)
 	}
 	f.ridx = int(ridx)
@@ -1,12 +1,12 @@
 package memfis
 
 import (
-	"cmp"
 	"errors"
 	"io"
 	"io/fs"
 	"path"
 	"slices"
+	"sort"
 	"strings"
 )
 
@@ -19,38 +19,136 @@ type MemFS interface {
 }
 
 type memFS struct {
-	// files is authoritative and contains file entries sorted ascending by name (using cmp.Compare).
+	// files is authoritative and contains file entries sorted ascending by name (using comparePath).
 	// on creation, each file has to be checked with validPath.
 	// If directories are ever supported, they are filenames with a terminal "/" are directories (content is ignored)
 	files []File
 	// rootpath is an optional subdirectory, it must end with "/" to be usable in length-based prefix cutting for e.g. Sub.
 	rootpath string
+	opts     Options
+	// tree is the optional index of rootpath, offset the index of files[0] in the files of the tree root.
+	tree   *treeNode
+	offset int
 }
 
-var _ MemFS = (*memFS)(nil)
+var (
+	_ MemFS         = (*memFS)(nil)
+	_ fs.ReadLinkFS = (*memFS)(nil)
+)
+
+// Options configure a MemFS created by Options.MakeMemFS.
+// The zero value provides the defaults used by MakeMemFS.
+type Options struct {
+	// TreeIndex indexes directories in a tree over path segments,
+	// so opening a directory, ReadDir and Sub do not depend on the number of files.
+	// It speeds up file systems with hundreds of thousands of files and costs memory per directory.
+	// It is ignored for a MemWriteFS.
+	TreeIndex bool
+
+	// DoubleStar enables "**" as a pattern element in Glob.
+	// It matches any number of path elements including none, e.g. "**/*.go" matches "a.go" and "a/b/c.go".
+	DoubleStar bool
+
+	// The path options fix messy file names, e.g. of generator output, on creation.
+	// Names that are still invalid afterwards are rejected.
+
+	// CleanPaths removes empty and "." elements from file names,
+	// e.g. "./a//b" becomes "a/b" and "/a" becomes "a".
+	CleanPaths bool
+	// Backslashes converts "\" in file names to "/".
+	Backslashes bool
+	// ResolveDotDot removes ".." elements and the preceding element from file names.
+	// Names leaving the root are rejected. Without it, all names containing ".." are rejected.
+	ResolveDotDot bool
+
+	// The limits bound the memory used by file systems built from untrusted input.
+	// They are checked on creation and on every change of a MemWriteFS,
+	// exceeding them causes a *LimitError. Zero disables a limit.
 
+	// MaxBytes limits the total size of all files.
+	MaxBytes int64
+	// MaxFiles limits the number of files and symbolic links, directories are not counted.
+	MaxFiles int
+	// MaxDepth limits the number of elements in paths, e.g. "a/b/c" has 3.
+	MaxDepth int
+
+	// SpillSize moves the content of larger files to temporary files when the file system is created,
+	// e.g. for trees that do not fit into memory. Zero disables spilling.
+	// Spilled files are read on demand and removed when the file system is garbage collected.
+	// Files written to a MemWriteFS later are kept in memory.
+	SpillSize int64
+	// SpillDir is the directory for spilled files, the default is os.TempDir().
+	SpillDir string
+}
+
+// MakeMemFS creates a MemFS containing files with the default Options.
 func MakeMemFS(files ...File) (MemFS, error) {
+	return Options{}.MakeMemFS(files...)
+}
+
+// MakeMemFS creates a MemFS containing files.
+// Files with a name ending in "/" and empty content are empty directories;
+// they are visible in ReadDir, Stat, Sub and Glob like any other directory.
+func (o Options) MakeMemFS(files ...File) (MemFS, error) {
 	fs := make([]File, len(files))
 	copy(fs, files)
-	for _, f := range fs {
+	for i, f := range fs {
 		n := f.GetName()
-		if isDir(n) && len(f.GetContent()) != 0 {
+		if o.normalizes() {
+			clean, err := o.normalize(n)
+			if err != nil {
+				return nil, err
+			}
+			if clean != n {
+				n, f = clean, withName(f, clean)
+				fs[i] = f
+			}
+		}
+		if isDir(n) && fileSize(f) != 0 {
 			// support empty directories with size 0 and name "" or ending in "/"
 			return nil, errors.New("file ending with / is directory but has content: " + n)
 		}
 		if !validPath(n) {
 			return nil, errors.New("unsupported file name " + n)
 		}
+		if err := o.checkDepth("create", n); err != nil {
+			return nil, err
+		}
+	}
+	bytes, count := usage(fs)
+	if err := o.checkUsage("create", ".", bytes, count); err != nil {
+		return nil, err
+	}
+	for i, f := range fs {
+		if !o.spills(f) {
+			continue
+		}
+		s, err := o.spill(f)
+		if err != nil {
+			return nil, err
+		}
+		fs[i] = s
 	}
 	if len(fs) <= 1 {
 		// same return, but skips logic that's not needed in the no or one file case
-		return &memFS{
+		m := &memFS{
 			files: fs,
-		}, nil
+			opts:  o,
+		}
+		if o.TreeIndex {
+			m.tree = buildTree(fs)
+		}
+		return m, nil
 	}
 	slices.SortStableFunc(fs, func(a, b File) int {
-		return cmp.Compare(a.GetName(), b.GetName())
+		return comparePath(a.GetName(), b.GetName())
 	})
+	for i := 1; i < len(fs); i++ {
+		if fs[i-1].GetName() == fs[i].GetName() {
+			// walk reports directories only once, check them here
+			return nil, errors.New("file names must be unique")
+		}
+	}
 	pn, dupe := "", false
 	walk("", fs, func(rootpath string) {
 		if dupe {
@@ -75,9 +173,19 @@ func MakeMemFS(files ...File) (MemFS, error) {
 	if dupe {
 		return nil, errors.New("file names must be unique")
 	}
-	return &memFS{
+	m := &memFS{
 		files: fs,
-	}, nil
+		opts:  o,
+	}
+	if o.TreeIndex {
+		m.tree = buildTree(fs)
+	}
+	return m, nil
+}
+
+// changeCount is always 0, a memFS never changes.
+func (m *memFS) changeCount() uint64 {
+	return 0
 }
 
 func (m *memFS) root(path string) string {
@@ -95,9 +203,7 @@ func (m *memFS) rootdir(path string) string {
 // It will retrieve the index it would be found at (never negative) and whether it existed.
 // idx will be in the inclusive interval [0, len(m.files)]
 func (m *memFS) find(rootpath string) (idx int, found bool) {
-	return slices.BinarySearchFunc(m.files, rootpath, func(f File, seek string) int {
-		return cmp.Compare(f.GetName(), seek)
-	})
+	return search(m.files, rootpath)
 }
 
 // open returns the *memFile or *memReadableDir at rootpath
@@ -106,27 +212,35 @@ func (m *memFS) open(rootpath string) (*memFile, *memFS, error) {
 		// open current directory
 		return nil, m, nil
 	}
+	if m.tree != nil {
+		if d, ok := m.openTree(rootpath); ok {
+			return nil, d, nil
+		}
+	}
 	low, lok := m.find(rootpath)
-	if lok {
+	if lok && !isDir(rootpath) {
 		// single file found
 		file := makeFile(m.files[low])
 		return file, nil, nil
 	}
+	// must be directory; search with the terminal "/" to skip siblings sharing the prefix
+	rootdir := toDir(rootpath)
+	if !lok {
+		low, _ = m.find(rootdir)
+	}
 	numFiles := len(m.files)
-	if numFiles <= low || !strings.HasPrefix(m.files[low].GetName(), rootpath) {
+	if numFiles <= low || !strings.HasPrefix(m.files[low].GetName(), rootdir) {
 		// searched path not found
 		return nil, nil, fs.ErrNotExist
 	}
-	high := numFiles
-	// find high index by searching for ... path++
-	if inc, ok := increment(rootpath); ok {
-		// ok -> could increment, rootprefix was not already maximum
-		high, _ = m.find(inc)
-	}
-	// must be directory
+	// all names starting with rootdir are adjacent, the first one without it is the upper bound
+	high := low + sort.Search(numFiles-low, func(i int) bool {
+		return !strings.HasPrefix(m.files[low+i].GetName(), rootdir)
+	})
 	fs := &memFS{
 		files:    m.files[low:high],
-		rootpath: toDir(rootpath),
+		rootpath: rootdir,
+		opts:     m.opts,
 	}
 	return nil, fs, nil
 }
@@ -136,7 +250,7 @@ func (m *memFS) Sub(dir string) (fs.FS, error) {
 	if rootdir != "" && !validPath(rootdir) {
 		return nil, fsPathError("sub", dir, fs.ErrInvalid)
 	}
-	_, d, err := m.open(rootdir)
+	_, d, err := m.follow(rootdir, true)
 	if d == nil || err != nil {
 		return nil, fsPathError("sub", dir, fs.ErrNotExist)
 	}
@@ -144,8 +258,11 @@ func (m *memFS) Sub(dir string) (fs.FS, error) {
 }
 
 func (m *memFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, fsPathError("open", name, fs.ErrInvalid)
+	}
 	rootpath := m.root(name)
-	f, d, err := m.open(rootpath)
+	f, d, err := m.follow(rootpath, true)
 	if err != nil {
 		return nil, fsPathError("open", name, err)
 	}
@@ -159,7 +276,10 @@ func (m *memFS) Open(name string) (fs.File, error) {
 }
 
 func (m *memFS) Stat(name string) (fs.FileInfo, error) {
-	f, d, err := m.open(m.root(name))
+	if !fs.ValidPath(name) {
+		return nil, fsPathError("stat", name, fs.ErrInvalid)
+	}
+	f, d, err := m.follow(m.root(name), true)
 	if err != nil {
 		return nil, fsPathError("stat", name, err)
 	}
@@ -170,15 +290,25 @@ func (m *memFS) Stat(name string) (fs.FileInfo, error) {
 }
 
 func (m *memFS) ReadFile(name string) ([]byte, error) {
-	f, _, _ := m.open(m.root(name))
+	if !fs.ValidPath(name) {
+		return nil, fsPathError("readfile", name, fs.ErrInvalid)
+	}
+	f, _, _ := m.follow(m.root(name), true)
 	if f == nil {
 		return nil, fsPathError("readfile", name, fs.ErrNotExist)
 	}
-	return []byte(f.GetContent()), nil
+	data, err := contentBytes(f.file)
+	if err != nil {
+		return nil, fsPathError("readfile", name, err)
+	}
+	return data, nil
 }
 
 func (m *memFS) ReadDir(name string) ([]fs.DirEntry, error) {
-	_, d, _ := m.open(m.root(name))
+	if !fs.ValidPath(name) {
+		return nil, fsPathError("readdir", name, fs.ErrInvalid)
+	}
+	_, d, _ := m.follow(m.root(name), true)
 	if d == nil {
 		return nil, fsPathError("readdir", name, fs.ErrNotExist)
 	}
@@ -192,10 +322,14 @@ func (m *memFS) Glob(pattern string) (matches []string, err error) {
 		// check pattern early to safely ignore err later
 		return nil, fsPathError("glob", ".", err)
 	}
+	match := path.Match
+	if m.opts.DoubleStar {
+		match = matchDoubleStar
+	}
 	rpl := len(m.rootpath)
 	walk(m.rootpath, m.files, func(rp string) {
 		n := fsPath(rp[rpl:])
-		if ok, _ := path.Match(pattern, n); ok {
+		if ok, _ := match(pattern, n); ok {
 			matches = append(matches, n)
 		}
 	})
@@ -257,9 +391,17 @@ func (m *memFS) dirEntries(entries []fs.DirEntry, dc dirCursor, n int) ([]fs.Dir
 					pidx:     len(rp),
 				},
 			)
+			if last, ok := m.skipTree(next); ok {
+				// continue after the contents of the directory
+				dc.idx = last
+			}
 			continue
 		}
 		entries = append(entries, makeFile(f))
 	}
+	if n > 0 && len(entries) == ne {
+		// only the entry of an empty directory itself was left
+		return entries, dc, io.EOF
+	}
 	return entries, dc, nil
 }
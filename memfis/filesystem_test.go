@@ -1,6 +1,10 @@
 package memfis
 
 import (
+	"errors"
+	"io/fs"
+	"path"
+	"slices"
 	"testing"
 	"testing/fstest"
 )
@@ -72,6 +76,21 @@ func TestMemFS(t *testing.T) {
 	)
 }
 
+func TestMemFSInvalidPath(t *testing.T) {
+	fsys, err := MakeMemFS(makeFiles("a/b", "Hi")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a/", "/a", "a//b", "./a", "a/../a/b"} {
+		if _, err := fsys.Open(name); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("Open(%q) got error %v, want fs.ErrInvalid", name, err)
+		}
+		if _, err := fsys.Stat(name); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("Stat(%q) got error %v, want fs.ErrInvalid", name, err)
+		}
+	}
+}
+
 func TestMemFSFilenameCollision(t *testing.T) {
 	// file name a is not unique
 	_, err := MakeMemFS(makeFiles(
@@ -93,3 +112,201 @@ func TestMemFSFileAndDirnameCollision(t *testing.T) {
 		t.Fatalf("MakeMemFS created a directory named like a file. Names must be unique")
 	}
 }
+
+func TestFromMap(t *testing.T) {
+	fsys, err := FromMap(map[string]string{
+		"a/b": "Hello",
+		"c":   "Hi",
+		"d/":  "",
+	})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v\n", err)
+	}
+	if err := fstest.TestFS(fsys, "a/b", "c", "d"); err != nil {
+		t.Fatalf("file system test failed: %v\n", err)
+	}
+	_, err = FromBytesMap(map[string][]byte{
+		"a":   []byte("Hi"),
+		"a/b": []byte("Ho"),
+	})
+	if err == nil {
+		t.Fatalf("FromBytesMap created a directory named like a file")
+	}
+}
+
+func TestFromFS(t *testing.T) {
+	src := fstest.MapFS{
+		"root/a.go":      {Data: []byte("package a")},
+		"root/a_test.go": {Data: []byte("package a")},
+		"root/b/c.go":    {Data: []byte("package c")},
+		"root/b/d.txt":   {Data: []byte("Hi")},
+		"other":          {Data: []byte("Ho")},
+	}
+	fsys, err := FromFS(src, "root")
+	if err != nil {
+		t.Fatalf("file system creation failed: %v\n", err)
+	}
+	if err := fstest.TestFS(fsys, "a.go", "a_test.go", "b/c.go", "b/d.txt"); err != nil {
+		t.Fatalf("file system test failed: %v\n", err)
+	}
+	fsys, err = FromFS(src, "root", "*.go", "b/*.go")
+	if err != nil {
+		t.Fatalf("file system creation failed: %v\n", err)
+	}
+	if err := fstest.TestFS(fsys, "a.go", "a_test.go", "b/c.go"); err != nil {
+		t.Fatalf("file system test failed: %v\n", err)
+	}
+	if _, err := fsys.Stat("b/d.txt"); err == nil {
+		t.Fatalf("file not matching the patterns was included")
+	}
+}
+
+// sysFile is a File with metadata.
+type sysFile struct {
+	name string
+	sys  any
+}
+
+func (f sysFile) GetName() string {
+	return f.name
+}
+
+func (f sysFile) GetContent() string {
+	return ""
+}
+
+func (f sysFile) Sys() any {
+	return f.sys
+}
+
+func TestFileSyser(t *testing.T) {
+	type owner struct {
+		uid, gid int
+	}
+	fsys, err := MakeMemFS(sysFile{name: "a/b", sys: owner{1, 2}}, entry{name: "c"})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v\n", err)
+	}
+	info, err := fsys.Stat("a/b")
+	if err != nil || info.Sys() != (owner{1, 2}) {
+		t.Errorf("unexpected Sys value %v (%v)", info.Sys(), err)
+	}
+	entries, err := fsys.ReadDir("a")
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("unexpected entries %v (%v)", entries, err)
+	}
+	if info, _ := entries[0].Info(); info.Sys() != (owner{1, 2}) {
+		t.Errorf("unexpected Sys value %v in directory entry", info.Sys())
+	}
+	if info, _ := fsys.Stat("c"); info.Sys() != nil {
+		t.Errorf("unexpected Sys value %v for plain file", info.Sys())
+	}
+}
+
+// bytesOnlyFile is a BytesFile failing the test when GetContent is used.
+type bytesOnlyFile struct {
+	t    *testing.T
+	name string
+	data []byte
+}
+
+func (f bytesOnlyFile) GetName() string {
+	return f.name
+}
+
+func (f bytesOnlyFile) GetContent() string {
+	f.t.Errorf("GetContent called for %q", f.name)
+	return string(f.data)
+}
+
+func (f bytesOnlyFile) Bytes() []byte {
+	return f.data
+}
+
+func TestBytesFile(t *testing.T) {
+	data := []byte("Hello, World")
+	fsys, err := MakeMemFS(bytesOnlyFile{t: t, name: "a/b", data: data})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v\n", err)
+	}
+	read, err := fsys.ReadFile("a/b")
+	if err != nil || string(read) != string(data) {
+		t.Fatalf("expected %q, got %q (%v)", data, read, err)
+	}
+	read[0] = 'J'
+	if data[0] != 'H' {
+		t.Errorf("ReadFile returned the backing slice")
+	}
+	if err := fstest.TestFS(fsys, "a/b"); err != nil {
+		t.Fatalf("file system test failed: %v\n", err)
+	}
+}
+
+func TestGlob(t *testing.T) {
+	files := map[string]string{
+		"a/b/c.go":  "",
+		"a/d.go":    "",
+		"a/x.txt":   "",
+		"a.txt":     "",
+		"ab/e.go":   "",
+		"empty/":    "",
+		"f.go":      "",
+		"[weird].x": "",
+	}
+	fsys, err := FromMap(files)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v\n", err)
+	}
+	sub, err := fsys.Sub("a")
+	if err != nil {
+		t.Fatalf("Sub failed: %v\n", err)
+	}
+	patterns := []string{"*", "*/*", "a/*", "*/*/*", "a*", "*.go", "*/*.go", "a/b", "?", "[a-b]*", "\\[weird\\].x", "empty", "missing/*", "b/*"}
+	for _, fsys := range []MemFS{fsys, sub.(MemFS)} {
+		for _, pattern := range patterns {
+			got, err := fsys.Glob(pattern)
+			if err != nil {
+				t.Errorf("Glob(%q) failed: %v", pattern, err)
+			}
+			// reference implementation based on ReadDir and path.Match
+			expected, _ := fs.Glob(noGlobFS{fsys}, pattern)
+			slices.Sort(got)
+			slices.Sort(expected)
+			if !slices.Equal(got, expected) {
+				t.Errorf("Glob(%q): expected %q, got %q", pattern, expected, got)
+			}
+		}
+		if _, err := fsys.Glob("[a-"); !errors.Is(err, path.ErrBadPattern) {
+			t.Errorf("expected bad pattern error, got %v", err)
+		}
+	}
+
+	files["a/b/c/d.go"] = ""
+	fileList := make([]File, 0, len(files))
+	for name, content := range files {
+		fileList = append(fileList, entry{name: name, content: content})
+	}
+	fsys, err = Options{DoubleStar: true}.MakeMemFS(fileList...)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v\n", err)
+	}
+	for pattern, expected := range map[string][]string{
+		"**/*.go":   {"a/b/c.go", "a/b/c/d.go", "a/d.go", "ab/e.go", "f.go"},
+		"a/**/*.go": {"a/b/c.go", "a/b/c/d.go", "a/d.go"},
+		"a/**/c":    {"a/b/c"},
+		"**/d.go":   {"a/b/c/d.go", "a/d.go"},
+	} {
+		got, err := fsys.Glob(pattern)
+		slices.Sort(got)
+		if err != nil || !slices.Equal(got, expected) {
+			t.Errorf("Glob(%q): expected %q, got %q (%v)", pattern, expected, got, err)
+		}
+	}
+	sub, err = fsys.Sub("a")
+	if err != nil {
+		t.Fatalf("Sub failed: %v\n", err)
+	}
+	if got, err := sub.(MemFS).Glob("**/*.go"); err != nil || !slices.Equal(got, []string{"b/c/d.go", "b/c.go", "d.go"}) {
+		t.Errorf("Glob in sub file system: unexpected %q (%v)", got, err)
+	}
+}
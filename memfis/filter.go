@@ -0,0 +1,179 @@
+package memfis
+
+import (
+	"io/fs"
+	"path"
+	"slices"
+	"syscall"
+)
+
+// filterFS hides files and directories of a MemFS.
+type filterFS struct {
+	fsys MemFS
+	keep func(path string, d fs.DirEntry) bool
+	// root is the directory in fsys used as "." for Sub
+	root string
+}
+
+var _ MemFS = (*filterFS)(nil)
+
+// Filter creates a MemFS showing only the files and directories of fsys for which keep reports true.
+// The path passed to keep is relative to the root of fsys.
+// A hidden directory hides all of its contents, directories without any visible contents
+// are pruned unless they are empty in fsys.
+//
+// fsys is accessed on every call, changes to it are visible in the filtered MemFS.
+func Filter(fsys MemFS, keep func(path string, d fs.DirEntry) bool) MemFS {
+	return &filterFS{
+		fsys: fsys,
+		keep: keep,
+		root: ".",
+	}
+}
+
+// full retrieves the path of name in fsys.
+func (f *filterFS) full(name string) string {
+	return path.Join(f.root, name)
+}
+
+// visible reports if the named path of fsys and all its parents are kept.
+// It retrieves the FileInfo of name if it is visible.
+func (f *filterFS) visible(name string) (fs.FileInfo, bool) {
+	info, err := f.fsys.Stat(name)
+	if err != nil {
+		return nil, false
+	}
+	if name == "." {
+		return info, true
+	}
+	for i := 0; i < len(name); i++ {
+		if name[i] != pathSeparator {
+			continue
+		}
+		parent, err := f.fsys.Stat(name[:i])
+		if err != nil || !f.keep(name[:i], fs.FileInfoToDirEntry(parent)) {
+			return nil, false
+		}
+	}
+	if !f.keep(name, fs.FileInfoToDirEntry(info)) {
+		return nil, false
+	}
+	if info.IsDir() && !f.hasContent(name) {
+		return nil, false
+	}
+	return info, true
+}
+
+// hasContent reports if the named kept directory of fsys has any visible contents or is empty in fsys.
+func (f *filterFS) hasContent(dir string) bool {
+	entries, err := f.fsys.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	if len(entries) == 0 {
+		return true
+	}
+	for _, e := range entries {
+		name := path.Join(dir, e.Name())
+		if f.keep(name, e) && (!e.IsDir() || f.hasContent(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// entries retrieves the visible entries of a visible directory.
+func (f *filterFS) entries(dir string) ([]fs.DirEntry, error) {
+	entries, err := f.fsys.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(entries, func(e fs.DirEntry) bool {
+		name := path.Join(dir, e.Name())
+		return !f.keep(name, e) || e.IsDir() && !f.hasContent(name)
+	}), nil
+}
+
+func (f *filterFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, fsPathError("open", name, fs.ErrInvalid)
+	}
+	full := f.full(name)
+	info, ok := f.visible(full)
+	if !ok {
+		return nil, fsPathError("open", name, fs.ErrNotExist)
+	}
+	if !info.IsDir() {
+		return f.fsys.Open(full)
+	}
+	entries, err := f.entries(full)
+	if err != nil {
+		return nil, err
+	}
+	return &listedDir{
+		name:    name,
+		info:    info,
+		entries: entries,
+	}, nil
+}
+
+func (f *filterFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, fsPathError("stat", name, fs.ErrInvalid)
+	}
+	info, ok := f.visible(f.full(name))
+	if !ok {
+		return nil, fsPathError("stat", name, fs.ErrNotExist)
+	}
+	return info, nil
+}
+
+func (f *filterFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, fsPathError("readfile", name, fs.ErrInvalid)
+	}
+	full := f.full(name)
+	info, ok := f.visible(full)
+	if !ok {
+		return nil, fsPathError("readfile", name, fs.ErrNotExist)
+	}
+	if info.IsDir() {
+		return nil, fsPathError("readfile", name, syscall.EISDIR)
+	}
+	return f.fsys.ReadFile(full)
+}
+
+func (f *filterFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, fsPathError("readdir", name, fs.ErrInvalid)
+	}
+	full := f.full(name)
+	info, ok := f.visible(full)
+	if !ok {
+		return nil, fsPathError("readdir", name, fs.ErrNotExist)
+	}
+	if !info.IsDir() {
+		return nil, fsPathError("readdir", name, syscall.ENOTDIR)
+	}
+	return f.entries(full)
+}
+
+func (f *filterFS) Glob(pattern string) ([]string, error) {
+	// hide Glob from fs.Glob to use the generic implementation based on ReadDir
+	return fs.Glob(noGlobFS{f}, pattern)
+}
+
+func (f *filterFS) Sub(dir string) (fs.FS, error) {
+	if !fs.ValidPath(dir) {
+		return nil, fsPathError("sub", dir, fs.ErrInvalid)
+	}
+	full := f.full(dir)
+	if info, ok := f.visible(full); !ok || !info.IsDir() {
+		return nil, fsPathError("sub", dir, fs.ErrNotExist)
+	}
+	return &filterFS{
+		fsys: f.fsys,
+		keep: f.keep,
+		root: full,
+	}, nil
+}
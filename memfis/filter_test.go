@@ -0,0 +1,54 @@
+package memfis
+
+import (
+	"io/fs"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFilter(t *testing.T) {
+	fsys, err := FromMap(map[string]string{
+		"a/a.go":        "package a",
+		"a/a_test.go":   "package a",
+		"b/b_test.go":   "package b",
+		"empty/":        "",
+		"secrets/key":   "secret",
+		"secrets/x.go":  "package x",
+		"c/d/e/f.go":    "package f",
+		"c/d/g_test.go": "package g",
+	})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	filtered := Filter(fsys, func(name string, d fs.DirEntry) bool {
+		return name != "secrets" && !strings.HasSuffix(name, "_test.go")
+	})
+	if err := fstest.TestFS(filtered, "a/a.go", "c/d/e/f.go", "empty"); err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+	for _, name := range []string{"a/a_test.go", "b", "b/b_test.go", "secrets", "secrets/x.go", "c/d/g_test.go"} {
+		if _, err := filtered.Stat(name); err == nil {
+			t.Errorf("hidden %q is visible", name)
+		}
+	}
+	entries, err := filtered.ReadDir(".")
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if !slices.Equal(names, []string{"a", "c", "empty"}) {
+		t.Errorf("unexpected entries %q", names)
+	}
+	sub, err := filtered.Sub("c")
+	if err != nil {
+		t.Fatalf("Sub failed: %v", err)
+	}
+	if err := fstest.TestFS(sub, "d/e/f.go"); err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package memfis
+
+import (
+	"bytes"
+	"io/fs"
+	"path"
+)
+
+// FromMap creates a MemFS from a map of file names to contents.
+// Names ending in "/" with empty content are empty directories.
+func FromMap(files map[string]string) (MemFS, error) {
+	fs := make([]File, 0, len(files))
+	for name, content := range files {
+		fs = append(fs, entry{name: name, content: content})
+	}
+	return MakeMemFS(fs...)
+}
+
+// FromBytesMap is like FromMap for byte slice contents.
+// The contents are copied.
+func FromBytesMap(files map[string][]byte) (MemFS, error) {
+	fs := make([]File, 0, len(files))
+	for name, content := range files {
+		fs = append(fs, bytesEntry{name: name, content: bytes.Clone(content)})
+	}
+	return MakeMemFS(fs...)
+}
+
+// bytesEntry is a BytesFile created by FromBytesMap.
+type bytesEntry struct {
+	name    string
+	content []byte
+}
+
+var _ BytesFile = bytesEntry{}
+
+func (e bytesEntry) GetName() string {
+	return e.name
+}
+
+func (e bytesEntry) GetContent() string {
+	return string(e.content)
+}
+
+func (e bytesEntry) Bytes() []byte {
+	return e.content
+}
+
+// FromFS creates a MemFS containing a snapshot of the directory root in src,
+// e.g. an embed.FS or os.DirFS.
+// root is the "." of the created MemFS, file permissions and symbolic links are kept.
+//
+// If patterns are given, only files with a path relative to root matching
+// at least one of them (see path.Match) are included and empty directories are omitted.
+func FromFS(src fs.FS, root string, patterns ...string) (MemFS, error) {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fsPathError("fromfs", p, err)
+		}
+	}
+	sub, err := fs.Sub(src, root)
+	if err != nil {
+		return nil, err
+	}
+	var b Builder
+	err = fs.WalkDir(sub, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if len(patterns) > 0 {
+				return nil
+			}
+			return b.MkdirAll(name)
+		}
+		if len(patterns) > 0 && !matchAny(patterns, name) {
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			target, err := fs.ReadLink(sub, name)
+			if err != nil {
+				return err
+			}
+			return b.add(MakeSymlink(name, target))
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		content, err := fs.ReadFile(sub, name)
+		if err != nil {
+			return err
+		}
+		return b.add(entry{name: name, content: string(content), mode: info.Mode().Perm()})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.Freeze()
+}
+
+// matchAny reports if name matches any of the already validated patterns.
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package memfis
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func FuzzMakeMemFS(f *testing.F) {
+	// each line is a file name, names ending in "/" are empty directories
+	for _, seed := range []string{
+		"a",
+		"a/b\na/c\nd",
+		"a/b\na.txt\nabs/x\na/b/",
+		"a/\na/b",
+		"a\na/b",
+		"x/y/z/\nx/y.go\nx-y",
+		"./a\n../b\na//b\n/c",
+		"",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		var files []File
+		var expected []string
+		for _, name := range strings.Split(input, "\n") {
+			content := name
+			if isDir(name) {
+				content = ""
+			}
+			files = append(files, entry{name: name, content: content})
+			if name != "" {
+				expected = append(expected, fsPath(name))
+			}
+		}
+		fsys, err := MakeMemFS(files...)
+		if err != nil {
+			return
+		}
+		m := fsys.(*memFS)
+		for i := 1; i < len(m.files); i++ {
+			if comparePath(m.files[i-1].GetName(), m.files[i].GetName()) >= 0 {
+				t.Fatalf("files are not sorted: %q before %q", m.files[i-1].GetName(), m.files[i].GetName())
+			}
+			a, b := m.files[i-1].GetName(), m.files[i].GetName()
+			if c := commonPath(a, b); !strings.HasPrefix(a, c) || !strings.HasPrefix(b, c) || !isDir(c) {
+				t.Fatalf("invalid common path %q of %q and %q", c, a, b)
+			}
+		}
+		var prev string
+		seen := make(map[string]bool)
+		walk("", m.files, func(rootpath string) {
+			if seen[rootpath] {
+				t.Fatalf("walk reported %q twice", rootpath)
+			}
+			seen[rootpath] = true
+			if prev != "" && comparePath(prev, rootpath) >= 0 {
+				t.Fatalf("walk reported %q after %q", rootpath, prev)
+			}
+			prev = rootpath
+		})
+		if err := fstest.TestFS(fsys, expected...); err != nil {
+			t.Fatalf("file system test failed for %q: %v", input, err)
+		}
+	})
+}
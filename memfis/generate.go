@@ -0,0 +1,62 @@
+package memfis
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"io/fs"
+	"strconv"
+)
+
+// GenerateGo writes the source of a Go file in package pkg declaring the variable varName
+// as a MemFS with the files and empty directories of fsys, e.g. for content
+// that is produced by a program and cannot be embedded with //go:embed.
+// The contents are string literals passed to FromMap when the package is initialized.
+//
+// Symbolic links are replaced by copies of the files they point to, permissions are not kept.
+func GenerateGo(w io.Writer, pkg, varName string, fsys fs.FS) error {
+	if !token.IsIdentifier(pkg) {
+		return fmt.Errorf("generate: invalid package name %q", pkg)
+	}
+	if !token.IsIdentifier(varName) {
+		return fmt.Errorf("generate: invalid variable name %q", varName)
+	}
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by memfis.GenerateGo; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\n", pkg)
+	fmt.Fprintf(&src, "import \"github.com/arnehormann/goof/memfis\"\n\n")
+	fmt.Fprintf(&src, "var %s = func() memfis.MemFS {\n", varName)
+	fmt.Fprintf(&src, "fsys, err := memfis.FromMap(map[string]string{\n")
+	err := WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || name == "." {
+			return err
+		}
+		if d.IsDir() {
+			entries, err := fs.ReadDir(fsys, name)
+			if err != nil || len(entries) > 0 {
+				// parents of other entries are implicit
+				return err
+			}
+			fmt.Fprintf(&src, "%s: \"\",\n", strconv.Quote(name+"/"))
+			return nil
+		}
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&src, "%s: %s,\n", strconv.Quote(name), strconv.Quote(string(content)))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(&src, "})\nif err != nil {\npanic(err)\n}\nreturn fsys\n}()\n")
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(formatted)
+	return err
+}
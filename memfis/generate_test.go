@@ -0,0 +1,41 @@
+package memfis
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestGenerateGo(t *testing.T) {
+	fsys, err := MakeMemFS(
+		entry{name: "a/b", content: "Hello\n\"World\"\x00"},
+		entry{name: "empty/"},
+		MakeSymlink("link", "a/b"),
+	)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	var out strings.Builder
+	if err := GenerateGo(&out, "assets", "Files", fsys); err != nil {
+		t.Fatalf("GenerateGo failed: %v", err)
+	}
+	src := out.String()
+	if _, err := parser.ParseFile(token.NewFileSet(), "generated.go", src, 0); err != nil {
+		t.Fatalf("generated invalid source: %v\n%s", err, src)
+	}
+	for _, want := range []string{
+		"package assets\n",
+		"var Files = func() memfis.MemFS {",
+		`"a/b":    "Hello\n\"World\"\x00",`,
+		`"empty/": "",`,
+		`"link":   "Hello\n\"World\"\x00",`,
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated source does not contain %q:\n%s", want, src)
+		}
+	}
+	if err := GenerateGo(&out, "assets", "no-identifier", fsys); err == nil {
+		t.Errorf("accepted invalid variable name")
+	}
+}
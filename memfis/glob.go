@@ -0,0 +1,40 @@
+package memfis
+
+import (
+	"path"
+	"strings"
+)
+
+// doubleStar is the pattern element matching any number of path elements.
+const doubleStar = "**"
+
+// matchDoubleStar is path.Match with support for "**" as a complete pattern element.
+func matchDoubleStar(pattern, name string) (bool, error) {
+	if !strings.Contains(pattern, doubleStar) {
+		return path.Match(pattern, name)
+	}
+	return matchElements(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// matchElements matches the elements of a pattern against the elements of a path.
+func matchElements(pattern, name []string) (bool, error) {
+	for len(pattern) > 0 {
+		if pattern[0] == doubleStar {
+			// try all possible numbers of skipped elements, the shortest first
+			for skip := 0; skip <= len(name); skip++ {
+				if ok, err := matchElements(pattern[1:], name[skip:]); ok || err != nil {
+					return ok, err
+				}
+			}
+			return false, nil
+		}
+		if len(name) == 0 {
+			return false, nil
+		}
+		if ok, err := path.Match(pattern[0], name[0]); !ok || err != nil {
+			return false, err
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0, nil
+}
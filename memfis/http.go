@@ -0,0 +1,240 @@
+package memfis
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HandlerOptions configure the http.Handler created by HandlerOptions.Handler.
+// The zero value is usable, it is used by Handler.
+type HandlerOptions struct {
+	// Index is the name of the file served for directories, e.g. "index.html".
+	// Directories are not served if it is empty or the directory does not contain it.
+	Index string
+	// Precompressed enables serving the variants of a file with the additional extensions
+	// ".br" and ".gz" to clients accepting the encodings br and gzip.
+	Precompressed bool
+}
+
+// precompressed lists the supported encodings of variants in order of preference.
+var precompressed = []struct {
+	encoding, ext string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// Handler creates an http.Handler serving the files in fsys with the default HandlerOptions.
+func Handler(fsys fs.FS) http.Handler {
+	return HandlerOptions{}.Handler(fsys)
+}
+
+// Handler creates an http.Handler serving the files in fsys.
+// Responses have a strong ETag derived from the checksum of the content,
+// conditional and range requests are handled by http.ServeContent.
+// The checksum is taken from the Metadata of a FileHasher or computed once and cached
+// until the size or modification time of the file changes or, for a MemWriteFS, until any file changes.
+func (o HandlerOptions) Handler(fsys fs.FS) http.Handler {
+	return &handler{
+		fsys: fsys,
+		opts: o,
+	}
+}
+
+type handler struct {
+	fsys fs.FS
+	opts HandlerOptions
+
+	mu sync.Mutex
+	// etags caches the computed ETags by file name
+	etags map[string]cachedETag
+}
+
+// cachedETag is a computed ETag and the state of the file it is valid for.
+type cachedETag struct {
+	size    int64
+	modTime time.Time
+	changes uint64
+	tag     string
+}
+
+// changeCounter is a file system counting its changes.
+// The files in it are only changed if the count changes.
+type changeCounter interface {
+	changeCount() uint64
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" {
+		name = "."
+	}
+	// taken first, a change after it invalidates the ETag computed below
+	changes, counted := uint64(0), false
+	if cc, ok := h.fsys.(changeCounter); ok {
+		changes, counted = cc.changeCount(), true
+	}
+	info, err := fs.Stat(h.fsys, name)
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	if info.IsDir() {
+		if h.opts.Index == "" {
+			http.NotFound(w, r)
+			return
+		}
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			// relative links in the index file require the trailing slash
+			http.Redirect(w, r, path.Base(r.URL.Path)+"/", http.StatusMovedPermanently)
+			return
+		}
+		name = path.Join(name, h.opts.Index)
+	}
+	contentType := mime.TypeByExtension(path.Ext(name))
+	served := name
+	if h.opts.Precompressed {
+		w.Header().Add("Vary", "Accept-Encoding")
+		accepted := r.Header.Get("Accept-Encoding")
+		for _, p := range precompressed {
+			if !acceptsEncoding(accepted, p.encoding) {
+				continue
+			}
+			if info, err := fs.Stat(h.fsys, name+p.ext); err == nil && !info.IsDir() {
+				served = name + p.ext
+				w.Header().Set("Content-Encoding", p.encoding)
+				break
+			}
+		}
+	}
+	f, err := h.fsys.Open(served)
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	defer f.Close()
+	info, err = f.Stat()
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			httpError(w, err)
+			return
+		}
+		content = bytes.NewReader(data)
+	}
+	if contentType == "" && served != name {
+		// prevent content sniffing of compressed variants
+		contentType = sniffType(h.fsys, name)
+	}
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	tag, err := h.etag(served, info, content, changes, counted)
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	w.Header().Set("Etag", tag)
+	http.ServeContent(w, r, name, info.ModTime(), content)
+}
+
+// etag retrieves a strong ETag for a file, using its checksum if it is known.
+// Otherwise, the sha256 checksum of content is computed and cached if the file has a modification time
+// or the file system counts its changes; content is read from the start afterwards.
+func (h *handler) etag(name string, info fs.FileInfo, content io.ReadSeeker, changes uint64, counted bool) (string, error) {
+	if md, ok := info.Sys().(*Metadata); ok && md.Checksum != nil {
+		if md.Checksum.Algorithm == "sha256" {
+			return `"` + hex.EncodeToString(md.Checksum.Sum) + `"`, nil
+		}
+		return `"` + md.Checksum.String() + `"`, nil
+	}
+	state := cachedETag{size: info.Size(), modTime: info.ModTime(), changes: changes}
+	cacheable := counted || !state.modTime.IsZero()
+	if cacheable {
+		h.mu.Lock()
+		cached, ok := h.etags[name]
+		h.mu.Unlock()
+		if ok && cached.size == state.size && cached.modTime.Equal(state.modTime) && cached.changes == state.changes {
+			return cached.tag, nil
+		}
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	state.tag = `"` + hex.EncodeToString(hash.Sum(nil)) + `"`
+	if cacheable {
+		h.mu.Lock()
+		if h.etags == nil {
+			h.etags = make(map[string]cachedETag)
+		}
+		h.etags[name] = state
+		h.mu.Unlock()
+	}
+	return state.tag, nil
+}
+
+// sniffType detects the content type of the named file from its first 512 bytes like http.ServeContent.
+func sniffType(fsys fs.FS, name string) string {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	defer f.Close()
+	var buf [512]byte
+	n, _ := io.ReadFull(f, buf[:])
+	return http.DetectContentType(buf[:n])
+}
+
+// acceptsEncoding reports if the value of an Accept-Encoding header accepts encoding.
+func acceptsEncoding(header, encoding string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(name), encoding) {
+			continue
+		}
+		q, found := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		if !found {
+			return true
+		}
+		weight, err := strconv.ParseFloat(q, 64)
+		return err == nil && weight > 0
+	}
+	return false
+}
+
+// httpError reports err with a matching status code.
+func httpError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	case errors.Is(err, fs.ErrPermission):
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	default:
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,192 @@
+package memfis
+
+import (
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	fsys, err := FromMap(map[string]string{
+		"index.html":      "<h1>Hi</h1>",
+		"app.js":          "console.log('Hi')",
+		"app.js.gz":       "gzipped",
+		"app.js.br":       "brotli",
+		"docs/index.html": "docs",
+		"raw/data.txt":    "data",
+	})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	h := HandlerOptions{Index: "index.html", Precompressed: true}.Handler(fsys)
+	get := func(target string, header ...string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			r.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	cases := []struct {
+		target   string
+		header   []string
+		status   int
+		body     string
+		encoding string
+	}{
+		{"/", nil, http.StatusOK, "<h1>Hi</h1>", ""},
+		{"/docs/", nil, http.StatusOK, "docs", ""},
+		{"/docs", nil, http.StatusMovedPermanently, "", ""},
+		{"/raw/", nil, http.StatusNotFound, "", ""},
+		{"/missing", nil, http.StatusNotFound, "", ""},
+		{"/../raw/data.txt", nil, http.StatusOK, "data", ""},
+		{"/app.js", nil, http.StatusOK, "console.log('Hi')", ""},
+		{"/app.js", []string{"Accept-Encoding", "gzip, deflate"}, http.StatusOK, "gzipped", "gzip"},
+		{"/app.js", []string{"Accept-Encoding", "gzip, br"}, http.StatusOK, "brotli", "br"},
+		{"/app.js", []string{"Accept-Encoding", "br;q=0, gzip;q=0.5"}, http.StatusOK, "gzipped", "gzip"},
+	}
+	for _, c := range cases {
+		w := get(c.target, c.header...)
+		if w.Code != c.status {
+			t.Errorf("%s %v: expected status %d, got %d", c.target, c.header, c.status, w.Code)
+			continue
+		}
+		if c.status != http.StatusOK {
+			continue
+		}
+		if body := w.Body.String(); body != c.body {
+			t.Errorf("%s %v: expected body %q, got %q", c.target, c.header, c.body, body)
+		}
+		if enc := w.Header().Get("Content-Encoding"); enc != c.encoding {
+			t.Errorf("%s %v: expected encoding %q, got %q", c.target, c.header, c.encoding, enc)
+		}
+	}
+	w := get("/app.js", "Accept-Encoding", "gzip")
+	if ct := w.Header().Get("Content-Type"); ct != "text/javascript; charset=utf-8" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	etag := w.Header().Get("Etag")
+	if etag == "" {
+		t.Fatalf("missing ETag")
+	}
+	if w := get("/app.js", "Accept-Encoding", "gzip", "If-None-Match", etag); w.Code != http.StatusNotModified {
+		t.Errorf("expected status %d for matching ETag, got %d", http.StatusNotModified, w.Code)
+	}
+	if w := get("/app.js", "If-None-Match", etag); w.Code != http.StatusOK {
+		t.Errorf("expected status %d for the ETag of another variant, got %d", http.StatusOK, w.Code)
+	}
+	if w := get("/"); w.Header().Get("Etag") == etag {
+		t.Errorf("same ETag for different contents")
+	}
+}
+
+// readCounter counts the bytes read from the files of an fs.FS.
+type readCounter struct {
+	fs.FS
+	n int
+}
+
+func (c *readCounter) Open(name string) (fs.File, error) {
+	f, err := c.FS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &countedFile{File: f, c: c}, nil
+}
+
+type countedFile struct {
+	fs.File
+	c *readCounter
+}
+
+func (f *countedFile) Read(p []byte) (int, error) {
+	n, err := f.File.Read(p)
+	f.c.n += n
+	return n, err
+}
+
+func (f *countedFile) Seek(offset int64, whence int) (int64, error) {
+	return f.File.(io.Seeker).Seek(offset, whence)
+}
+
+func TestHandlerETagCache(t *testing.T) {
+	mapFS := fstest.MapFS{"a.txt": {Data: []byte("first"), ModTime: time.Unix(1, 0)}}
+	fsys := &readCounter{FS: mapFS}
+	h := Handler(fsys)
+	serve := func(method string, header ...string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest(method, "/a.txt", nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			r.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	etag := serve(http.MethodGet).Header().Get("Etag")
+	fsys.n = 0
+	if w := serve(http.MethodHead); w.Header().Get("Etag") != etag {
+		t.Errorf("ETag changed from %s to %s", etag, w.Header().Get("Etag"))
+	}
+	if w := serve(http.MethodGet, "If-None-Match", etag); w.Code != http.StatusNotModified {
+		t.Errorf("expected status %d for matching ETag, got %d", http.StatusNotModified, w.Code)
+	}
+	if fsys.n != 0 {
+		t.Errorf("read %d bytes for cached ETag", fsys.n)
+	}
+	mapFS["a.txt"] = &fstest.MapFile{Data: []byte("other"), ModTime: time.Unix(2, 0)}
+	if w := serve(http.MethodGet, "If-None-Match", etag); w.Code != http.StatusOK || w.Body.String() != "other" {
+		t.Errorf("changed file: got status %d and %q", w.Code, w.Body.String())
+	}
+}
+
+func TestHandlerETagMemWriteFS(t *testing.T) {
+	w, err := MakeMemWriteFS(entry{name: "a.txt", content: "first"})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	h := Handler(w)
+	etag := func() string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/a.txt", nil))
+		return rec.Header().Get("Etag")
+	}
+	before := etag()
+	if again := etag(); again != before {
+		t.Errorf("ETag changed from %s to %s", before, again)
+	}
+	f, err := w.Create("a.txt")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	io.WriteString(f, "other")
+	f.Close()
+	if after := etag(); after == before {
+		t.Errorf("same ETag %s after the content changed", after)
+	}
+}
+
+func TestHandlerPrecompressedType(t *testing.T) {
+	fsys, err := FromMap(map[string]string{
+		"page":    "<html><body>Hi</body></html>",
+		"page.gz": "\x1f\x8b\x08\x00compressed",
+	})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	h := HandlerOptions{Precompressed: true}.Handler(fsys)
+	r := httptest.NewRequest(http.MethodGet, "/page", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+}
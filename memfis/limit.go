@@ -0,0 +1,63 @@
+package memfis
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LimitError reports that creating or changing a file system would exceed a limit set in Options.
+type LimitError struct {
+	Op   string
+	Path string
+	// Limit is the name of the exceeded field in Options, e.g. "MaxBytes".
+	Limit string
+	Max   int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s %s: exceeds %s of %d", e.Op, e.Path, e.Limit, e.Max)
+}
+
+// depth retrieves the number of elements in a rootpath.
+func depth(rootpath string) int {
+	return strings.Count(strings.TrimSuffix(rootpath, string(pathSeparator)), string(pathSeparator)) + 1
+}
+
+// isLink reports if f is a symbolic link.
+func isLink(f File) bool {
+	_, ok := linkTarget(f)
+	return ok
+}
+
+// usage retrieves the size and the number of the files and symbolic links in files.
+func usage(files []File) (bytes int64, count int) {
+	for _, f := range files {
+		if isDir(f.GetName()) {
+			continue
+		}
+		count++
+		if !isLink(f) {
+			bytes += fileSize(f)
+		}
+	}
+	return bytes, count
+}
+
+// checkDepth reports a LimitError if rootpath has more elements than allowed.
+func (o Options) checkDepth(op, rootpath string) error {
+	if o.MaxDepth > 0 && depth(rootpath) > o.MaxDepth {
+		return &LimitError{Op: op, Path: fsPath(rootpath), Limit: "MaxDepth", Max: int64(o.MaxDepth)}
+	}
+	return nil
+}
+
+// checkUsage reports a LimitError if the size or number of files is larger than allowed.
+func (o Options) checkUsage(op, name string, bytes int64, count int) error {
+	if o.MaxBytes > 0 && bytes > o.MaxBytes {
+		return &LimitError{Op: op, Path: name, Limit: "MaxBytes", Max: o.MaxBytes}
+	}
+	if o.MaxFiles > 0 && count > o.MaxFiles {
+		return &LimitError{Op: op, Path: name, Limit: "MaxFiles", Max: int64(o.MaxFiles)}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package memfis
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	opts := Options{MaxBytes: 10, MaxFiles: 3, MaxDepth: 2}
+	// expectLimit fails unless err is a LimitError for limit
+	expectLimit := func(err error, limit string) {
+		t.Helper()
+		var le *LimitError
+		if !errors.As(err, &le) || le.Limit != limit {
+			t.Errorf("expected %s to be exceeded, got %v", limit, err)
+		}
+	}
+	_, err := opts.MakeMemFS(entry{name: "a", content: "01234567890"})
+	expectLimit(err, "MaxBytes")
+	_, err = opts.MakeMemFS(entry{name: "a"}, entry{name: "b"}, entry{name: "c"}, entry{name: "d"})
+	expectLimit(err, "MaxFiles")
+	_, err = opts.MakeMemFS(entry{name: "a/b/c"})
+	expectLimit(err, "MaxDepth")
+	_, err = opts.MakeMemFS(entry{name: "a/b/"}, entry{name: "c"}, MakeSymlink("l", "c"))
+	if err != nil {
+		t.Fatalf("file system creation within limits failed: %v", err)
+	}
+
+	w, err := opts.MakeMemWriteFS(entry{name: "a/b", content: "12345"})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	f, err := w.Create("c")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := io.WriteString(f, "12345"); err != nil {
+		t.Fatalf("write within limits failed: %v", err)
+	}
+	_, err = io.WriteString(f, "6")
+	expectLimit(err, "MaxBytes")
+	expectLimit(f.Truncate(6), "MaxBytes")
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if data, err := w.ReadFile("c"); err != nil || string(data) != "12345" {
+		t.Errorf("failed write changed content to %q (%v)", data, err)
+	}
+	f, err = w.OpenFile("c", os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	if _, err := io.WriteString(f, "12345"); err != nil {
+		t.Errorf("write after truncation failed: %v", err)
+	}
+	f.Close()
+	_, err = w.Create("d")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	_, err = w.Create("e")
+	expectLimit(err, "MaxFiles")
+	if err := w.Remove("d"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := w.Create("e"); err != nil {
+		t.Errorf("Create after Remove failed: %v", err)
+	}
+	expectLimit(w.MkdirAll("x/y/z", 0o750), "MaxDepth")
+	if err := w.Mkdir("x", 0o750); err != nil {
+		t.Fatalf("Mkdir failed: %v", err)
+	}
+	if err := w.Rename("a", "x/a"); err == nil {
+		t.Errorf("moved a/b to x/a/b despite MaxDepth")
+	}
+	if err := w.Rename("e", "c"); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if _, err := w.Create("f"); err != nil {
+		t.Errorf("Create after replacing Rename failed: %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package memfis
+
+import (
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// ListAfter retrieves up to n entries of the directory dir with names sorted after the name after.
+// All remaining entries are retrieved if n <= 0, an empty after starts with the first entry.
+//
+// Unlike ReadDirFile.ReadDir, the position in the directory is not hidden state:
+// the name of the last retrieved entry is a cursor to continue the listing
+// in later calls or even in other processes.
+func ListAfter(fsys fs.FS, dir, after string, n int) ([]fs.DirEntry, error) {
+	if w, ok := fsys.(*memWriteFS); ok {
+		fsys = w.view()
+	}
+	m, ok := fsys.(*memFS)
+	if !ok {
+		return listAfter(fsys, dir, after, n)
+	}
+	_, d, err := m.follow(m.root(dir), true)
+	if err != nil || d == nil {
+		return nil, fsPathError("listafter", dir, fs.ErrNotExist)
+	}
+	rpl := len(d.rootpath)
+	start := sort.Search(len(d.files), func(i int) bool {
+		next := nextSegment(d.files[i].GetName()[rpl:])
+		return strings.TrimSuffix(next, string(pathSeparator)) > after
+	})
+	entries, _, err := d.dirEntries(nil, dirCursor{idx: start}, n)
+	if err == io.EOF {
+		// no entries after the cursor
+		return entries, nil
+	}
+	return entries, err
+}
+
+// listAfter is ListAfter for other file systems.
+func listAfter(fsys fs.FS, dir, after string, n int) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	entries = entries[sort.Search(len(entries), func(i int) bool {
+		return entries[i].Name() > after
+	}):]
+	if n > 0 && len(entries) > n {
+		entries = entries[:n]
+	}
+	return entries, nil
+}
@@ -0,0 +1,59 @@
+package memfis
+
+import (
+	"io/fs"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestListAfter(t *testing.T) {
+	files := map[string]string{
+		"d/a":     "",
+		"d/b/c":   "",
+		"d/b.txt": "",
+		"d/c/":    "",
+		"d/d":     "",
+		"d/e":     "",
+		"other":   "",
+	}
+	fsys, err := FromMap(files)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	reference := fstest.MapFS{}
+	for name := range files {
+		reference[name] = &fstest.MapFile{}
+	}
+	reference["d/c"] = &fstest.MapFile{Mode: fs.ModeDir}
+	delete(reference, "d/c/")
+
+	for _, fsys := range []fs.FS{fsys, reference} {
+		// paginate in steps of two
+		var names []string
+		after := ""
+		for {
+			entries, err := ListAfter(fsys, "d", after, 2)
+			if err != nil {
+				t.Fatalf("ListAfter failed: %v", err)
+			}
+			if len(entries) == 0 {
+				break
+			}
+			for _, e := range entries {
+				names = append(names, e.Name())
+			}
+			after = entries[len(entries)-1].Name()
+		}
+		if expected := []string{"a", "b", "b.txt", "c", "d", "e"}; !slices.Equal(names, expected) {
+			t.Errorf("%T: expected %q, got %q", fsys, expected, names)
+		}
+		entries, err := ListAfter(fsys, "d", "b.", 0)
+		if err != nil || len(entries) != 4 || entries[0].Name() != "b.txt" {
+			t.Errorf("%T: unexpected entries %v (%v)", fsys, entries, err)
+		}
+		if _, err := ListAfter(fsys, "missing", "", 0); err == nil {
+			t.Errorf("%T: listed a missing directory", fsys)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package memfis
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/fs"
+)
+
+// marshalMagic starts every marshaled file system, the last byte is the format version.
+const marshalMagic = "memfis\x00\x01"
+
+// kinds of marshaled entries
+const (
+	kindFile byte = iota
+	kindDir
+	kindSymlink
+)
+
+var errCorrupt = errors.New("memfis: corrupt marshaled file system")
+
+// Marshal serializes all files, empty directories and symbolic links of fsys with their permissions
+// into a compact binary form that can be loaded with Unmarshal.
+//
+// The format starts with a header followed by one record per entry:
+// the kind, the permissions and the length-prefixed name and content (the target for symbolic links).
+// All numbers are unsigned varints.
+func Marshal(fsys fs.FS) ([]byte, error) {
+	buf := []byte(marshalMagic)
+	appendString := func(s string) {
+		buf = binary.AppendUvarint(buf, uint64(len(s)))
+		buf = append(buf, s...)
+	}
+	err := WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || name == "." {
+			return err
+		}
+		var kind byte
+		var content string
+		switch {
+		case d.IsDir():
+			entries, err := fs.ReadDir(fsys, name)
+			if err != nil || len(entries) > 0 {
+				// parents of other entries are implicit
+				return err
+			}
+			kind = kindDir
+		case d.Type()&fs.ModeSymlink != 0:
+			target, err := fs.ReadLink(fsys, name)
+			if err != nil {
+				return err
+			}
+			kind, content = kindSymlink, target
+		default:
+			data, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				return err
+			}
+			kind, content = kindFile, string(data)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		buf = append(buf, kind)
+		buf = binary.AppendUvarint(buf, uint64(info.Mode().Perm()))
+		appendString(name)
+		appendString(content)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// Unmarshal loads a file system serialized by Marshal.
+// The contents are copied, data can be reused afterwards.
+func Unmarshal(data []byte) (MemFS, error) {
+	rest, ok := bytes.CutPrefix(data, []byte(marshalMagic))
+	if !ok {
+		return nil, errCorrupt
+	}
+	readUvarint := func() (uint64, bool) {
+		v, n := binary.Uvarint(rest)
+		if n <= 0 {
+			return 0, false
+		}
+		rest = rest[n:]
+		return v, true
+	}
+	readString := func() (string, bool) {
+		n, ok := readUvarint()
+		if !ok || n > uint64(len(rest)) {
+			return "", false
+		}
+		s := string(rest[:n])
+		rest = rest[n:]
+		return s, true
+	}
+	var files []File
+	for len(rest) > 0 {
+		kind := rest[0]
+		rest = rest[1:]
+		mode, ok := readUvarint()
+		if !ok {
+			return nil, errCorrupt
+		}
+		name, ok := readString()
+		if !ok {
+			return nil, errCorrupt
+		}
+		content, ok := readString()
+		if !ok {
+			return nil, errCorrupt
+		}
+		switch kind {
+		case kindFile:
+			files = append(files, entry{name: name, content: content, mode: fs.FileMode(mode).Perm()})
+		case kindDir:
+			files = append(files, entry{name: toDir(name)})
+		case kindSymlink:
+			files = append(files, MakeSymlink(name, content))
+		default:
+			return nil, errCorrupt
+		}
+	}
+	return MakeMemFS(files...)
+}
@@ -0,0 +1,51 @@
+package memfis
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMarshal(t *testing.T) {
+	fsys, err := MakeMemFS(
+		entry{name: "a/b", content: "Hello"},
+		entry{name: "a/run", content: "#!/bin/sh", mode: 0o755},
+		entry{name: "empty/"},
+		entry{name: "c", content: ""},
+		MakeSymlink("link", "a/b"),
+	)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	data, err := Marshal(fsys)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	loaded, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if err := fstest.TestFS(loaded, "a/b", "a/run", "empty", "c", "link"); err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+	if content, err := loaded.ReadFile("link"); err != nil || string(content) != "Hello" {
+		t.Errorf("unexpected content %q behind link (%v)", content, err)
+	}
+	if info, err := loaded.Stat("a/run"); err != nil || info.Mode() != 0o755 {
+		t.Errorf("permissions were not kept: %v (%v)", info.Mode(), err)
+	}
+	if again, err := Marshal(loaded); err != nil || string(again) != string(data) {
+		t.Errorf("marshaling is not deterministic (%v)", err)
+	}
+	for i := range data {
+		// truncated data must not panic
+		Unmarshal(data[:i])
+	}
+	if _, err := Unmarshal([]byte("garbage")); err == nil {
+		t.Errorf("loaded garbage")
+	}
+	if _, err := Unmarshal(data[:len(data)-1]); err == nil {
+		t.Errorf("loaded truncated data")
+	}
+	var _ fs.FS = loaded
+}
@@ -0,0 +1,224 @@
+package memfistest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+// invalidPaths are rejected by every file system, see fs.ValidPath.
+var invalidPaths = []string{"/", "/a", "a/", "a//b", "./a", "a/./b", "../a", "a/../b"}
+
+// Conformance checks fsys with fstest.TestFS and expected
+// and additionally checks behavior not covered by it, as observed on os.File by cmd/fsdirtester:
+//
+//   - reading a directory in pages of one entry yields the same entries as a single ReadDir(-1),
+//     after the last entry ReadDir(n > 0) reports io.EOF and ReadDir(-1) an empty list without error
+//   - Seek(0, io.SeekStart) on directories and files implementing io.Seeker starts reading again
+//   - Read, Stat and ReadDir fail on closed files and directories
+//   - Stat of an open file matches fs.Stat and the entry of its directory,
+//     missing paths are reported with fs.ErrNotExist
+//   - Open and Stat reject invalid paths, e.g. "a/" or "../a"
+func Conformance(t testing.TB, fsys fs.FS, expected ...string) {
+	t.Helper()
+	if err := fstest.TestFS(fsys, expected...); err != nil {
+		t.Errorf("fstest.TestFS: %v", err)
+	}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			t.Errorf("%s: walking failed: %v", name, err)
+			return nil
+		}
+		checkStat(t, fsys, name, d)
+		switch {
+		case d.IsDir():
+			checkDir(t, fsys, name)
+		case d.Type().IsRegular():
+			checkFile(t, fsys, name)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("walking failed: %v", err)
+	}
+	for _, name := range invalidPaths {
+		if _, err := fsys.Open(name); !isRejected(err) {
+			t.Errorf("%s: Open got error %v, want fs.ErrInvalid or fs.ErrNotExist", name, err)
+		}
+		if _, err := fs.Stat(fsys, name); !isRejected(err) {
+			t.Errorf("%s: Stat got error %v, want fs.ErrInvalid or fs.ErrNotExist", name, err)
+		}
+	}
+	const missing = "memfistest-missing"
+	if _, err := fs.Stat(fsys, missing); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("%s: Stat got error %v, want fs.ErrNotExist", missing, err)
+	}
+	var pe *fs.PathError
+	if _, err := fsys.Open(missing); !errors.As(err, &pe) || !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("%s: Open got error %v, want *fs.PathError with fs.ErrNotExist", missing, err)
+	}
+}
+
+// isRejected reports if err is allowed by fs.FS for names not satisfying fs.ValidPath.
+func isRejected(err error) bool {
+	return errors.Is(err, fs.ErrInvalid) || errors.Is(err, fs.ErrNotExist)
+}
+
+// checkStat compares fs.Stat and Stat of the open file with the directory entry d of name.
+func checkStat(t testing.TB, fsys fs.FS, name string, d fs.DirEntry) {
+	t.Helper()
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		t.Errorf("%s: Stat failed: %v", name, err)
+		return
+	}
+	if want := path.Base(name); info.Name() != want {
+		t.Errorf("%s: Stat got name %q, want %q", name, info.Name(), want)
+	}
+	if d.Type()&fs.ModeSymlink != 0 {
+		// Stat follows symbolic links
+		return
+	}
+	if info.IsDir() != d.IsDir() || info.Mode().Type() != d.Type() {
+		t.Errorf("%s: Stat got mode %v, directory entry has type %v", name, info.Mode(), d.Type())
+	}
+	f, err := fsys.Open(name)
+	if err != nil {
+		t.Errorf("%s: Open failed: %v", name, err)
+		return
+	}
+	defer f.Close()
+	finfo, err := f.Stat()
+	if err != nil {
+		t.Errorf("%s: Stat of open file failed: %v", name, err)
+		return
+	}
+	if finfo.Name() != info.Name() || finfo.Mode() != info.Mode() || finfo.Size() != info.Size() {
+		t.Errorf("%s: Stat of open file got %s %v %d, fs.Stat got %s %v %d", name,
+			finfo.Name(), finfo.Mode(), finfo.Size(), info.Name(), info.Mode(), info.Size())
+	}
+}
+
+// entryNames retrieves the names of directory entries.
+func entryNames(entries []fs.DirEntry) []string {
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name()
+	}
+	return names
+}
+
+// checkDir checks ReadDir paging, Seek and the behavior after Close of the named directory.
+func checkDir(t testing.TB, fsys fs.FS, name string) {
+	t.Helper()
+	f, err := fsys.Open(name)
+	if err != nil {
+		t.Errorf("%s: Open failed: %v", name, err)
+		return
+	}
+	d, ok := f.(fs.ReadDirFile)
+	if !ok {
+		f.Close()
+		t.Errorf("%s: directory does not implement fs.ReadDirFile", name)
+		return
+	}
+	all, err := d.ReadDir(-1)
+	if err != nil {
+		t.Errorf("%s: ReadDir(-1) failed: %v", name, err)
+	}
+	want := entryNames(all)
+	if entries, err := d.ReadDir(-1); len(entries) != 0 || err != nil {
+		t.Errorf("%s: ReadDir(-1) at the end got %v, %v, want no entries and no error", name, entryNames(entries), err)
+	}
+	if entries, err := d.ReadDir(1); len(entries) != 0 || err != io.EOF {
+		t.Errorf("%s: ReadDir(1) at the end got %v, %v, want io.EOF", name, entryNames(entries), err)
+	}
+	if _, err := f.Read(make([]byte, 1)); err == nil || err == io.EOF {
+		t.Errorf("%s: Read of a directory got error %v, want a failure", name, err)
+	}
+	if s, ok := f.(io.Seeker); ok {
+		if pos, err := s.Seek(0, io.SeekStart); pos != 0 || err != nil {
+			t.Errorf("%s: Seek(0, io.SeekStart) got %d, %v", name, pos, err)
+		}
+		entries, err := d.ReadDir(-1)
+		if got := entryNames(entries); err != nil || !slices.Equal(got, want) {
+			t.Errorf("%s: ReadDir(-1) after Seek got %v, %v, want %v", name, got, err, want)
+		}
+	}
+	checkClosed(t, name, f)
+
+	f, err = fsys.Open(name)
+	if err != nil {
+		t.Errorf("%s: Open failed: %v", name, err)
+		return
+	}
+	defer f.Close()
+	d = f.(fs.ReadDirFile)
+	var paged []string
+	for range len(want) + 1 {
+		entries, err := d.ReadDir(1)
+		if err == io.EOF {
+			break
+		}
+		if err != nil || len(entries) != 1 {
+			t.Errorf("%s: ReadDir(1) got %v, %v, want one entry", name, entryNames(entries), err)
+			return
+		}
+		paged = append(paged, entries[0].Name())
+	}
+	if !slices.Equal(paged, want) {
+		t.Errorf("%s: ReadDir(1) got %v in pages, ReadDir(-1) got %v", name, paged, want)
+	}
+	if entries, err := d.ReadDir(-1); len(entries) != 0 || err != nil {
+		t.Errorf("%s: ReadDir(-1) after io.EOF got %v, %v, want no entries and no error", name, entryNames(entries), err)
+	}
+}
+
+// checkFile checks Seek and the behavior after Close of the named regular file.
+func checkFile(t testing.TB, fsys fs.FS, name string) {
+	t.Helper()
+	f, err := fsys.Open(name)
+	if err != nil {
+		t.Errorf("%s: Open failed: %v", name, err)
+		return
+	}
+	content, err := io.ReadAll(f)
+	if err != nil {
+		t.Errorf("%s: Read failed: %v", name, err)
+	}
+	if s, ok := f.(io.Seeker); ok {
+		if pos, err := s.Seek(0, io.SeekStart); pos != 0 || err != nil {
+			t.Errorf("%s: Seek(0, io.SeekStart) got %d, %v", name, pos, err)
+		}
+		again, err := io.ReadAll(f)
+		if err != nil || !bytes.Equal(again, content) {
+			t.Errorf("%s: Read after Seek got %q, %v, want %q", name, again, err, content)
+		}
+	}
+	checkClosed(t, name, f)
+}
+
+// checkClosed closes f and checks that using it fails afterwards.
+func checkClosed(t testing.TB, name string, f fs.File) {
+	t.Helper()
+	if err := f.Close(); err != nil {
+		t.Errorf("%s: Close failed: %v", name, err)
+		return
+	}
+	if n, err := f.Read(make([]byte, 1)); n != 0 || err == nil || err == io.EOF {
+		t.Errorf("%s: Read after Close got %d, %v, want a failure", name, n, err)
+	}
+	if _, err := f.Stat(); err == nil {
+		t.Errorf("%s: Stat after Close succeeded", name)
+	}
+	if d, ok := f.(fs.ReadDirFile); ok {
+		if _, err := d.ReadDir(-1); err == nil {
+			t.Errorf("%s: ReadDir after Close succeeded", name)
+		}
+	}
+}
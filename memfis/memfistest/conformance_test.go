@@ -0,0 +1,41 @@
+package memfistest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/arnehormann/goof/memfis"
+)
+
+func TestConformance(t *testing.T) {
+	fsys, err := memfis.FromMap(map[string]string{
+		"a/b":    "Hello",
+		"a/c/d":  "World",
+		"e":      "",
+		"empty/": "",
+	})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	Conformance(t, fsys, "a/b", "a/c/d", "e", "empty")
+
+	dir := filepath.Join(t.TempDir(), "dir")
+	if err := Update(dir, fsys); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	Conformance(t, os.DirFS(dir), "a/b", "a/c/d", "e", "empty")
+}
+
+func TestConformanceFailure(t *testing.T) {
+	// files of fstest.MapFS can still be used after Close
+	r := &recorder{TB: t}
+	Conformance(r, fstest.MapFS{"a/b": {Data: []byte("Hello")}}, "a/b")
+	if len(r.errors) == 0 {
+		t.Errorf("no errors reported for use after Close")
+	}
+	for _, e := range r.errors {
+		t.Log(e)
+	}
+}
@@ -0,0 +1,96 @@
+// Package memfistest compares file systems against golden directories for snapshot tests
+// and checks the conformance of file system implementations.
+//
+// Run the tests with -memfis.update to rewrite the golden directories with the current results:
+//
+//	go test ./... -args -memfis.update
+package memfistest
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arnehormann/goof/memfis"
+)
+
+var update = flag.Bool("memfis.update", false, "rewrite golden directories passed to memfistest.AssertEqualsDir")
+
+// AssertEqualsDir reports an error for every difference between fsys and the golden directory dir.
+// Permissions are not compared, they are not kept by all version control systems.
+// If the flag -memfis.update is set, dir is replaced by the contents of fsys instead.
+func AssertEqualsDir(t testing.TB, fsys fs.FS, dir string) {
+	t.Helper()
+	if *update {
+		if err := Update(dir, fsys); err != nil {
+			t.Fatalf("updating golden directory %s failed: %v", dir, err)
+		}
+		return
+	}
+	opts := memfis.DiffOptions{
+		Content:     describeContent,
+		IgnoreModes: true,
+	}
+	changes, err := opts.Diff(os.DirFS(dir), fsys)
+	if err != nil {
+		t.Fatalf("comparison with golden directory %s failed: %v", dir, err)
+	}
+	for _, c := range changes {
+		switch c.Kind {
+		case memfis.Added:
+			t.Errorf("%s: unexpected, it is not in the golden directory %s", c.Path, dir)
+		case memfis.Removed:
+			t.Errorf("%s: missing, it is in the golden directory %s", c.Path, dir)
+		default:
+			t.Errorf("%s: differs from the golden directory %s%s", c.Path, dir, c.Diff)
+		}
+	}
+}
+
+// describeContent describes different file contents for AssertEqualsDir.
+func describeContent(path string, golden, got []byte) string {
+	const maxLen = 200
+	if len(golden) > maxLen || len(got) > maxLen {
+		return fmt.Sprintf(", expected %d bytes, got %d", len(golden), len(got))
+	}
+	return fmt.Sprintf("\nexpected %q\ngot      %q", golden, got)
+}
+
+// Update replaces the contents of the directory dir with the files, directories and symbolic links of fsys.
+// dir is created if it does not exist.
+func Update(dir string, fsys fs.FS) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return memfis.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || name == "." {
+			return err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		switch {
+		case d.IsDir():
+			return os.Mkdir(target, 0o755)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := fs.ReadLink(fsys, name)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(filepath.FromSlash(link), target)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, content, info.Mode().Perm()|0o600)
+	})
+}
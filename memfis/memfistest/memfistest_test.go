@@ -0,0 +1,56 @@
+package memfistest
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/arnehormann/goof/memfis"
+)
+
+// recorder collects the errors reported to it.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertEqualsDir(t *testing.T) {
+	golden := filepath.Join(t.TempDir(), "golden")
+	fsys, err := memfis.FromMap(map[string]string{
+		"a/b":    "Hello",
+		"c":      "World",
+		"empty/": "",
+	})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	if err := Update(golden, fsys); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	AssertEqualsDir(t, fsys, golden)
+
+	changed, err := memfis.FromMap(map[string]string{
+		"a/b": "Hello",
+		"c":   "Changed",
+		"d":   "New",
+	})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	r := &recorder{TB: t}
+	AssertEqualsDir(r, changed, golden)
+	want := []string{
+		"c: differs from the golden directory " + golden + "\nexpected \"World\"\ngot      \"Changed\"",
+		"d: unexpected, it is not in the golden directory " + golden,
+		"empty: missing, it is in the golden directory " + golden,
+	}
+	if fmt.Sprint(r.errors) != fmt.Sprint(want) {
+		t.Errorf("expected errors\n%q\ngot\n%q", want, r.errors)
+	}
+}
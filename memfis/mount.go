@@ -0,0 +1,218 @@
+package memfis
+
+import (
+	"errors"
+	"io/fs"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// mountFS presents a file system at a path of another one.
+type mountFS struct {
+	base fs.FS
+	// at is the mount point in base, never "."
+	at  string
+	sub fs.FS
+}
+
+var _ MemFS = (*mountFS)(nil)
+
+// Mount creates a MemFS showing sub at the path at in base, e.g. to combine embedded static assets
+// with a dynamically generated tree.
+// Everything in base at or below at is hidden, directories leading to at are created as needed
+// and merged with the contents of base.
+//
+// Mount fails if at is not a valid path below "." or if a parent of at is not a directory in base.
+// base and sub are accessed on every call, changes to them are visible in the created MemFS.
+func Mount(base MemFS, at string, sub fs.FS) (MemFS, error) {
+	if !fs.ValidPath(at) || at == "." {
+		return nil, fsPathError("mount", at, fs.ErrInvalid)
+	}
+	for i := 0; i < len(at); i++ {
+		if at[i] != pathSeparator {
+			continue
+		}
+		if info, err := base.Stat(at[:i]); err == nil && !info.IsDir() {
+			return nil, fsPathError("mount", at, syscall.ENOTDIR)
+		}
+	}
+	return &mountFS{
+		base: base,
+		at:   at,
+		sub:  sub,
+	}, nil
+}
+
+// inside retrieves the path in sub for names at or below the mount point.
+func (m *mountFS) inside(name string) (string, bool) {
+	if name == m.at {
+		return ".", true
+	}
+	rel, ok := strings.CutPrefix(name, m.at+"/")
+	return rel, ok
+}
+
+// leadsTo reports if name is a directory containing the mount point.
+func (m *mountFS) leadsTo(name string) bool {
+	return name == "." || strings.HasPrefix(m.at, name+"/")
+}
+
+// next retrieves the directory in the named parent of the mount point leading to it.
+func (m *mountFS) next(name string) memDir {
+	o := 0
+	if name != "." {
+		o = len(name) + 1
+	}
+	return memDir{
+		rootpath: m.at[:o] + nextSegment(m.at[o:]+"/"),
+		pidx:     o,
+	}
+}
+
+// parentInfo retrieves the FileInfo of a directory containing the mount point.
+func (m *mountFS) parentInfo(name string) fs.FileInfo {
+	if info, err := fs.Stat(m.base, name); err == nil {
+		return info
+	}
+	return makeRootDir(toDir(name))
+}
+
+// parentEntries retrieves the merged entries of a directory containing the mount point.
+func (m *mountFS) parentEntries(name string) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(m.base, name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	next := m.next(name)
+	entries = slices.DeleteFunc(entries, func(e fs.DirEntry) bool {
+		// keep existing directories leading to the mount point, hide everything else of that name
+		return e.Name() == next.Name() && (!e.IsDir() || next.GetName() == toDir(m.at))
+	})
+	i, found := slices.BinarySearchFunc(entries, next.Name(), func(e fs.DirEntry, name string) int {
+		return strings.Compare(e.Name(), name)
+	})
+	if !found {
+		entries = slices.Insert(entries, i, fs.DirEntry(next))
+	}
+	return entries, nil
+}
+
+func (m *mountFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, fsPathError("open", name, fs.ErrInvalid)
+	}
+	if rel, ok := m.inside(name); ok {
+		if rel != "." {
+			return m.sub.Open(rel)
+		}
+		entries, err := fs.ReadDir(m.sub, ".")
+		if err != nil {
+			return nil, err
+		}
+		return &listedDir{
+			name:    name,
+			info:    makeRootDir(toDir(name)),
+			entries: entries,
+		}, nil
+	}
+	if !m.leadsTo(name) {
+		return m.base.Open(name)
+	}
+	entries, err := m.parentEntries(name)
+	if err != nil {
+		return nil, err
+	}
+	return &listedDir{
+		name:    name,
+		info:    m.parentInfo(name),
+		entries: entries,
+	}, nil
+}
+
+func (m *mountFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, fsPathError("stat", name, fs.ErrInvalid)
+	}
+	if rel, ok := m.inside(name); ok {
+		if rel == "." {
+			if _, err := fs.Stat(m.sub, "."); err != nil {
+				return nil, err
+			}
+			return makeRootDir(toDir(name)), nil
+		}
+		return fs.Stat(m.sub, rel)
+	}
+	if m.leadsTo(name) {
+		return m.parentInfo(name), nil
+	}
+	return fs.Stat(m.base, name)
+}
+
+func (m *mountFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, fsPathError("readfile", name, fs.ErrInvalid)
+	}
+	if rel, ok := m.inside(name); ok {
+		return fs.ReadFile(m.sub, rel)
+	}
+	if m.leadsTo(name) {
+		return nil, fsPathError("readfile", name, syscall.EISDIR)
+	}
+	return fs.ReadFile(m.base, name)
+}
+
+func (m *mountFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, fsPathError("readdir", name, fs.ErrInvalid)
+	}
+	if rel, ok := m.inside(name); ok {
+		return fs.ReadDir(m.sub, rel)
+	}
+	if m.leadsTo(name) {
+		return m.parentEntries(name)
+	}
+	return fs.ReadDir(m.base, name)
+}
+
+func (m *mountFS) Glob(pattern string) ([]string, error) {
+	// hide Glob from fs.Glob to use the generic implementation based on ReadDir
+	return fs.Glob(noGlobFS{m}, pattern)
+}
+
+func (m *mountFS) Sub(dir string) (fs.FS, error) {
+	if !fs.ValidPath(dir) {
+		return nil, fsPathError("sub", dir, fs.ErrInvalid)
+	}
+	if dir == "." {
+		return m, nil
+	}
+	if rel, ok := m.inside(dir); ok {
+		return fs.Sub(m.sub, rel)
+	}
+	if !m.leadsTo(dir) {
+		return fs.Sub(m.base, dir)
+	}
+	var base fs.FS
+	var err error
+	if m.exists(dir) {
+		base, err = fs.Sub(m.base, dir)
+	} else {
+		// the directory only leads to the mount point
+		base, err = MakeMemFS()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &mountFS{
+		base: base,
+		at:   m.at[len(dir)+1:],
+		sub:  m.sub,
+	}, nil
+}
+
+// exists reports if the named directory exists in base.
+func (m *mountFS) exists(dir string) bool {
+	info, err := fs.Stat(m.base, dir)
+	return err == nil && info.IsDir()
+}
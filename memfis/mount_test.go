@@ -0,0 +1,69 @@
+package memfis
+
+import (
+	"io/fs"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMount(t *testing.T) {
+	base, err := FromMap(map[string]string{
+		"index.html":       "<html>",
+		"static/style.css": "body{}",
+		"static/gen":       "hidden by the mount",
+	})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	sub, err := FromMap(map[string]string{
+		"a.js":     "a",
+		"lib/b.js": "b",
+	})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	mounted, err := Mount(base, "static/gen", sub)
+	if err != nil {
+		t.Fatalf("Mount failed: %v", err)
+	}
+	if err := fstest.TestFS(mounted, "index.html", "static/style.css", "static/gen/a.js", "static/gen/lib/b.js"); err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+	if content, err := mounted.ReadFile("static/gen/lib/b.js"); err != nil || string(content) != "b" {
+		t.Errorf("unexpected content %q (%v)", content, err)
+	}
+
+	deep, err := Mount(base, "x/y/z", sub)
+	if err != nil {
+		t.Fatalf("Mount failed: %v", err)
+	}
+	if err := fstest.TestFS(deep, "index.html", "static/gen", "x/y/z/a.js", "x/y/z/lib/b.js"); err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+	entries, err := deep.ReadDir(".")
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if !slices.Equal(names, []string{"index.html", "static", "x"}) {
+		t.Errorf("unexpected entries %q", names)
+	}
+	subfs, err := deep.Sub("x")
+	if err != nil {
+		t.Fatalf("Sub failed: %v", err)
+	}
+	if err := fstest.TestFS(subfs, "y/z/a.js", "y/z/lib/b.js"); err != nil {
+		t.Fatalf("sub file system test failed: %v", err)
+	}
+
+	for _, at := range []string{".", "/abs", "index.html/x"} {
+		if _, err := Mount(base, at, sub); err == nil {
+			t.Errorf("mounted at %q", at)
+		}
+	}
+	var _ fs.FS = mounted
+}
@@ -0,0 +1,44 @@
+package memfis
+
+import (
+	"errors"
+	"strings"
+)
+
+var errDotDot = errors.New("path contains .. element")
+
+// normalizes reports if o changes file names on creation.
+func (o Options) normalizes() bool {
+	return o.CleanPaths || o.Backslashes || o.ResolveDotDot
+}
+
+// normalize converts a file name according to o.
+// Names of directories keep their trailing "/".
+func (o Options) normalize(name string) (string, error) {
+	n := name
+	if o.Backslashes {
+		n = strings.ReplaceAll(n, `\`, "/")
+	}
+	dir := isDir(n)
+	var elems []string
+	for _, e := range strings.Split(strings.TrimSuffix(n, "/"), "/") {
+		switch {
+		case o.CleanPaths && (e == "" || e == "."):
+			continue
+		case e != "..":
+		case !o.ResolveDotDot:
+			return "", fsPathError("create", name, errDotDot)
+		case len(elems) == 0:
+			return "", fsPathError("create", name, errPathEscapes)
+		default:
+			elems = elems[:len(elems)-1]
+			continue
+		}
+		elems = append(elems, e)
+	}
+	n = strings.Join(elems, "/")
+	if dir && n != "" {
+		n += "/"
+	}
+	return n, nil
+}
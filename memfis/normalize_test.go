@@ -0,0 +1,42 @@
+package memfis
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNormalize(t *testing.T) {
+	opts := Options{CleanPaths: true, Backslashes: true, ResolveDotDot: true}
+	fsys, err := opts.MakeMemFS(
+		entry{name: "./a//b", content: "b"},
+		entry{name: `a\c`, content: "c"},
+		entry{name: "/d/../e", content: "e"},
+		entry{name: "./empty//"},
+		MakeSymlink("./link", "a/b"),
+	)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	if err := fstest.TestFS(fsys, "a/b", "a/c", "e", "empty", "link"); err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+	if data, err := fsys.ReadFile("link"); err != nil || string(data) != "b" {
+		t.Errorf("renamed link does not resolve: %q (%v)", data, err)
+	}
+	for _, c := range []struct {
+		opts Options
+		name string
+		err  error
+	}{
+		{opts, "a/../../b", errPathEscapes},
+		{Options{CleanPaths: true}, "a/../b", errDotDot},
+	} {
+		if _, err := c.opts.MakeMemFS(entry{name: c.name}); !errors.Is(err, c.err) {
+			t.Errorf("expected error %v for %q, got %v", c.err, c.name, err)
+		}
+	}
+	if _, err := MakeMemFS(entry{name: "./a"}); err == nil {
+		t.Errorf("accepted unclean name without options")
+	}
+}
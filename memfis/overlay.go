@@ -0,0 +1,420 @@
+package memfis
+
+import (
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// Overlay is a writable file system reading from a base file system.
+// All changes are recorded in memory, the base file system is never modified:
+// created and modified files are stored in an upper layer,
+// removed files and directories of the base file system are recorded as whiteouts.
+type Overlay struct {
+	base  fs.FS
+	upper *memWriteFS
+	// mu serializes changes
+	mu sync.RWMutex
+	// whiteouts contains removed paths of base.
+	// It is replaced on every change and never modified in place.
+	whiteouts map[string]bool
+}
+
+var _ MemWriteFS = (*Overlay)(nil)
+
+// NewOverlay creates an Overlay with base as its read only lower layer.
+func NewOverlay(base fs.FS) *Overlay {
+	return &Overlay{
+		base:      base,
+		upper:     &memWriteFS{},
+		whiteouts: map[string]bool{},
+	}
+}
+
+// Upper retrieves a MemFS containing all created and modified files and directories.
+func (o *Overlay) Upper() MemFS {
+	return o.upper.view()
+}
+
+// Whiteouts retrieves the sorted paths removed from the base file system.
+// Paths contained in removed directories are not listed.
+func (o *Overlay) Whiteouts() []string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	whiteouts := make([]string, 0, len(o.whiteouts))
+	for name := range o.whiteouts {
+		whiteouts = append(whiteouts, name)
+	}
+	slices.Sort(whiteouts)
+	return whiteouts
+}
+
+// view retrieves a read only file system of the current state.
+func (o *Overlay) view() MemFS {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.current()
+}
+
+// current is view for callers holding o.mu.
+func (o *Overlay) current() MemFS {
+	return Union(whiteoutFS{fsys: o.base, whiteouts: o.whiteouts}, o.upper.view())
+}
+
+// Snapshot retrieves a MemFS of the current state of the upper layer and the whiteouts.
+// The base file system is shared, changes to it are visible in the snapshot.
+func (o *Overlay) Snapshot() MemFS {
+	return o.view()
+}
+
+func (o *Overlay) Open(name string) (fs.File, error) {
+	return o.view().Open(name)
+}
+
+func (o *Overlay) Stat(name string) (fs.FileInfo, error) {
+	return o.view().Stat(name)
+}
+
+func (o *Overlay) ReadFile(name string) ([]byte, error) {
+	return o.view().ReadFile(name)
+}
+
+func (o *Overlay) ReadDir(name string) ([]fs.DirEntry, error) {
+	return o.view().ReadDir(name)
+}
+
+func (o *Overlay) Glob(pattern string) ([]string, error) {
+	return o.view().Glob(pattern)
+}
+
+func (o *Overlay) Sub(dir string) (fs.FS, error) {
+	return o.view().Sub(dir)
+}
+
+// visible reports if name is a file or directory in the current state.
+// The caller must hold o.mu.
+func (o *Overlay) visible(name string) (isFile, isDir bool) {
+	if name == "" || name == "." {
+		return false, true
+	}
+	info, err := fs.Stat(o.current(), name)
+	if err != nil {
+		return false, false
+	}
+	return !info.IsDir(), info.IsDir()
+}
+
+// prepare makes sure the parent directory of name exists in the upper layer.
+// The caller must hold o.mu.
+func (o *Overlay) prepare(op, name string) error {
+	dir := strings.TrimSuffix(parent(name), string(pathSeparator))
+	isFile, isDir := o.visible(dir)
+	if isFile {
+		return fsPathError(op, name, syscall.ENOTDIR)
+	}
+	if !isDir {
+		return fsPathError(op, name, fs.ErrNotExist)
+	}
+	if dir == "" {
+		return nil
+	}
+	return o.upper.MkdirAll(dir, modeDir)
+}
+
+// whiteout records name as removed from base.
+// The caller must hold o.mu.
+func (o *Overlay) whiteout(name string) {
+	if _, err := fs.Stat(whiteoutFS{fsys: o.base, whiteouts: o.whiteouts}, name); err != nil {
+		// not in base
+		return
+	}
+	whiteouts := maps.Clone(o.whiteouts)
+	// the whiteout of a directory covers its previously removed children
+	maps.DeleteFunc(whiteouts, func(removed string, _ bool) bool {
+		return strings.HasPrefix(removed, name+"/")
+	})
+	whiteouts[name] = true
+	o.whiteouts = whiteouts
+}
+
+func (o *Overlay) Create(name string) (WriteFile, error) {
+	return o.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o666)
+}
+
+func (o *Overlay) OpenFile(name string, flag int, perm fs.FileMode) (WriteFile, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.openFile(name, flag, perm)
+}
+
+// openFile is OpenFile for callers holding o.mu.
+func (o *Overlay) openFile(name string, flag int, perm fs.FileMode) (WriteFile, error) {
+	if !validName(name) {
+		return nil, fsPathError("open", name, fs.ErrInvalid)
+	}
+	isFile, isDir := o.visible(name)
+	if isDir {
+		return nil, fsPathError("open", name, syscall.EISDIR)
+	}
+	if !isFile && flag&os.O_CREATE == 0 {
+		return nil, fsPathError("open", name, fs.ErrNotExist)
+	}
+	if isFile && flag&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL {
+		return nil, fsPathError("open", name, fs.ErrExist)
+	}
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) == 0 {
+		// reading leaves the upper layer unchanged
+		return o.readFile(name)
+	}
+	if err := o.prepare("open", name); err != nil {
+		return nil, err
+	}
+	if _, found := search(o.upper.view().files, name); isFile && !found {
+		// copy up the base file
+		info, err := fs.Stat(o.base, name)
+		if err != nil {
+			return nil, err
+		}
+		data, err := fs.ReadFile(o.base, name)
+		if err != nil {
+			return nil, err
+		}
+		f, err := o.upper.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode())
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Write(data); err != nil {
+			return nil, err
+		}
+		f.Close()
+	}
+	return o.upper.OpenFile(name, flag|os.O_CREATE, perm)
+}
+
+// readFile opens name in the current state for reading only.
+// The caller must hold o.mu.
+func (o *Overlay) readFile(name string) (WriteFile, error) {
+	view := o.current()
+	info, err := fs.Stat(view, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := fs.ReadFile(view, name)
+	if err != nil {
+		return nil, err
+	}
+	return &writeFile{
+		fs:       o.upper,
+		name:     name,
+		data:     data,
+		mode:     info.Mode().Perm(),
+		readable: true,
+	}, nil
+}
+
+func (o *Overlay) Mkdir(name string, perm fs.FileMode) error {
+	if !validName(name) {
+		return fsPathError("mkdir", name, fs.ErrInvalid)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if isFile, isDir := o.visible(name); isFile || isDir {
+		return fsPathError("mkdir", name, fs.ErrExist)
+	}
+	if err := o.prepare("mkdir", name); err != nil {
+		return err
+	}
+	return o.upper.Mkdir(name, perm)
+}
+
+func (o *Overlay) MkdirAll(name string, perm fs.FileMode) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.mkdirAll(name, perm)
+}
+
+// mkdirAll is MkdirAll for callers holding o.mu.
+func (o *Overlay) mkdirAll(name string, perm fs.FileMode) error {
+	if name == "." {
+		return nil
+	}
+	if !validName(name) {
+		return fsPathError("mkdir", name, fs.ErrInvalid)
+	}
+	for i := 0; i <= len(name); i++ {
+		if i < len(name) && name[i] != pathSeparator {
+			continue
+		}
+		if isFile, _ := o.visible(name[:i]); isFile {
+			return fsPathError("mkdir", name[:i], syscall.ENOTDIR)
+		}
+	}
+	return o.upper.MkdirAll(name, perm)
+}
+
+func (o *Overlay) Remove(name string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.remove(name)
+}
+
+// remove is Remove for callers holding o.mu.
+func (o *Overlay) remove(name string) error {
+	if !validName(name) {
+		return fsPathError("remove", name, fs.ErrInvalid)
+	}
+	isFile, isDir := o.visible(name)
+	if !isFile && !isDir {
+		return fsPathError("remove", name, fs.ErrNotExist)
+	}
+	if isDir {
+		entries, err := fs.ReadDir(o.current(), name)
+		if err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			return fsPathError("remove", name, syscall.ENOTEMPTY)
+		}
+	}
+	if _, uFile, uDir := stat(o.upper.view().files, name); uFile || uDir {
+		if err := o.upper.Remove(name); err != nil {
+			return err
+		}
+	}
+	o.whiteout(name)
+	return nil
+}
+
+func (o *Overlay) Rename(oldname, newname string) error {
+	if !validName(oldname) || !validName(newname) {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: fs.ErrInvalid}
+	}
+	if oldname == newname {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	view := o.current()
+	// collect all paths to move, parents before their children
+	var moved []string
+	err := fs.WalkDir(view, oldname, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		moved = append(moved, name)
+		return nil
+	})
+	if err != nil {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: err}
+	}
+	if isFile, isDir := o.visible(newname); isDir || isFile && len(moved) > 1 {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: fs.ErrExist}
+	}
+	if strings.HasPrefix(newname, oldname+"/") {
+		// can not move a directory into itself
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: fs.ErrInvalid}
+	}
+	if err := o.prepare("rename", newname); err != nil {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: err.(*fs.PathError).Err}
+	}
+	for _, name := range moved {
+		target := newname + strings.TrimPrefix(name, oldname)
+		info, err := fs.Stat(view, name)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if err := o.mkdirAll(target, info.Mode().Perm()); err != nil {
+				return err
+			}
+			continue
+		}
+		data, err := fs.ReadFile(view, name)
+		if err != nil {
+			return err
+		}
+		f, err := o.openFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data); err != nil {
+			return err
+		}
+		f.Close()
+	}
+	for i := len(moved) - 1; i >= 0; i-- {
+		if err := o.remove(moved[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// whiteoutFS hides removed paths of a file system.
+type whiteoutFS struct {
+	fsys      fs.FS
+	whiteouts map[string]bool
+}
+
+var _ fs.ReadDirFS = whiteoutFS{}
+
+// hidden reports if name or any of its parents is a whiteout.
+func (w whiteoutFS) hidden(name string) bool {
+	if len(w.whiteouts) == 0 {
+		return false
+	}
+	for i := 0; i <= len(name); i++ {
+		if (i == len(name) || name[i] == pathSeparator) && w.whiteouts[name[:i]] {
+			return true
+		}
+	}
+	return false
+}
+
+func (w whiteoutFS) Open(name string) (fs.File, error) {
+	if w.hidden(name) {
+		return nil, fsPathError("open", name, fs.ErrNotExist)
+	}
+	f, err := w.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(w.whiteouts) == 0 {
+		return f, nil
+	}
+	info, err := f.Stat()
+	if err != nil || !info.IsDir() {
+		return f, err
+	}
+	f.Close()
+	entries, err := w.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+	return &listedDir{
+		name:    name,
+		info:    info,
+		entries: entries,
+	}, nil
+}
+
+func (w whiteoutFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if w.hidden(name) {
+		return nil, fsPathError("readdir", name, fs.ErrNotExist)
+	}
+	entries, err := fs.ReadDir(w.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	prefix := name + "/"
+	if name == "." {
+		prefix = ""
+	}
+	return slices.DeleteFunc(entries, func(e fs.DirEntry) bool {
+		return w.whiteouts[prefix+e.Name()]
+	}), nil
+}
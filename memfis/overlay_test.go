@@ -0,0 +1,102 @@
+package memfis
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestOverlay(t *testing.T) {
+	base := fstest.MapFS{
+		"a/b":      {Data: []byte("base")},
+		"a/c":      {Data: []byte("base")},
+		"d/e":      {Data: []byte("base")},
+		"keep.txt": {Data: []byte("base")},
+	}
+	o := NewOverlay(base)
+	f, err := o.OpenFile("a/b", os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	if _, err := f.Write([]byte("BA")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	f.Close()
+	if f, err = o.Create("a/new"); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	f.Write([]byte("new"))
+	f.Close()
+	if err := o.Remove("a/c"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if err := o.Remove("d"); err == nil {
+		t.Errorf("removed non-empty directory")
+	}
+	if err := o.Rename("d", "moved"); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	expected := map[string]string{
+		"a/b":      "BAse",
+		"a/new":    "new",
+		"keep.txt": "base",
+		"moved/e":  "base",
+	}
+	var names []string
+	for name, content := range expected {
+		names = append(names, name)
+		data, err := o.ReadFile(name)
+		if err != nil || string(data) != content {
+			t.Errorf("expected %q in %q, got %q (%v)", content, name, data, err)
+		}
+	}
+	if err := fstest.TestFS(o, names...); err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+	for _, name := range []string{"a/c", "d/e", "d"} {
+		if _, err := o.Stat(name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("removed %q is visible: %v", name, err)
+		}
+	}
+	if data, _ := fs.ReadFile(base, "a/b"); string(data) != "base" {
+		t.Errorf("base was modified: %q", data)
+	}
+	if whiteouts := o.Whiteouts(); !slices.Equal(whiteouts, []string{"a/c", "d"}) {
+		t.Errorf("unexpected whiteouts %q", whiteouts)
+	}
+	if err := fstest.TestFS(o.Upper(), "a/b", "a/new", "moved/e"); err != nil {
+		t.Errorf("upper layer test failed: %v", err)
+	}
+	if _, err := o.Upper().Stat("keep.txt"); err == nil {
+		t.Errorf("unchanged file in upper layer")
+	}
+	// recreate a removed file
+	if err := o.Mkdir("d", 0o755); err != nil {
+		t.Fatalf("Mkdir failed: %v", err)
+	}
+	if entries, err := o.ReadDir("d"); err != nil || len(entries) != 0 {
+		t.Errorf("expected empty directory, got %v (%v)", entries, err)
+	}
+}
+
+func TestOverlayReadOnlyOpen(t *testing.T) {
+	o := NewOverlay(fstest.MapFS{"a/b": {Data: []byte("base"), Mode: 0o640}})
+	f, err := o.OpenFile("a/b", os.O_RDONLY, 0)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	defer f.Close()
+	data := make([]byte, 8)
+	if n, _ := f.Read(data); string(data[:n]) != "base" {
+		t.Errorf("got %q, want %q", data[:n], "base")
+	}
+	if _, err := f.Write([]byte("x")); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("Write got %v, want %v", err, fs.ErrPermission)
+	}
+	if entries, err := fs.ReadDir(o.Upper(), "."); err != nil || len(entries) != 0 {
+		t.Errorf("upper layer changed by reading: %v (%v)", entries, err)
+	}
+}
@@ -0,0 +1,93 @@
+package memfis
+
+import (
+	"io/fs"
+	"iter"
+	"sort"
+	"strings"
+)
+
+// ListPrefix retrieves the paths of all files, symbolic links and empty directories of fsys
+// starting with the raw string prefix in the order of a depth-first walk,
+// e.g. "static/im" matches "static/img/a.png" and "static/image.png".
+// Symbolic links are not followed.
+//
+// File systems created by MakeMemFS find the paths with a binary search.
+func ListPrefix(fsys fs.FS, prefix string) ([]string, error) {
+	var names []string
+	for name, err := range ListPrefixSeq(fsys, prefix) {
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// ListPrefixSeq is ListPrefix as an iterator.
+// Iteration stops after an error.
+func ListPrefixSeq(fsys fs.FS, prefix string) iter.Seq2[string, error] {
+	if w, ok := fsys.(*memWriteFS); ok {
+		fsys = w.view()
+	}
+	m, ok := fsys.(*memFS)
+	if !ok {
+		return listPrefix(fsys, prefix)
+	}
+	return func(yield func(string, error) bool) {
+		full := m.rootpath + prefix
+		rpl := len(m.rootpath)
+		// names sharing a prefix are adjacent in every lexical order
+		i := sort.Search(len(m.files), func(i int) bool {
+			return comparePath(m.files[i].GetName(), full) >= 0
+		})
+		for ; i < len(m.files); i++ {
+			n := m.files[i].GetName()
+			if !strings.HasPrefix(n, full) {
+				return
+			}
+			if n == m.rootpath {
+				// entry of the current directory
+				continue
+			}
+			if !yield(fsPath(n[rpl:]), nil) {
+				return
+			}
+		}
+	}
+}
+
+// listPrefix is ListPrefixSeq for other file systems.
+func listPrefix(fsys fs.FS, prefix string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		stopped := false
+		err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if name == "." {
+				return nil
+			}
+			if !strings.HasPrefix(name, prefix) {
+				if d.IsDir() && !strings.HasPrefix(prefix, name+"/") {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				entries, err := fs.ReadDir(fsys, name)
+				if err != nil || len(entries) > 0 {
+					return err
+				}
+			}
+			if !yield(name, nil) {
+				stopped = true
+				return fs.SkipAll
+			}
+			return nil
+		})
+		if err != nil && !stopped {
+			yield("", err)
+		}
+	}
+}
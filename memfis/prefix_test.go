@@ -0,0 +1,58 @@
+package memfis
+
+import (
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestListPrefix(t *testing.T) {
+	files := map[string]string{
+		"static/img/a.png":  "a",
+		"static/img/b.png":  "b",
+		"static/image.png":  "i",
+		"static/imx/":       "",
+		"static/index.html": "x",
+		"other":             "o",
+	}
+	fsys, err := FromMap(files)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	mapfs := fstest.MapFS{}
+	for name, content := range files {
+		if content == "" {
+			mapfs[name[:len(name)-1]] = &fstest.MapFile{Mode: modeDir}
+			continue
+		}
+		mapfs[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+	for prefix, want := range map[string][]string{
+		"static/im":  {"static/image.png", "static/img/a.png", "static/img/b.png", "static/imx"},
+		"static/img": {"static/img/a.png", "static/img/b.png"},
+		"o":          {"other"},
+		"missing":    nil,
+	} {
+		got, err := ListPrefix(fsys, prefix)
+		if err != nil || !slices.Equal(got, want) {
+			t.Errorf("ListPrefix(%q) = %q, %v; expected %q", prefix, got, err, want)
+		}
+		got, err = ListPrefix(mapfs, prefix)
+		if err != nil || !slices.Equal(got, want) {
+			t.Errorf("generic ListPrefix(%q) = %q, %v; expected %q", prefix, got, err, want)
+		}
+	}
+	sub, err := fsys.Sub("static")
+	if err != nil {
+		t.Fatalf("Sub failed: %v", err)
+	}
+	if got, err := ListPrefix(sub, "ind"); err != nil || !slices.Equal(got, []string{"index.html"}) {
+		t.Errorf("ListPrefix in sub file system = %q, %v", got, err)
+	}
+	for name := range ListPrefixSeq(fsys, "static/") {
+		if name != "static/image.png" {
+			t.Errorf("unexpected first path %q", name)
+		}
+		break
+	}
+}
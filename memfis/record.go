@@ -0,0 +1,157 @@
+package memfis
+
+import (
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Access describes how a path was accessed through a recording file system.
+type Access uint8
+
+const (
+	// AccessOpen reports a call of Open.
+	AccessOpen Access = 1 << iota
+	// AccessStat reports a call of Stat.
+	AccessStat
+	// AccessRead reports a call of ReadFile.
+	AccessRead
+	// AccessReadDir reports a call of ReadDir.
+	AccessReadDir
+)
+
+func (a Access) String() string {
+	var names []string
+	for _, n := range []struct {
+		access Access
+		name   string
+	}{{AccessOpen, "open"}, {AccessStat, "stat"}, {AccessRead, "read"}, {AccessReadDir, "readdir"}} {
+		if a&n.access != 0 {
+			names = append(names, n.name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// Recorder collects the paths accessed through the file system created by Record.
+// Its methods are safe for concurrent use.
+type Recorder struct {
+	fsys     fs.FS
+	mu       sync.Mutex
+	accesses map[string]Access
+}
+
+// Record wraps fsys in a file system recording the paths passed to Open, Stat, ReadFile and ReadDir,
+// e.g. to find unused embedded assets or to verify cache warming.
+// Calls are recorded whether they succeed or not, paths in sub file systems relative to fsys.
+func Record(fsys fs.FS) (*Recorder, fs.FS) {
+	r := &Recorder{
+		fsys:     fsys,
+		accesses: make(map[string]Access),
+	}
+	return r, &recordFS{fsys: fsys, rec: r, root: "."}
+}
+
+// record adds an access of the named path.
+func (r *Recorder) record(name string, a Access) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.accesses[name] |= a
+}
+
+// Access retrieves how the named path was accessed, zero if it was not.
+func (r *Recorder) Access(name string) Access {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.accesses[name]
+}
+
+// Accessed retrieves all accessed paths in the order of a depth-first walk.
+func (r *Recorder) Accessed() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.accesses))
+	for name := range r.accesses {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, comparePath)
+	return names
+}
+
+// Unused retrieves the files of the recorded file system that were neither opened nor read,
+// in the order of a depth-first walk.
+func (r *Recorder) Unused() ([]string, error) {
+	var unused []string
+	err := WalkDir(r.fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if r.Access(name)&(AccessOpen|AccessRead) == 0 {
+			unused = append(unused, name)
+		}
+		return nil
+	})
+	return unused, err
+}
+
+// Reset forgets all recorded accesses.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clear(r.accesses)
+}
+
+// recordFS is the file system created by Record.
+type recordFS struct {
+	fsys fs.FS
+	rec  *Recorder
+	// root is the directory of a sub file system in the recorded file system
+	root string
+}
+
+var (
+	_ fs.GlobFS     = (*recordFS)(nil)
+	_ fs.ReadDirFS  = (*recordFS)(nil)
+	_ fs.ReadFileFS = (*recordFS)(nil)
+	_ fs.StatFS     = (*recordFS)(nil)
+	_ fs.SubFS      = (*recordFS)(nil)
+)
+
+func (r *recordFS) record(name string, a Access) {
+	r.rec.record(path.Join(r.root, name), a)
+}
+
+func (r *recordFS) Open(name string) (fs.File, error) {
+	r.record(name, AccessOpen)
+	return r.fsys.Open(name)
+}
+
+func (r *recordFS) Stat(name string) (fs.FileInfo, error) {
+	r.record(name, AccessStat)
+	return fs.Stat(r.fsys, name)
+}
+
+func (r *recordFS) ReadFile(name string) ([]byte, error) {
+	r.record(name, AccessRead)
+	return fs.ReadFile(r.fsys, name)
+}
+
+func (r *recordFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	r.record(name, AccessReadDir)
+	return fs.ReadDir(r.fsys, name)
+}
+
+// Glob is not recorded, it does not access contents.
+func (r *recordFS) Glob(pattern string) ([]string, error) {
+	return fs.Glob(r.fsys, pattern)
+}
+
+func (r *recordFS) Sub(dir string) (fs.FS, error) {
+	sub, err := fs.Sub(r.fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	return &recordFS{fsys: sub, rec: r.rec, root: path.Join(r.root, dir)}, nil
+}
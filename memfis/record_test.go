@@ -0,0 +1,56 @@
+package memfis
+
+import (
+	"io/fs"
+	"slices"
+	"testing"
+)
+
+func TestRecord(t *testing.T) {
+	fsys, err := FromMap(map[string]string{
+		"index.html":     "<html>",
+		"static/app.js":  "app",
+		"static/old.css": "old",
+		"unused.txt":     "unused",
+	})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	rec, recorded := Record(fsys)
+	if _, err := fs.ReadFile(recorded, "index.html"); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if _, err := fs.Stat(recorded, "unused.txt"); err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	static, err := fs.Sub(recorded, "static")
+	if err != nil {
+		t.Fatalf("Sub failed: %v", err)
+	}
+	f, err := static.Open("app.js")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	f.Close()
+	if _, err := fs.ReadFile(recorded, "missing"); err == nil {
+		t.Fatalf("read missing file")
+	}
+
+	if accessed := rec.Accessed(); !slices.Equal(accessed, []string{"index.html", "missing", "static/app.js", "unused.txt"}) {
+		t.Errorf("unexpected accessed paths %q", accessed)
+	}
+	if a := rec.Access("unused.txt"); a != AccessStat {
+		t.Errorf("unexpected access %v", a)
+	}
+	unused, err := rec.Unused()
+	if err != nil {
+		t.Fatalf("Unused failed: %v", err)
+	}
+	if !slices.Equal(unused, []string{"static/old.css", "unused.txt"}) {
+		t.Errorf("unexpected unused files %q", unused)
+	}
+	rec.Reset()
+	if accessed := rec.Accessed(); len(accessed) != 0 {
+		t.Errorf("Reset kept %q", accessed)
+	}
+}
@@ -0,0 +1,106 @@
+package memfis
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// Rename creates a MemFS with the files and empty directories of fsys at the paths retrieved by rewrite,
+// e.g. to add or strip a prefix or to change extensions.
+// rewrite is called with the path of each file and empty directory, an empty result drops it.
+// Directories containing files are created for the new paths as needed.
+//
+// Rename fails if a new path is invalid or if paths collide.
+// File contents are read from fsys when they are accessed.
+// Targets of symbolic links are not rewritten.
+func Rename(fsys MemFS, rewrite func(string) string) (MemFS, error) {
+	var b Builder
+	// sources contains the original path for each new path
+	sources := make(map[string]string)
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || name == "." {
+			return err
+		}
+		if d.IsDir() {
+			entries, err := fsys.ReadDir(name)
+			if err != nil || len(entries) > 0 {
+				return err
+			}
+		}
+		target := rewrite(name)
+		if target == "" {
+			return nil
+		}
+		if prev, ok := sources[target]; ok {
+			return fmt.Errorf("rename: %s and %s both map to %s: %w", prev, name, target, fs.ErrExist)
+		}
+		sources[target] = name
+		if d.IsDir() {
+			return b.MkdirAll(target)
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			link, err := fs.ReadLink(fsys, name)
+			if err != nil {
+				return err
+			}
+			return b.add(MakeSymlink(target, link))
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return b.add(viewFile{
+			fsys: fsys,
+			src:  name,
+			name: target,
+			info: info,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.Freeze()
+}
+
+// viewFile is a File reading its content from a file system.
+type viewFile struct {
+	fsys fs.FS
+	// src is the path in fsys
+	src  string
+	name string
+	info fs.FileInfo
+}
+
+var (
+	_ FileSizer     = viewFile{}
+	_ FileModer     = viewFile{}
+	_ FileSyser     = viewFile{}
+	_ contentLoader = viewFile{}
+)
+
+func (f viewFile) GetName() string {
+	return f.name
+}
+
+// GetContent reads the content, it is empty if reading fails.
+func (f viewFile) GetContent() string {
+	content, _ := f.loadContent()
+	return content
+}
+
+func (f viewFile) Size() int64 {
+	return f.info.Size()
+}
+
+func (f viewFile) Mode() fs.FileMode {
+	return f.info.Mode().Perm()
+}
+
+func (f viewFile) Sys() any {
+	return f.info.Sys()
+}
+
+func (f viewFile) loadContent() (string, error) {
+	data, err := fs.ReadFile(f.fsys, f.src)
+	return string(data), err
+}
@@ -0,0 +1,53 @@
+package memfis
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRename(t *testing.T) {
+	fsys, err := FromMap(map[string]string{
+		"gen/a.pb.go":   "package a",
+		"gen/b/c.pb.go": "package c",
+		"gen/empty/":    "",
+		"README":        "ignored",
+	})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	renamed, err := Rename(fsys, func(name string) string {
+		name, ok := strings.CutPrefix(name, "gen/")
+		if !ok {
+			return ""
+		}
+		return "api/" + strings.Replace(name, ".pb.go", ".go", 1)
+	})
+	if err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if err := fstest.TestFS(renamed, "api/a.go", "api/b/c.go", "api/empty"); err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+	if data, err := renamed.ReadFile("api/b/c.go"); err != nil || string(data) != "package c" {
+		t.Errorf("unexpected content %q (%v)", data, err)
+	}
+	if _, err := renamed.Stat("README"); err == nil {
+		t.Errorf("dropped file is visible")
+	}
+
+	_, err = Rename(fsys, func(name string) string {
+		return strings.Replace(name, "/b/c.", "/a.", 1)
+	})
+	if !errors.Is(err, fs.ErrExist) {
+		t.Errorf("expected collision, got %v", err)
+	}
+	_, err = Rename(fsys, func(name string) string {
+		return "../" + name
+	})
+	if !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("expected invalid path, got %v", err)
+	}
+}
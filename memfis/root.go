@@ -0,0 +1,271 @@
+package memfis
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
+	"syscall"
+)
+
+var (
+	errPathEscapes = errors.New("path escapes from parent")
+	errRootClosed  = errors.New("root already closed")
+)
+
+// Root provides access to a directory of a file system like os.Root does for the operating system.
+// Names passed to its methods may contain ".." elements and symbolic links may be followed,
+// but they cannot resolve to anything outside of the directory.
+// Absolute names and links with absolute targets are rejected.
+//
+// Names are resolved before they are passed to the underlying file system.
+// Unless that is immutable, e.g. created by MakeMemFS, it must not be changed concurrently.
+type Root struct {
+	fsys fs.FS
+	// dir is the root directory in fsys
+	dir    string
+	closed bool
+}
+
+// OpenRoot opens the directory dir of fsys as a Root.
+// dir itself is a trusted io/fs path, symbolic links in it are followed by fsys.
+func OpenRoot(fsys fs.FS, dir string) (*Root, error) {
+	if !fs.ValidPath(dir) {
+		return nil, fsPathError("openroot", dir, fs.ErrInvalid)
+	}
+	info, err := fs.Stat(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fsPathError("openroot", dir, syscall.ENOTDIR)
+	}
+	return &Root{fsys: fsys, dir: dir}, nil
+}
+
+// Name retrieves the directory passed to OpenRoot.
+func (r *Root) Name() string {
+	return r.dir
+}
+
+// Close closes the Root, later calls of its methods fail.
+func (r *Root) Close() error {
+	r.closed = true
+	return nil
+}
+
+// resolve retrieves the path in the underlying file system for an untrusted name.
+// Symbolic links in parent directories are always followed, the last element only if final is set.
+func (r *Root) resolve(op, name string, final bool) (string, error) {
+	if r.closed {
+		return "", fsPathError(op, name, errRootClosed)
+	}
+	if name == "" || strings.HasPrefix(name, "/") {
+		return "", fsPathError(op, name, errPathEscapes)
+	}
+	// resolved contains the elements of the resolved directory, todo the elements still to resolve
+	var resolved []string
+	todo := strings.Split(name, "/")
+	links := 0
+	for len(todo) > 0 {
+		elem := todo[0]
+		todo = todo[1:]
+		switch elem {
+		case "", ".":
+			continue
+		case "..":
+			if len(resolved) == 0 {
+				return "", fsPathError(op, name, errPathEscapes)
+			}
+			resolved = resolved[:len(resolved)-1]
+			continue
+		}
+		current := path.Join(r.dir, path.Join(resolved...), elem)
+		if len(todo) == 0 && !final {
+			resolved = append(resolved, elem)
+			break
+		}
+		info, err := fs.Lstat(r.fsys, current)
+		if err != nil {
+			if len(todo) == 0 {
+				// report missing files when the path is used
+				resolved = append(resolved, elem)
+				break
+			}
+			return "", fsPathError(op, name, unwrapPathError(err))
+		}
+		if info.Mode()&fs.ModeSymlink == 0 {
+			resolved = append(resolved, elem)
+			continue
+		}
+		if links++; links > maxLinks {
+			return "", fsPathError(op, name, syscall.ELOOP)
+		}
+		target, err := fs.ReadLink(r.fsys, current)
+		if err != nil {
+			return "", fsPathError(op, name, unwrapPathError(err))
+		}
+		if strings.HasPrefix(target, "/") {
+			return "", fsPathError(op, name, errPathEscapes)
+		}
+		todo = append(strings.Split(target, "/"), todo...)
+	}
+	return path.Join(r.dir, path.Join(resolved...)), nil
+}
+
+// unwrapPathError retrieves the error wrapped by a fs.PathError
+// to report it with the name passed to Root.
+func unwrapPathError(err error) error {
+	var pe *fs.PathError
+	if errors.As(err, &pe) {
+		return pe.Err
+	}
+	return err
+}
+
+// Open opens the named file or directory for reading.
+func (r *Root) Open(name string) (fs.File, error) {
+	p, err := r.resolve("open", name, true)
+	if err != nil {
+		return nil, err
+	}
+	f, err := r.fsys.Open(p)
+	if err != nil {
+		return nil, fsPathError("open", name, unwrapPathError(err))
+	}
+	return f, nil
+}
+
+// Stat retrieves the FileInfo of the named file, symbolic links are followed.
+func (r *Root) Stat(name string) (fs.FileInfo, error) {
+	p, err := r.resolve("stat", name, true)
+	if err != nil {
+		return nil, err
+	}
+	info, err := fs.Stat(r.fsys, p)
+	if err != nil {
+		return nil, fsPathError("stat", name, unwrapPathError(err))
+	}
+	return info, nil
+}
+
+// Lstat retrieves the FileInfo of the named file without following a final symbolic link.
+func (r *Root) Lstat(name string) (fs.FileInfo, error) {
+	p, err := r.resolve("lstat", name, false)
+	if err != nil {
+		return nil, err
+	}
+	info, err := fs.Lstat(r.fsys, p)
+	if err != nil {
+		return nil, fsPathError("lstat", name, unwrapPathError(err))
+	}
+	return info, nil
+}
+
+// ReadLink retrieves the target of the named symbolic link.
+func (r *Root) ReadLink(name string) (string, error) {
+	p, err := r.resolve("readlink", name, false)
+	if err != nil {
+		return "", err
+	}
+	target, err := fs.ReadLink(r.fsys, p)
+	if err != nil {
+		return "", fsPathError("readlink", name, unwrapPathError(err))
+	}
+	return target, nil
+}
+
+// ReadFile reads the named file.
+func (r *Root) ReadFile(name string) ([]byte, error) {
+	p, err := r.resolve("readfile", name, true)
+	if err != nil {
+		return nil, err
+	}
+	content, err := fs.ReadFile(r.fsys, p)
+	if err != nil {
+		return nil, fsPathError("readfile", name, unwrapPathError(err))
+	}
+	return content, nil
+}
+
+// ReadDir reads the named directory and retrieves its entries sorted by name.
+func (r *Root) ReadDir(name string) ([]fs.DirEntry, error) {
+	p, err := r.resolve("readdir", name, true)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := fs.ReadDir(r.fsys, p)
+	if err != nil {
+		return nil, fsPathError("readdir", name, unwrapPathError(err))
+	}
+	return entries, nil
+}
+
+// OpenRoot opens the named directory as a Root confined to it.
+func (r *Root) OpenRoot(name string) (*Root, error) {
+	p, err := r.resolve("openroot", name, true)
+	if err != nil {
+		return nil, err
+	}
+	return OpenRoot(r.fsys, p)
+}
+
+// FS retrieves a file system for the Root.
+// Like for every fs.FS, the names passed to it must be valid io/fs paths.
+func (r *Root) FS() fs.FS {
+	return rootFS{r}
+}
+
+// rootFS is the file system of a Root.
+type rootFS struct {
+	root *Root
+}
+
+var (
+	_ fs.ReadDirFS  = rootFS{}
+	_ fs.ReadFileFS = rootFS{}
+	_ fs.ReadLinkFS = rootFS{}
+	_ fs.StatFS     = rootFS{}
+)
+
+func (f rootFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, fsPathError("open", name, fs.ErrInvalid)
+	}
+	return f.root.Open(name)
+}
+
+func (f rootFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, fsPathError("stat", name, fs.ErrInvalid)
+	}
+	return f.root.Stat(name)
+}
+
+func (f rootFS) Lstat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, fsPathError("lstat", name, fs.ErrInvalid)
+	}
+	return f.root.Lstat(name)
+}
+
+func (f rootFS) ReadLink(name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", fsPathError("readlink", name, fs.ErrInvalid)
+	}
+	return f.root.ReadLink(name)
+}
+
+func (f rootFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, fsPathError("readfile", name, fs.ErrInvalid)
+	}
+	return f.root.ReadFile(name)
+}
+
+func (f rootFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, fsPathError("readdir", name, fs.ErrInvalid)
+	}
+	return f.root.ReadDir(name)
+}
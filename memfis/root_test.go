@@ -0,0 +1,77 @@
+package memfis
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRoot(t *testing.T) {
+	fsys, err := MakeMemFS(
+		entry{name: "secret", content: "secret"},
+		entry{name: "public/a", content: "a"},
+		entry{name: "public/sub/b", content: "b"},
+		MakeSymlink("public/inside", "sub/b"),
+		MakeSymlink("public/up", "sub/../a"),
+		MakeSymlink("public/escape", "../secret"),
+		MakeSymlink("public/absolute", "/public/a"),
+		MakeSymlink("public/loop", "loop"),
+		MakeSymlink("public/dir", "sub"),
+	)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	root, err := OpenRoot(fsys, "public")
+	if err != nil {
+		t.Fatalf("OpenRoot failed: %v", err)
+	}
+	for name, want := range map[string]string{
+		"a":               "a",
+		"sub/../a":        "a",
+		"./sub//b":        "b",
+		"inside":          "b",
+		"up":              "a",
+		"dir/b":           "b",
+		"dir/../sub/b":    "b",
+		"sub/../dir/../a": "a",
+	} {
+		if content, err := root.ReadFile(name); err != nil || string(content) != want {
+			t.Errorf("ReadFile(%q) = %q, %v; expected %q", name, content, err, want)
+		}
+	}
+	for name, want := range map[string]error{
+		"../secret":        errPathEscapes,
+		"sub/../../secret": errPathEscapes,
+		"/secret":          errPathEscapes,
+		"escape":           errPathEscapes,
+		"absolute":         errPathEscapes,
+		"loop":             syscall.ELOOP,
+		"missing":          fs.ErrNotExist,
+	} {
+		if _, err := root.Open(name); !errors.Is(err, want) {
+			t.Errorf("Open(%q) returned %v, expected %v", name, err, want)
+		}
+	}
+	if info, err := root.Lstat("escape"); err != nil || info.Mode()&fs.ModeSymlink == 0 {
+		t.Errorf("Lstat of a link failed: %v", err)
+	}
+	if target, err := root.ReadLink("escape"); err != nil || target != "../secret" {
+		t.Errorf("ReadLink = %q, %v", target, err)
+	}
+	sub, err := root.OpenRoot("dir")
+	if err != nil {
+		t.Fatalf("OpenRoot failed: %v", err)
+	}
+	if _, err := sub.ReadFile("../a"); !errors.Is(err, errPathEscapes) {
+		t.Errorf("nested root did not confine ../a: %v", err)
+	}
+	if err := fstest.TestFS(sub.FS(), "b"); err != nil {
+		t.Errorf("file system test failed: %v", err)
+	}
+	root.Close()
+	if _, err := root.Stat("a"); err == nil {
+		t.Errorf("closed root is usable")
+	}
+}
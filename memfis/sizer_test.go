@@ -0,0 +1,170 @@
+package memfis
+
+import (
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+// expensiveFile is a FileSizer counting the calls of GetContent.
+type expensiveFile struct {
+	name  string
+	size  int64
+	calls *int
+}
+
+func (f expensiveFile) GetName() string {
+	return f.name
+}
+
+func (f expensiveFile) GetContent() string {
+	*f.calls++
+	return strings.Repeat("x", int(f.size))
+}
+
+func (f expensiveFile) Size() int64 {
+	return f.size
+}
+
+func TestFileSizerContract(t *testing.T) {
+	calls := 0
+	fsys, err := MakeMemFS(
+		expensiveFile{name: "a/b", size: 10, calls: &calls},
+		expensiveFile{name: "c", size: 20, calls: &calls},
+		entry{name: "d/"},
+	)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	info, err := fsys.Stat("a/b")
+	if err != nil || info.Size() != 10 {
+		t.Fatalf("unexpected Stat result %v (%v)", info, err)
+	}
+	entries, err := fsys.ReadDir(".")
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	for _, e := range entries {
+		if _, err := e.Info(); err != nil {
+			t.Fatalf("Info failed: %v", err)
+		}
+	}
+	f, err := fsys.Open("c")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if pos, err := f.(io.Seeker).Seek(-5, io.SeekEnd); err != nil || pos != 15 {
+		t.Errorf("unexpected Seek result %d (%v)", pos, err)
+	}
+	f.Close()
+	if err := WalkDir(fsys, ".", func(string, fs.DirEntry, error) error { return nil }); err != nil {
+		t.Fatalf("WalkDir failed: %v", err)
+	}
+	if s, err := Stats(fsys); err != nil || s.Bytes != 30 {
+		t.Errorf("unexpected Stats %+v (%v)", s, err)
+	}
+	if _, err := fsys.Glob("*"); err != nil {
+		t.Fatalf("Glob failed: %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("GetContent was called %d times without reading", calls)
+	}
+	if data, err := fsys.ReadFile("c"); err != nil || len(data) != 20 || calls != 1 {
+		t.Errorf("unexpected ReadFile result with %d calls (%v)", calls, err)
+	}
+}
+
+func TestFileSizerRenamed(t *testing.T) {
+	calls := 0
+	w, err := MakeMemWriteFS(expensiveFile{name: "a", size: 10, calls: &calls})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	if err := w.Rename("a", "b"); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if info, err := w.Stat("b"); err != nil || info.Size() != 10 {
+		t.Errorf("unexpected Stat result %v (%v)", info, err)
+	}
+	normalized, err := Options{CleanPaths: true, Backslashes: true}.MakeMemFS(
+		expensiveFile{name: "./c//d", size: 20, calls: &calls},
+		expensiveFile{name: `e\f`, size: 30, calls: &calls},
+	)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	for name, size := range map[string]int64{"c/d": 20, "e/f": 30} {
+		if info, err := normalized.Stat(name); err != nil || info.Size() != size {
+			t.Errorf("unexpected Stat result for %s: %v (%v)", name, info, err)
+		}
+	}
+	if calls != 0 {
+		t.Errorf("GetContent was called %d times without reading", calls)
+	}
+}
+
+func TestRenamedKeepsReading(t *testing.T) {
+	content := strings.Repeat("spilled ", 100)
+	w, err := Options{SpillSize: 10, SpillDir: t.TempDir()}.MakeMemWriteFS(
+		entry{name: "a", content: content},
+		checksummed{entry{name: "h", content: "hashed"}},
+	)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	if err := w.Rename("a", "b"); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if err := w.Rename("h", "i"); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	f := w.(*memWriteFS).view()
+	idx, found := search(f.files, "b")
+	if !found {
+		t.Fatalf("renamed file is missing")
+	}
+	if _, ok := f.files[idx].(ReaderAtFile); !ok {
+		t.Errorf("renamed spilled file lost ReaderAt: %T", f.files[idx])
+	}
+	if data, err := w.ReadFile("b"); err != nil || string(data) != content {
+		t.Errorf("unexpected content of renamed file (%v)", err)
+	}
+	info, err := w.Stat("i")
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if md, ok := info.Sys().(*Metadata); !ok || md.Checksum == nil || md.Checksum.Algorithm != "test" {
+		t.Errorf("renamed file lost its checksum: %#v", info.Sys())
+	}
+}
+
+// checksummed is a FileHasher with a fixed checksum.
+type checksummed struct {
+	entry
+}
+
+func (f checksummed) Checksum() Checksum {
+	return Checksum{Algorithm: "test", Sum: []byte{1}}
+}
+
+func BenchmarkStat(b *testing.B) {
+	calls := 0
+	const size = 1 << 20
+	fsys, err := MakeMemFS(
+		expensiveFile{name: "sizer", size: size, calls: &calls},
+		// hide Size to measure the fallback to GetContent
+		struct{ File }{expensiveFile{name: "content", size: size, calls: &calls}},
+	)
+	if err != nil {
+		b.Fatalf("file system creation failed: %v", err)
+	}
+	for _, name := range []string{"sizer", "content"} {
+		b.Run(name, func(b *testing.B) {
+			for b.Loop() {
+				info, _ := fsys.Stat(name)
+				info.Size()
+			}
+		})
+	}
+}
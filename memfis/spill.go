@@ -0,0 +1,87 @@
+package memfis
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"runtime"
+)
+
+// spilledFile is a File with its content moved to a temporary file.
+type spilledFile struct {
+	name string
+	file *os.File
+	size int64
+	mode fs.FileMode
+	sys  any
+}
+
+var (
+	_ ReaderAtFile = (*spilledFile)(nil)
+	_ FileModer    = (*spilledFile)(nil)
+	_ FileSyser    = (*spilledFile)(nil)
+)
+
+func (f *spilledFile) GetName() string {
+	return f.name
+}
+
+// GetContent reads the content, it is empty if reading fails.
+func (f *spilledFile) GetContent() string {
+	data, _ := readAllAt(f)
+	return string(data)
+}
+
+func (f *spilledFile) Size() int64 {
+	return f.size
+}
+
+func (f *spilledFile) ReaderAt() io.ReaderAt {
+	return f.file
+}
+
+func (f *spilledFile) Mode() fs.FileMode {
+	return f.mode
+}
+
+func (f *spilledFile) Sys() any {
+	return f.sys
+}
+
+// spills reports if the content of f is moved to a temporary file.
+func (o Options) spills(f File) bool {
+	if o.SpillSize <= 0 || isDir(f.GetName()) || isLink(f) {
+		return false
+	}
+	if _, ok := f.(*spilledFile); ok {
+		return false
+	}
+	return fileSize(f) > o.SpillSize
+}
+
+// spill moves the content of f to a temporary file in o.SpillDir.
+// The temporary file is removed when the returned File is garbage collected.
+func (o Options) spill(f File) (File, error) {
+	tmp, err := os.CreateTemp(o.SpillDir, "memfis-*")
+	if err != nil {
+		return nil, err
+	}
+	n, err := writeContent(tmp, f, 0)
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+	s := &spilledFile{
+		name: f.GetName(),
+		file: tmp,
+		size: int64(n),
+		mode: fileMode(f),
+		sys:  fileSys(f),
+	}
+	runtime.AddCleanup(s, func(tmp *os.File) {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}, tmp)
+	return s, nil
+}
@@ -0,0 +1,39 @@
+package memfis
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSpill(t *testing.T) {
+	dir := t.TempDir()
+	large := strings.Repeat("large content ", 100)
+	opts := Options{SpillSize: 64, SpillDir: dir}
+	fsys, err := opts.MakeMemFS(
+		entry{name: "small", content: "small"},
+		entry{name: "a/large", content: large, mode: 0o755},
+		entry{name: "empty/"},
+		MakeSymlink("link", "a/large"),
+	)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	spilled, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("reading spill directory failed: %v", err)
+	}
+	if len(spilled) != 1 {
+		t.Errorf("expected one spilled file, got %d", len(spilled))
+	}
+	if err := fstest.TestFS(fsys, "small", "a/large", "empty", "link"); err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+	if data, err := fsys.ReadFile("link"); err != nil || string(data) != large {
+		t.Errorf("unexpected content of spilled file (%v)", err)
+	}
+	if info, err := fsys.Stat("a/large"); err != nil || info.Mode() != 0o755 || info.Size() != int64(len(large)) {
+		t.Errorf("unexpected FileInfo of spilled file: %v (%v)", info, err)
+	}
+}
@@ -0,0 +1,56 @@
+package memfis
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// Statistics summarizes the contents of a file system.
+type Statistics struct {
+	// Files is the number of regular files.
+	Files int
+	// Dirs is the number of directories excluding ".".
+	Dirs int
+	// Links is the number of symbolic links.
+	Links int
+	// Bytes is the total size of all regular files.
+	Bytes int64
+	// ExtBytes is the total size of regular files per extension as returned by path.Ext.
+	ExtBytes map[string]int64
+	// Deepest is the first path in walk order with the most path elements.
+	Deepest string
+}
+
+// Stats retrieves the Statistics of fsys, e.g. to report or budget memory usage.
+func Stats(fsys fs.FS) (Statistics, error) {
+	s := Statistics{
+		ExtBytes: make(map[string]int64),
+	}
+	depth := 0
+	err := WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || name == "." {
+			return err
+		}
+		if n := strings.Count(name, "/") + 1; n > depth {
+			depth, s.Deepest = n, name
+		}
+		switch {
+		case d.IsDir():
+			s.Dirs++
+			return nil
+		case d.Type()&fs.ModeSymlink != 0:
+			s.Links++
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		s.Files++
+		s.Bytes += info.Size()
+		s.ExtBytes[path.Ext(name)] += info.Size()
+		return nil
+	})
+	return s, err
+}
@@ -0,0 +1,40 @@
+package memfis
+
+import (
+	"maps"
+	"reflect"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	fsys, err := MakeMemFS(
+		entry{name: "a/b/c.go", content: "package c"},
+		entry{name: "a/d.go", content: "package d"},
+		entry{name: "a/e.txt", content: "Hi"},
+		entry{name: "empty/"},
+		entry{name: "README", content: "Hello"},
+		MakeSymlink("link", "a"),
+	)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	s, err := Stats(fsys)
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	expected := Statistics{
+		Files:    4,
+		Dirs:     3,
+		Links:    1,
+		Bytes:    25,
+		ExtBytes: map[string]int64{".go": 18, ".txt": 2, "": 5},
+		Deepest:  "a/b/c.go",
+	}
+	if !maps.Equal(s.ExtBytes, expected.ExtBytes) {
+		t.Errorf("expected sizes by extension %v, got %v", expected.ExtBytes, s.ExtBytes)
+	}
+	s.ExtBytes, expected.ExtBytes = nil, nil
+	if !reflect.DeepEqual(s, expected) {
+		t.Errorf("expected %+v, got %+v", expected, s)
+	}
+}
@@ -0,0 +1,167 @@
+package memfis
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+	"syscall"
+)
+
+// maxLinks limits the number of symbolic links followed to resolve a path, like Linux does.
+const maxLinks = 40
+
+// modeSymlink is the mode of all symbolic links.
+const modeSymlink fs.FileMode = fs.ModeSymlink | 0o777
+
+// SymlinkFile is a File representing a symbolic link.
+// Its content is ignored.
+type SymlinkFile interface {
+	File
+	// Target retrieves the destination of the link.
+	// Relative targets are resolved from the directory containing the link,
+	// targets starting with "/" from the root of the file system.
+	// Links pointing outside of the file system do not resolve.
+	Target() string
+}
+
+// symlink is the SymlinkFile created by MakeSymlink.
+type symlink struct {
+	name   string
+	target string
+}
+
+var _ SymlinkFile = symlink{}
+
+// MakeSymlink creates a symbolic link with the given name pointing to target.
+func MakeSymlink(name, target string) SymlinkFile {
+	return symlink{name: name, target: target}
+}
+
+func (l symlink) GetName() string {
+	return l.name
+}
+
+func (l symlink) GetContent() string {
+	return ""
+}
+
+func (l symlink) Target() string {
+	return l.target
+}
+
+// linkTarget retrieves the target of f and reports whether f is a symbolic link.
+func linkTarget(f File) (string, bool) {
+	if l, ok := f.(SymlinkFile); ok {
+		return l.Target(), true
+	}
+	return "", false
+}
+
+// resolveLink retrieves the rootpath a link at rootpath with target points to.
+// It reports false if target is outside of the file system.
+func resolveLink(rootpath, target string) (string, bool) {
+	var p string
+	if strings.HasPrefix(target, "/") {
+		p = path.Clean(target)[1:]
+	} else {
+		p = path.Join(parent(rootpath), target)
+	}
+	switch {
+	case p == "" || p == ".":
+		return "", true
+	case p == ".." || strings.HasPrefix(p, "../"):
+		return "", false
+	}
+	return p, true
+}
+
+// follow is open with symbolic links resolved.
+// Links in parent directories are always followed, the last element only if final is set.
+// A followed link to a file keeps the name of the link.
+func (m *memFS) follow(rootpath string, final bool) (*memFile, *memFS, error) {
+	name := rootpath[strings.LastIndexByte(rootpath, pathSeparator)+1:]
+	for range maxLinks {
+		if !strings.HasPrefix(rootpath, m.rootpath) {
+			// link target outside of a sub file system
+			return nil, nil, fs.ErrNotExist
+		}
+		f, d, err := m.open(rootpath)
+		if f != nil {
+			target, ok := linkTarget(f.file)
+			if !ok || !final {
+				f.name = name
+				return f, nil, nil
+			}
+			if rootpath, ok = resolveLink(rootpath, target); !ok {
+				return nil, nil, fs.ErrNotExist
+			}
+			continue
+		}
+		if err == nil {
+			return nil, d, nil
+		}
+		next, ok := m.followParent(rootpath)
+		if !ok {
+			return nil, nil, err
+		}
+		rootpath = next
+	}
+	return nil, nil, syscall.ELOOP
+}
+
+// followParent resolves the first symbolic link in the parent directories of rootpath.
+// It reports false if there is none.
+func (m *memFS) followParent(rootpath string) (string, bool) {
+	for i := 0; i < len(rootpath); i++ {
+		if rootpath[i] != pathSeparator {
+			continue
+		}
+		idx, found := m.find(rootpath[:i])
+		if !found {
+			continue
+		}
+		target, ok := linkTarget(m.files[idx])
+		if !ok {
+			// parent is a regular file
+			return "", false
+		}
+		resolved, ok := resolveLink(rootpath[:i], target)
+		if !ok {
+			return "", false
+		}
+		return toDir(resolved) + rootpath[i+1:], true
+	}
+	return "", false
+}
+
+func (m *memFS) ReadLink(name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", fsPathError("readlink", name, fs.ErrInvalid)
+	}
+	f, _, err := m.follow(m.root(name), false)
+	if err != nil {
+		return "", fsPathError("readlink", name, err)
+	}
+	if f == nil {
+		return "", fsPathError("readlink", name, fs.ErrInvalid)
+	}
+	target, ok := linkTarget(f.file)
+	if !ok {
+		return "", fsPathError("readlink", name, fs.ErrInvalid)
+	}
+	return target, nil
+}
+
+func (m *memFS) Lstat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, fsPathError("lstat", name, fs.ErrInvalid)
+	}
+	f, d, err := m.follow(m.root(name), false)
+	if err != nil {
+		return nil, fsPathError("lstat", name, err)
+	}
+	if d != nil {
+		return makeRootDir(d.rootpath), nil
+	}
+	return f, nil
+}
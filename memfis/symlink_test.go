@@ -0,0 +1,84 @@
+package memfis
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"syscall"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSymlink(t *testing.T) {
+	fsys, err := MakeMemFS(
+		entry{name: "a/b", content: "Hello"},
+		MakeSymlink("a/link", "b"),
+		MakeSymlink("dir", "a"),
+		MakeSymlink("root", "/a/b"),
+		MakeSymlink("dangling", "missing"),
+		MakeSymlink("outside", "../x"),
+		MakeSymlink("loop", "loop"),
+	)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	for _, name := range []string{"a/link", "dir/b", "dir/link", "root"} {
+		data, err := fsys.ReadFile(name)
+		if err != nil || string(data) != "Hello" {
+			t.Errorf("expected content of a/b in %q, got %q (%v)", name, data, err)
+		}
+	}
+	for _, name := range []string{"dangling", "outside"} {
+		if _, err := fsys.Open(name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("expected %q not to exist, got %v", name, err)
+		}
+	}
+	if _, err := fsys.Open("loop"); !errors.Is(err, syscall.ELOOP) {
+		t.Errorf("expected link loop, got %v", err)
+	}
+	target, err := fs.ReadLink(fsys, "a/link")
+	if err != nil || target != "b" {
+		t.Errorf("expected link target b, got %q (%v)", target, err)
+	}
+	if _, err := fs.ReadLink(fsys, "a/b"); err == nil {
+		t.Errorf("ReadLink succeeded for a regular file")
+	}
+	info, err := fs.Lstat(fsys, "dir")
+	if err != nil || info.Mode()&fs.ModeSymlink == 0 || info.Name() != "dir" {
+		t.Errorf("expected symbolic link from Lstat, got %v (%v)", info, err)
+	}
+	info, err = fs.Stat(fsys, "a/link")
+	if err != nil || !info.Mode().IsRegular() || info.Name() != "link" {
+		t.Errorf("expected regular file from Stat, got %v (%v)", info, err)
+	}
+	valid, err := fsys.Sub("dir")
+	if err != nil {
+		t.Fatalf("Sub failed: %v", err)
+	}
+	if err := fstest.TestFS(valid, "b", "link"); err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+
+	var tarred bytes.Buffer
+	if err := WriteTar(&tarred, fsys); err != nil {
+		t.Fatalf("WriteTar failed: %v", err)
+	}
+	untarred, err := FromTar(&tarred)
+	if err != nil {
+		t.Fatalf("FromTar failed: %v", err)
+	}
+	if target, err := fs.ReadLink(untarred, "dir"); err != nil || target != "a" {
+		t.Errorf("expected link target a after tar round trip, got %q (%v)", target, err)
+	}
+	var zipped bytes.Buffer
+	if err := WriteZip(&zipped, fsys); err != nil {
+		t.Fatalf("WriteZip failed: %v", err)
+	}
+	unzipped, err := FromZip(bytes.NewReader(zipped.Bytes()), int64(zipped.Len()))
+	if err != nil {
+		t.Fatalf("FromZip failed: %v", err)
+	}
+	if target, err := fs.ReadLink(unzipped, "root"); err != nil || target != "/a/b" {
+		t.Errorf("expected link target /a/b after zip round trip, got %q (%v)", target, err)
+	}
+}
@@ -0,0 +1,83 @@
+package memfis
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+)
+
+// gzipMagic are the first bytes of gzip compressed data
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// FromTar creates a MemFS containing all regular files, directories and symbolic links of a tar archive.
+// Gzip compressed archives are detected and decompressed.
+// Other entry types are ignored.
+// Extended attributes of regular files are kept, see XattrFile.
+// If an archive contains a file more than once, the last one is used.
+func FromTar(r io.Reader) (MemFS, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(len(gzipMagic)); err == nil && bytes.Equal(magic, gzipMagic) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	} else {
+		r = br
+	}
+	tr := tar.NewReader(r)
+	files := make(map[string]File)
+	xattrs := make(map[string]map[string]string)
+	var dirs []string
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimPrefix(h.Name, "./")
+		switch h.Typeflag {
+		case tar.TypeDir:
+			name = strings.TrimSuffix(name, "/")
+			if name != "" && name != "." {
+				dirs = append(dirs, name)
+			}
+		case tar.TypeReg:
+			var sb strings.Builder
+			sb.Grow(sizeHint(h.Size))
+			if _, err := io.Copy(&sb, tr); err != nil {
+				return nil, fsPathError("untar", h.Name, err)
+			}
+			files[name] = entry{name: name, content: sb.String(), mode: h.FileInfo().Mode().Perm()}
+			xattrs[name] = paxXattrs(h.PAXRecords)
+		case tar.TypeSymlink:
+			files[name] = MakeSymlink(name, h.Linkname)
+			delete(xattrs, name)
+		}
+	}
+	var b Builder
+	for _, dir := range dirs {
+		if err := b.MkdirAll(dir); err != nil {
+			return nil, err
+		}
+	}
+	for _, f := range files {
+		if err := b.add(f); err != nil {
+			return nil, err
+		}
+	}
+	for name, attrs := range xattrs {
+		for k, v := range attrs {
+			if err := b.SetXattr(name, k, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return b.Freeze()
+}
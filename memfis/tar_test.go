@@ -0,0 +1,82 @@
+package memfis
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+	"testing/fstest"
+)
+
+func makeTar(t *testing.T, w io.Writer, nameContentPairs ...string) {
+	t.Helper()
+	tw := tar.NewWriter(w)
+	for i := 0; i < len(nameContentPairs); i += 2 {
+		name, content := nameContentPairs[i], nameContentPairs[i+1]
+		h := &tar.Header{
+			Name:     name,
+			Mode:     0o640,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}
+		if isDir(name) {
+			h.Mode = 0o750
+			h.Typeflag = tar.TypeDir
+		}
+		if err := tw.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(tw, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFromTar(t *testing.T) {
+	pairs := []string{
+		"./a/", "",
+		"./a/b", "Hello",
+		"./a/c/", "",
+		"./d", "Hi",
+		"./d", "Ho",
+	}
+	var plain, compressed bytes.Buffer
+	makeTar(t, &plain, pairs...)
+	zw := gzip.NewWriter(&compressed)
+	makeTar(t, zw, pairs...)
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []io.Reader{&plain, &compressed} {
+		fsys, err := FromTar(r)
+		if err != nil {
+			t.Fatalf("file system creation failed: %v", err)
+		}
+		if err := fstest.TestFS(fsys, "a/b", "a/c", "d"); err != nil {
+			t.Fatalf("file system test failed: %v", err)
+		}
+		if data, _ := fsys.ReadFile("d"); string(data) != "Ho" {
+			t.Errorf("expected last file content %q, got %q", "Ho", data)
+		}
+	}
+}
+
+func TestFromTarForgedSize(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{Name: "a", Mode: 0o640, Size: 1 << 62, Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(tw, "Hello"); err != nil {
+		t.Fatal(err)
+	}
+	// the archive ends before the claimed size
+	tw.Flush()
+	if _, err := FromTar(&buf); err == nil {
+		t.Errorf("no error for a forged size")
+	}
+}
@@ -0,0 +1,80 @@
+package memfis
+
+import "strings"
+
+// treeNode indexes a directory in a tree over path segments.
+// Lookups of directories need one map access per segment instead of binary searches over all files.
+type treeNode struct {
+	// lo and hi delimit the files contained in the directory in the files of the root memFS
+	lo, hi int
+	// dirs contains the subdirectories by name
+	dirs map[string]*treeNode
+}
+
+// buildTree creates the index of the sorted files.
+func buildTree(files []File) *treeNode {
+	root := &treeNode{hi: len(files)}
+	for i, f := range files {
+		node := root
+		n := f.GetName()
+		for {
+			j := strings.IndexByte(n, pathSeparator)
+			if j < 0 {
+				break
+			}
+			name := n[:j]
+			n = n[j+1:]
+			child, ok := node.dirs[name]
+			if !ok {
+				if node.dirs == nil {
+					node.dirs = make(map[string]*treeNode)
+				}
+				child = &treeNode{lo: i}
+				node.dirs[name] = child
+			}
+			child.hi = i + 1
+			node = child
+		}
+	}
+	return root
+}
+
+// lookup retrieves the node of a directory relative to t in memfs representation, nil if there is none.
+func (t *treeNode) lookup(rel string) *treeNode {
+	node := t
+	for rel != "" && node != nil {
+		seg := nextSegment(rel)
+		rel = rel[len(seg):]
+		node = node.dirs[strings.TrimSuffix(seg, string(pathSeparator))]
+	}
+	return node
+}
+
+// openTree is open for a memFS with a tree index.
+// It reports false if rootpath is not a directory.
+func (m *memFS) openTree(rootpath string) (*memFS, bool) {
+	node := m.tree.lookup(toDir(rootpath)[len(m.rootpath):])
+	if node == nil {
+		return nil, false
+	}
+	return &memFS{
+		files:    m.files[node.lo-m.offset : node.hi-m.offset],
+		rootpath: toDir(rootpath),
+		opts:     m.opts,
+		tree:     node,
+		offset:   node.lo,
+	}, true
+}
+
+// skipTree retrieves the index of the last file in the subdirectory next of m,
+// it reports false if m has no tree index.
+func (m *memFS) skipTree(next string) (int, bool) {
+	if m.tree == nil {
+		return 0, false
+	}
+	child, ok := m.tree.dirs[strings.TrimSuffix(next, string(pathSeparator))]
+	if !ok {
+		return 0, false
+	}
+	return child.hi - m.offset - 1, true
+}
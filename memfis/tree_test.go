@@ -0,0 +1,92 @@
+package memfis
+
+import (
+	"fmt"
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestTreeIndex(t *testing.T) {
+	files := []File{
+		entry{name: "a/b/c", content: "c"},
+		entry{name: "a/b/d/e", content: "e"},
+		entry{name: "a/bc", content: "bc"},
+		entry{name: "a.txt", content: "a"},
+		entry{name: "abs/x", content: "x"},
+		entry{name: "empty/"},
+		MakeSymlink("link", "a/b"),
+	}
+	plain, err := MakeMemFS(files...)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	tree, err := Options{TreeIndex: true}.MakeMemFS(files...)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	if err := fstest.TestFS(tree, "a/b/c", "a/b/d/e", "a/bc", "a.txt", "abs/x", "empty", "link"); err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+	for _, dir := range []string{".", "a", "a/b", "a/b/d", "abs", "empty", "link"} {
+		want, err := plain.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("ReadDir failed: %v", err)
+		}
+		got, err := tree.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("ReadDir with tree index failed: %v", err)
+		}
+		if !reflect.DeepEqual(names(got), names(want)) {
+			t.Errorf("ReadDir(%q) = %q, expected %q", dir, names(got), names(want))
+		}
+	}
+	sub, err := tree.Sub("a/b")
+	if err != nil {
+		t.Fatalf("Sub failed: %v", err)
+	}
+	if err := fstest.TestFS(sub, "c", "d/e"); err != nil {
+		t.Fatalf("sub file system test failed: %v", err)
+	}
+}
+
+// names retrieves the names of entries.
+func names(entries []fs.DirEntry) []string {
+	n := make([]string, len(entries))
+	for i, e := range entries {
+		n[i] = e.Name()
+	}
+	return n
+}
+
+func BenchmarkTreeIndex(b *testing.B) {
+	var files []File
+	for i := range 100 {
+		for j := range 100 {
+			for k := range 20 {
+				files = append(files, entry{name: fmt.Sprintf("d%d/s%d/f%d", i, j, k)})
+			}
+		}
+	}
+	for _, opts := range []Options{{}, {TreeIndex: true}} {
+		fsys, err := opts.MakeMemFS(files...)
+		if err != nil {
+			b.Fatalf("file system creation failed: %v", err)
+		}
+		name := "sorted"
+		if opts.TreeIndex {
+			name = "tree"
+		}
+		b.Run(name+"/ReadDir", func(b *testing.B) {
+			for b.Loop() {
+				fsys.ReadDir(".")
+			}
+		})
+		b.Run(name+"/Sub", func(b *testing.B) {
+			for b.Loop() {
+				fsys.Sub("d50/s50")
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package memfis
+
+import (
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// unionFS merges layers of file systems; later layers override earlier ones.
+type unionFS struct {
+	// layers in order of precedence, the top layer first
+	layers []fs.FS
+}
+
+var _ MemFS = (*unionFS)(nil)
+
+// Union creates a MemFS merging all layers.
+// Files in later layers override files and directories with the same name in earlier layers.
+// Directories are merged as long as they are directories in all layers above,
+// a file hides everything below it.
+//
+// The layers are accessed on every call, changes to them are visible in the union.
+func Union(layers ...fs.FS) MemFS {
+	top := slices.Clone(layers)
+	slices.Reverse(top)
+	return &unionFS{
+		layers: top,
+	}
+}
+
+// resolve finds the layer containing the named file or all layers containing the
+// named directory in order of precedence.
+// Both are empty if name does not exist in the union.
+func (u *unionFS) resolve(name string) (file fs.FS, dirs []fs.FS) {
+	dirs = u.layers
+	if name == "." {
+		return nil, dirs
+	}
+	for i := 0; i <= len(name); i++ {
+		if i < len(name) && name[i] != pathSeparator {
+			continue
+		}
+		current := name[:i]
+		var next []fs.FS
+		for _, l := range dirs {
+			info, err := fs.Stat(l, current)
+			if err != nil {
+				continue
+			}
+			if info.IsDir() {
+				next = append(next, l)
+				continue
+			}
+			if len(next) == 0 && i == len(name) {
+				return l, nil
+			}
+			// a file hides all layers below
+			break
+		}
+		if len(next) == 0 {
+			return nil, nil
+		}
+		dirs = next
+	}
+	return nil, dirs
+}
+
+func (u *unionFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, fsPathError("open", name, fs.ErrInvalid)
+	}
+	file, dirs := u.resolve(name)
+	if file != nil {
+		return file.Open(name)
+	}
+	if len(dirs) == 0 {
+		return nil, fsPathError("open", name, fs.ErrNotExist)
+	}
+	info, err := fs.Stat(dirs[0], name)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := mergeDirs(dirs, name)
+	if err != nil {
+		return nil, err
+	}
+	return &listedDir{
+		name:    name,
+		info:    info,
+		entries: entries,
+	}, nil
+}
+
+func (u *unionFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, fsPathError("stat", name, fs.ErrInvalid)
+	}
+	file, dirs := u.resolve(name)
+	if file != nil {
+		return fs.Stat(file, name)
+	}
+	if len(dirs) == 0 {
+		return nil, fsPathError("stat", name, fs.ErrNotExist)
+	}
+	return fs.Stat(dirs[0], name)
+}
+
+func (u *unionFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, fsPathError("readfile", name, fs.ErrInvalid)
+	}
+	file, dirs := u.resolve(name)
+	if file != nil {
+		return fs.ReadFile(file, name)
+	}
+	if len(dirs) != 0 {
+		return nil, fsPathError("readfile", name, syscall.EISDIR)
+	}
+	return nil, fsPathError("readfile", name, fs.ErrNotExist)
+}
+
+func (u *unionFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, fsPathError("readdir", name, fs.ErrInvalid)
+	}
+	file, dirs := u.resolve(name)
+	if file != nil {
+		return nil, fsPathError("readdir", name, syscall.ENOTDIR)
+	}
+	if len(dirs) == 0 {
+		return nil, fsPathError("readdir", name, fs.ErrNotExist)
+	}
+	return mergeDirs(dirs, name)
+}
+
+func (u *unionFS) Glob(pattern string) ([]string, error) {
+	// hide Glob from fs.Glob to use the generic implementation based on ReadDir
+	return fs.Glob(noGlobFS{u}, pattern)
+}
+
+func (u *unionFS) Sub(dir string) (fs.FS, error) {
+	if !fs.ValidPath(dir) {
+		return nil, fsPathError("sub", dir, fs.ErrInvalid)
+	}
+	_, dirs := u.resolve(dir)
+	if len(dirs) == 0 {
+		return nil, fsPathError("sub", dir, fs.ErrNotExist)
+	}
+	sub := &unionFS{
+		layers: make([]fs.FS, len(dirs)),
+	}
+	for i, l := range dirs {
+		s, err := fs.Sub(l, dir)
+		if err != nil {
+			return nil, err
+		}
+		sub.layers[i] = s
+	}
+	return sub, nil
+}
+
+// noGlobFS hides all methods of a MemFS except Open and ReadDir.
+type noGlobFS struct {
+	fsys MemFS
+}
+
+func (n noGlobFS) Open(name string) (fs.File, error) {
+	return n.fsys.Open(name)
+}
+
+func (n noGlobFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return n.fsys.ReadDir(name)
+}
+
+// mergeDirs retrieves the sorted entries of the named directory in all layers.
+// Entries in earlier layers take precedence.
+func mergeDirs(layers []fs.FS, name string) ([]fs.DirEntry, error) {
+	var merged []fs.DirEntry
+	seen := make(map[string]bool)
+	for _, l := range layers {
+		entries, err := fs.ReadDir(l, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if seen[e.Name()] {
+				continue
+			}
+			seen[e.Name()] = true
+			merged = append(merged, e)
+		}
+	}
+	slices.SortFunc(merged, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return merged, nil
+}
+
+// listedDir is an open directory with precomputed entries.
+type listedDir struct {
+	name    string
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	// offset into entries for ReadDir, negative when closed
+	offset int
+}
+
+var _ fs.ReadDirFile = (*listedDir)(nil)
+
+func (d *listedDir) isClosed() bool {
+	return d.offset < 0
+}
+
+func (d *listedDir) Close() error {
+	if d.isClosed() {
+		return fsPathError("close", d.name, errClosed)
+	}
+	d.offset = -1
+	return nil
+}
+
+func (d *listedDir) Stat() (fs.FileInfo, error) {
+	if d.isClosed() {
+		return nil, fsPathError("stat", d.name, errStatClosed)
+	}
+	return d.info, nil
+}
+
+func (d *listedDir) Read([]byte) (int, error) {
+	if d.isClosed() {
+		return 0, fsPathError("read", d.name, errClosed)
+	}
+	return 0, fsPathError("read", d.name, syscall.EISDIR)
+}
+
+// Seek will reset non-closed directories for ReadDir.
+func (d *listedDir) Seek(offset int64, whence int) (int64, error) {
+	if d.isClosed() {
+		return 0, fsPathError("seek", d.name, errClosed)
+	}
+	d.offset = 0
+	return 0, nil
+}
+
+func (d *listedDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if d.isClosed() {
+		return nil, fsPathError("readdir", d.name, errClosed)
+	}
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return slices.Clone(rest), nil
+	}
+	if len(rest) == 0 {
+		return []fs.DirEntry{}, io.EOF
+	}
+	rest = rest[:min(n, len(rest))]
+	d.offset += len(rest)
+	return slices.Clone(rest), nil
+}
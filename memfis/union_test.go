@@ -0,0 +1,70 @@
+package memfis
+
+import (
+	"io/fs"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestUnion(t *testing.T) {
+	base := fstest.MapFS{
+		"a/b":      {Data: []byte("base")},
+		"a/c":      {Data: []byte("base")},
+		"d/e":      {Data: []byte("hidden by file d")},
+		"f":        {Data: []byte("hidden by directory f")},
+		"g/h/i":    {Data: []byte("base")},
+		"keep.txt": {Data: []byte("base")},
+	}
+	override, err := FromMap(map[string]string{
+		"a/b":     "override",
+		"d":       "file",
+		"f/x":     "directory",
+		"g/h/j":   "override",
+		"new.txt": "override",
+	})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	u := Union(base, override)
+	expected := map[string]string{
+		"a/b":      "override",
+		"a/c":      "base",
+		"d":        "file",
+		"f/x":      "directory",
+		"g/h/i":    "base",
+		"g/h/j":    "override",
+		"keep.txt": "base",
+		"new.txt":  "override",
+	}
+	var names []string
+	for name, content := range expected {
+		names = append(names, name)
+		data, err := u.ReadFile(name)
+		if err != nil || string(data) != content {
+			t.Errorf("expected %q in %q, got %q (%v)", content, name, data, err)
+		}
+	}
+	if err := fstest.TestFS(u, names...); err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+	for _, name := range []string{"d/e", "f"} {
+		if info, err := u.Stat(name); err == nil && !info.IsDir() {
+			t.Errorf("shadowed file %q is visible", name)
+		}
+	}
+	matches, err := u.Glob("*/*")
+	if err != nil {
+		t.Fatalf("Glob failed: %v", err)
+	}
+	if !slices.Equal(matches, []string{"a/b", "a/c", "f/x", "g/h"}) {
+		t.Errorf("unexpected matches: %v", matches)
+	}
+	sub, err := fs.Sub(u, "g")
+	if err != nil {
+		t.Fatalf("Sub failed: %v", err)
+	}
+	if err := fstest.TestFS(sub, "h/i", "h/j"); err != nil {
+		t.Fatalf("sub file system test failed: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package memfis
+
+import (
+	"io/fs"
+	"strings"
+)
+
+// WalkDir is fs.WalkDir with a fast path for file systems created by MakeMemFS.
+// It visits the same entries in the same order and handles fs.SkipDir and fs.SkipAll the same way,
+// but iterates the sorted files directly instead of reading every directory.
+func WalkDir(fsys fs.FS, root string, fn fs.WalkDirFunc) error {
+	if w, ok := fsys.(*memWriteFS); ok {
+		fsys = w.view()
+	}
+	m, ok := fsys.(*memFS)
+	if !ok {
+		return fs.WalkDir(fsys, root, fn)
+	}
+	return m.walkDir(root, fn)
+}
+
+func (m *memFS) walkDir(root string, fn fs.WalkDirFunc) error {
+	info, err := m.Stat(root)
+	if err != nil {
+		return fn(root, nil, err)
+	}
+	err = fn(root, fs.FileInfoToDirEntry(info), nil)
+	if err == fs.SkipDir || err == fs.SkipAll {
+		return nil
+	}
+	if err != nil || !info.IsDir() {
+		return err
+	}
+	_, sub, err := m.follow(m.root(root), true)
+	if err != nil {
+		return err
+	}
+	prefix := ""
+	if root != "." {
+		prefix = root + "/"
+	}
+	join := func(rootpath string) string {
+		return prefix + fsPath(rootpath[len(sub.rootpath):])
+	}
+	// skip is the rootpath prefix of all skipped files, "" for none
+	skip := ""
+	prevdir := sub.rootpath
+	for _, f := range sub.files {
+		n := f.GetName()
+		if skip != "" && strings.HasPrefix(n, skip) {
+			continue
+		}
+		skip = ""
+		prevdir = commonPath(prevdir, n)
+		o := len(prevdir)
+		for {
+			i := strings.IndexByte(n[o:], pathSeparator)
+			if i < 0 {
+				break
+			}
+			d := memDir{rootpath: n[:o+i+1], pidx: o}
+			o += i + 1
+			prevdir = d.rootpath
+			err := fn(join(d.rootpath), d, nil)
+			if err == fs.SkipDir {
+				skip = d.rootpath
+				break
+			}
+			if err != nil {
+				return ignoreSkipAll(err)
+			}
+		}
+		if skip != "" || isDir(n) {
+			// skipped or directory entries were already reported in the loop
+			continue
+		}
+		err := fn(join(n), makeFile(f), nil)
+		if err == fs.SkipDir {
+			// skip the remaining files in the directory
+			skip = parent(n)
+			if skip == sub.rootpath {
+				return nil
+			}
+			continue
+		}
+		if err != nil {
+			return ignoreSkipAll(err)
+		}
+	}
+	return nil
+}
+
+// ignoreSkipAll retrieves nil for fs.SkipAll and err otherwise.
+func ignoreSkipAll(err error) error {
+	if err == fs.SkipAll {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,95 @@
+package memfis
+
+import (
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestWalkDir(t *testing.T) {
+	fsys, err := MakeMemFS(
+		entry{name: "a/b/c"},
+		entry{name: "a/b/d"},
+		entry{name: "a/e"},
+		entry{name: "a.txt"},
+		entry{name: "a-b"},
+		entry{name: "empty/"},
+		entry{name: "f/g/h/i"},
+		entry{name: "f/j"},
+		entry{name: "f/k"},
+		MakeSymlink("link", "f"),
+		entry{name: "z"},
+	)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	if err := fstest.TestFS(fsys, "a/b/c", "a.txt", "a-b", "link"); err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+	// record retrieves the visited entries and applies skip
+	record := func(walk func(fs.FS, string, fs.WalkDirFunc) error, root string, skip map[string]error) ([]string, error) {
+		var visited []string
+		err := walk(fsys, root, func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				visited = append(visited, name+" "+err.Error())
+				return nil
+			}
+			visited = append(visited, fmt.Sprintf("%s %s %v", name, d.Name(), d.Type()))
+			return skip[name]
+		})
+		return visited, err
+	}
+	cases := []struct {
+		root string
+		skip map[string]error
+	}{
+		{".", nil},
+		{"a", nil},
+		{"link", nil},
+		{"a/e", nil},
+		{"missing", nil},
+		{".", map[string]error{"a/b": fs.SkipDir}},
+		{".", map[string]error{"f/j": fs.SkipDir}},
+		{".", map[string]error{"a-b": fs.SkipDir}},
+		{".", map[string]error{"f/g/h": fs.SkipAll}},
+		{".", map[string]error{"a/b/c": fs.ErrInvalid}},
+		{".", map[string]error{".": fs.SkipDir}},
+	}
+	for _, c := range cases {
+		expected, expectedErr := record(fs.WalkDir, c.root, c.skip)
+		got, err := record(WalkDir, c.root, c.skip)
+		if !slices.Equal(got, expected) || err != expectedErr {
+			t.Errorf("WalkDir(%q) with %v:\nexpected %v\n%s\ngot %v\n%s",
+				c.root, c.skip, expectedErr, strings.Join(expected, "\n"), err, strings.Join(got, "\n"))
+		}
+	}
+}
+
+func BenchmarkWalkDir(b *testing.B) {
+	var files []File
+	for i := range 100 {
+		for j := range 100 {
+			files = append(files, entry{name: fmt.Sprintf("d%d/s%d/f", i, j)})
+		}
+	}
+	fsys, err := MakeMemFS(files...)
+	if err != nil {
+		b.Fatalf("file system creation failed: %v", err)
+	}
+	count := func(string, fs.DirEntry, error) error {
+		return nil
+	}
+	b.Run("native", func(b *testing.B) {
+		for b.Loop() {
+			WalkDir(fsys, ".", count)
+		}
+	})
+	b.Run("io/fs", func(b *testing.B) {
+		for b.Loop() {
+			fs.WalkDir(fsys, ".", count)
+		}
+	})
+}
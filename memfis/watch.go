@@ -0,0 +1,177 @@
+package memfis
+
+import (
+	"errors"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Op describes the kinds of changes reported in an Event like fsnotify.Op does.
+type Op uint32
+
+const (
+	// OpCreate reports a new file or directory.
+	OpCreate Op = 1 << iota
+	// OpWrite reports changed file contents.
+	OpWrite
+	// OpRemove reports a removed file or directory.
+	OpRemove
+	// OpRename reports the old name of a moved file or directory, its new name is reported by OpCreate.
+	OpRename
+)
+
+func (op Op) String() string {
+	var names []string
+	for _, o := range []struct {
+		op   Op
+		name string
+	}{{OpCreate, "CREATE"}, {OpWrite, "WRITE"}, {OpRemove, "REMOVE"}, {OpRename, "RENAME"}} {
+		if op&o.op != 0 {
+			names = append(names, o.name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// Has reports if op contains all of the changes in h.
+func (op Op) Has(h Op) bool {
+	return op&h == h
+}
+
+// Event is a change of a file or directory in a MemWriteFS.
+type Event struct {
+	// Name is the path of the changed file or directory.
+	Name string
+	Op   Op
+}
+
+func (e Event) String() string {
+	return e.Op.String() + " " + e.Name
+}
+
+// Watcher delivers the changes of a MemWriteFS created by MakeMemWriteFS.
+type Watcher struct {
+	// Events receives the changes in the order they were made.
+	// It is closed by Close.
+	Events <-chan Event
+
+	events   chan Event
+	fsys     *memWriteFS
+	patterns []string
+	mu       sync.Mutex
+	// queue contains the events not delivered yet, so changes never wait for receivers
+	queue []Event
+	wake  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+}
+
+// Watch creates a Watcher for the changes of fsys.
+// If patterns are given, only changes of paths matching at least one of them are delivered.
+// Patterns use the syntax of path.Match, "**" matches any number of path elements
+// like in Glob with Options.DoubleStar, e.g. "assets/**" watches the directory assets recursively.
+//
+// Watch fails with errors.ErrUnsupported unless fsys was created by MakeMemWriteFS.
+// Close must be called when the Watcher is not needed anymore.
+func Watch(fsys MemWriteFS, patterns ...string) (*Watcher, error) {
+	w, ok := fsys.(*memWriteFS)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fsPathError("watch", p, err)
+		}
+	}
+	events := make(chan Event)
+	watcher := &Watcher{
+		Events:   events,
+		events:   events,
+		fsys:     w,
+		patterns: slices.Clone(patterns),
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	w.mu.Lock()
+	w.watchers = append(w.watchers, watcher)
+	w.mu.Unlock()
+	go watcher.deliver()
+	return watcher, nil
+}
+
+// Close stops the delivery of changes and closes Events.
+// Undelivered changes are dropped.
+func (w *Watcher) Close() error {
+	w.once.Do(func() {
+		w.fsys.mu.Lock()
+		w.fsys.watchers = slices.DeleteFunc(w.fsys.watchers, func(other *Watcher) bool {
+			return other == w
+		})
+		w.fsys.mu.Unlock()
+		close(w.done)
+	})
+	return nil
+}
+
+// matches reports if changes of name are delivered.
+func (w *Watcher) matches(name string) bool {
+	if len(w.patterns) == 0 {
+		return true
+	}
+	for _, p := range w.patterns {
+		if ok, _ := matchDoubleStar(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// add queues an event without waiting for receivers.
+func (w *Watcher) add(e Event) {
+	if !w.matches(e.Name) {
+		return
+	}
+	w.mu.Lock()
+	w.queue = append(w.queue, e)
+	w.mu.Unlock()
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliver sends the queued events until the Watcher is closed.
+func (w *Watcher) deliver() {
+	defer close(w.events)
+	for {
+		w.mu.Lock()
+		queue := w.queue
+		w.queue = nil
+		w.mu.Unlock()
+		for _, e := range queue {
+			select {
+			case w.events <- e:
+			case <-w.done:
+				return
+			}
+		}
+		if len(queue) > 0 {
+			continue
+		}
+		select {
+		case <-w.wake:
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// notify counts a change and reports it to all watchers, the caller must hold the write lock.
+func (w *memWriteFS) notify(name string, op Op) {
+	w.changes++
+	for _, watcher := range w.watchers {
+		watcher.add(Event{Name: name, Op: op})
+	}
+}
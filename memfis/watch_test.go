@@ -0,0 +1,91 @@
+package memfis
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"time"
+)
+
+// receive collects n events or fails after a timeout.
+func receive(t *testing.T, w *Watcher, n int) []string {
+	t.Helper()
+	var events []string
+	for range n {
+		select {
+		case e := <-w.Events:
+			events = append(events, e.String())
+		case <-time.After(time.Second):
+			t.Fatalf("timeout after events %q", events)
+		}
+	}
+	return events
+}
+
+func TestWatch(t *testing.T) {
+	fsys, err := MakeMemWriteFS(entry{name: "old", content: "old"})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	all, err := Watch(fsys)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer all.Close()
+	filtered, err := Watch(fsys, "a/**")
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	check := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	check(fsys.MkdirAll("a/b", 0o750))
+	f, err := fsys.Create("a/b/c")
+	check(err)
+	_, err = io.WriteString(f, "Hello")
+	check(err)
+	check(f.Close())
+	check(fsys.Rename("old", "a/new"))
+	check(fsys.Remove("a/new"))
+
+	want := []string{
+		"CREATE a",
+		"CREATE a/b",
+		"CREATE a/b/c",
+		"WRITE a/b/c",
+		"RENAME old",
+		"CREATE a/new",
+		"REMOVE a/new",
+	}
+	if events := receive(t, all, len(want)); !slices.Equal(events, want) {
+		t.Errorf("expected events %q, got %q", want, events)
+	}
+	want = slices.DeleteFunc(want, func(e string) bool {
+		return e == "RENAME old"
+	})
+	if events := receive(t, filtered, len(want)); !slices.Equal(events, want) {
+		t.Errorf("expected filtered events %q, got %q", want, events)
+	}
+	check(filtered.Close())
+	check(fsys.Mkdir("a/x", 0o750))
+	for e := range filtered.Events {
+		// undelivered events may be dropped or received until Events is closed
+		if e.Name == "a/x" {
+			t.Errorf("received event after Close")
+		}
+	}
+	if events := receive(t, all, 1); events[0] != "CREATE a/x" {
+		t.Errorf("unexpected event %q", events[0])
+	}
+
+	if _, err := Watch(NewOverlay(fsys)); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("expected unsupported error, got %v", err)
+	}
+	if _, err := Watch(fsys, "["); err == nil {
+		t.Errorf("accepted invalid pattern")
+	}
+}
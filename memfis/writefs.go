@@ -0,0 +1,785 @@
+package memfis
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// MemWriteFS is a MemFS that can be modified after creation.
+// Reading methods operate on the state at the time of the call,
+// files and directories opened for reading are not affected by later changes.
+type MemWriteFS interface {
+	MemFS
+	// Create creates or truncates the named file and opens it for reading and writing.
+	Create(name string) (WriteFile, error)
+	// OpenFile opens the named file with flags like os.OpenFile.
+	// Supported flags are O_RDONLY, O_WRONLY, O_RDWR, O_CREATE, O_EXCL, O_TRUNC and O_APPEND.
+	// The permissions in perm are used for new files.
+	// Directories can not be opened with OpenFile, use Open instead.
+	OpenFile(name string, flag int, perm fs.FileMode) (WriteFile, error)
+	// Mkdir creates a directory, its parent directory must exist.
+	Mkdir(name string, perm fs.FileMode) error
+	// MkdirAll creates a directory and all missing parent directories.
+	MkdirAll(name string, perm fs.FileMode) error
+	// Remove removes a file or an empty directory.
+	Remove(name string) error
+	// Rename moves a file or directory.
+	// An existing file at newname is replaced, an existing directory is not.
+	Rename(oldname, newname string) error
+	// Snapshot retrieves an immutable MemFS of the current state.
+	// It shares all unmodified files with the MemWriteFS, taking it is cheap.
+	// Diff reports the changes between two snapshots.
+	Snapshot() MemFS
+}
+
+// WriteFile is a file in a MemWriteFS opened by Create or OpenFile.
+// Written data is visible in the file system after Sync or Close.
+type WriteFile interface {
+	fs.File
+	io.Writer
+	io.WriterAt
+	io.Seeker
+	io.ReaderAt
+	// Truncate changes the size of the file without changing the offset, growing fills it with zeros.
+	Truncate(size int64) error
+	// Sync makes the data written so far visible in the file system.
+	Sync() error
+}
+
+type memWriteFS struct {
+	mu sync.RWMutex
+	// files has the same structure as memFS.files.
+	// It is replaced on every change and never modified in place,
+	// so readers can safely keep using a previous version.
+	files []File
+	opts  Options
+	// bytes and count track the usage of files for the limits in opts
+	bytes int64
+	count int
+	// watchers receive all changes
+	watchers []*Watcher
+	// changes counts the changes reported to watchers
+	changes uint64
+}
+
+var _ MemWriteFS = (*memWriteFS)(nil)
+
+// MakeMemWriteFS creates a MemWriteFS initially containing files with the default Options.
+func MakeMemWriteFS(files ...File) (MemWriteFS, error) {
+	return Options{}.MakeMemWriteFS(files...)
+}
+
+// MakeMemWriteFS creates a MemWriteFS initially containing files.
+// The limits in o are enforced for all changes.
+func (o Options) MakeMemWriteFS(files ...File) (MemWriteFS, error) {
+	m, err := o.MakeMemFS(files...)
+	if err != nil {
+		return nil, err
+	}
+	w := &memWriteFS{
+		files: m.(*memFS).files,
+		opts:  o,
+	}
+	w.bytes, w.count = usage(w.files)
+	return w, nil
+}
+
+// entry is a File created by a MemWriteFS
+type entry struct {
+	name    string
+	content string
+	// mode contains the permissions, zero for the default
+	mode fs.FileMode
+}
+
+var (
+	_ FileSizer = entry{}
+	_ FileModer = entry{}
+)
+
+func (e entry) GetName() string {
+	return e.name
+}
+
+func (e entry) GetContent() string {
+	return e.content
+}
+
+func (e entry) Size() int64 {
+	return int64(len(e.content))
+}
+
+func (e entry) Mode() fs.FileMode {
+	return e.mode
+}
+
+// renamedFile is a File with a changed name.
+// It keeps the size, mode, Sys value and content loading of the file;
+// renamedReaderAt, renamedBytes and renamedCompressed also keep its way of reading.
+type renamedFile struct {
+	File
+	name string
+}
+
+var (
+	_ FileSizer     = renamedFile{}
+	_ FileModer     = renamedFile{}
+	_ FileSyser     = renamedFile{}
+	_ contentLoader = renamedFile{}
+)
+
+func (f renamedFile) GetName() string {
+	return f.name
+}
+
+func (f renamedFile) Size() int64 {
+	return fileSize(f.File)
+}
+
+func (f renamedFile) Mode() fs.FileMode {
+	return fileMode(f.File)
+}
+
+// Sys also keeps the checksum and the extended attributes in the *Metadata of the file.
+func (f renamedFile) Sys() any {
+	return fileSys(f.File)
+}
+
+func (f renamedFile) loadContent() (string, error) {
+	return content(f.File)
+}
+
+// renamedReaderAt is a renamed ReaderAtFile.
+type renamedReaderAt struct {
+	renamedFile
+}
+
+var _ ReaderAtFile = renamedReaderAt{}
+
+func (f renamedReaderAt) ReaderAt() io.ReaderAt {
+	return f.File.(ReaderAtFile).ReaderAt()
+}
+
+// renamedBytes is a renamed BytesFile.
+type renamedBytes struct {
+	renamedFile
+}
+
+var _ BytesFile = renamedBytes{}
+
+func (f renamedBytes) Bytes() []byte {
+	return f.File.(BytesFile).Bytes()
+}
+
+// renamedCompressed is a renamed CompressedFile.
+type renamedCompressed struct {
+	renamedFile
+}
+
+var _ CompressedFile = renamedCompressed{}
+
+func (f renamedCompressed) Compressed() ([]byte, string) {
+	return f.File.(CompressedFile).Compressed()
+}
+
+// withName retrieves a File with the name and the content of f.
+func withName(f File, name string) File {
+	switch f := f.(type) {
+	case entry:
+		f.name = name
+		return f
+	case symlink:
+		f.name = name
+		return f
+	case bytesEntry:
+		f.name = name
+		return f
+	case readerAtFile:
+		f.name = name
+		return f
+	case viewFile:
+		f.name = name
+		return f
+	case *concatFile:
+		c := *f
+		c.name = name
+		return &c
+	case xattrFile:
+		f.File = withName(f.File, name)
+		return f
+	case renamedFile:
+		return withName(f.File, name)
+	case renamedReaderAt:
+		return withName(f.File, name)
+	case renamedBytes:
+		return withName(f.File, name)
+	case renamedCompressed:
+		return withName(f.File, name)
+	}
+	r := renamedFile{File: f, name: name}
+	switch f.(type) {
+	case ReaderAtFile:
+		return renamedReaderAt{r}
+	case BytesFile:
+		return renamedBytes{r}
+	case CompressedFile:
+		return renamedCompressed{r}
+	}
+	return r
+}
+
+// view retrieves a read only file system of the current state.
+func (w *memWriteFS) view() *memFS {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return &memFS{
+		files: w.files,
+		opts:  w.opts,
+	}
+}
+
+// changeCount retrieves the number of changes so far.
+func (w *memWriteFS) changeCount() uint64 {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.changes
+}
+
+func (w *memWriteFS) Snapshot() MemFS {
+	return w.view()
+}
+
+func (w *memWriteFS) Open(name string) (fs.File, error) {
+	return w.view().Open(name)
+}
+
+func (w *memWriteFS) Stat(name string) (fs.FileInfo, error) {
+	return w.view().Stat(name)
+}
+
+func (w *memWriteFS) ReadFile(name string) ([]byte, error) {
+	return w.view().ReadFile(name)
+}
+
+func (w *memWriteFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return w.view().ReadDir(name)
+}
+
+func (w *memWriteFS) Glob(pattern string) ([]string, error) {
+	return w.view().Glob(pattern)
+}
+
+func (w *memWriteFS) Sub(dir string) (fs.FS, error) {
+	return w.view().Sub(dir)
+}
+
+// validName reports if name is a valid io/fs path for a file or a directory other than ".".
+func validName(name string) bool {
+	return name != "." && fs.ValidPath(name) && !strings.Contains(name, `\`)
+}
+
+// parent retrieves the parent directory of a file or directory in memfs representation.
+func parent(rootpath string) string {
+	if isDir(rootpath) {
+		rootpath = rootpath[:len(rootpath)-1]
+	}
+	return rootpath[:strings.LastIndexByte(rootpath, pathSeparator)+1]
+}
+
+// stat reports if name is a file or a directory in files.
+// The file index is only valid for files.
+func stat(files []File, name string) (idx int, isFile, isDir bool) {
+	if name == "" {
+		return 0, false, true
+	}
+	idx, isFile = search(files, name)
+	if isFile {
+		return idx, true, false
+	}
+	dir := toDir(name)
+	i, _ := search(files, dir)
+	return idx, false, i < len(files) && strings.HasPrefix(files[i].GetName(), dir)
+}
+
+// checkParent reports an error if the parent of a new file or directory does not exist.
+func checkParent(files []File, op, name string) error {
+	_, isFile, isDir := stat(files, strings.TrimSuffix(parent(name), string(pathSeparator)))
+	if isFile {
+		return fsPathError(op, name, syscall.ENOTDIR)
+	}
+	if !isDir {
+		return fsPathError(op, name, fs.ErrNotExist)
+	}
+	return nil
+}
+
+// concat retrieves a new slice containing all files.
+func concat(files ...[]File) []File {
+	n := 0
+	for _, f := range files {
+		n += len(f)
+	}
+	c := make([]File, 0, n)
+	for _, f := range files {
+		c = append(c, f...)
+	}
+	return c
+}
+
+// insert retrieves a copy of files with f inserted at the correct position.
+func insert(files []File, f File) []File {
+	i, found := search(files, f.GetName())
+	if found {
+		return concat(files[:i], []File{f}, files[i+1:])
+	}
+	return concat(files[:i], []File{f}, files[i:])
+}
+
+// remove retrieves a copy of files without the entries in [low, high).
+// The parent directory is kept as an empty directory if it would disappear.
+func remove(files []File, low, high int) []File {
+	dir := parent(files[low].GetName())
+	files = concat(files[:low], files[high:])
+	if _, _, isDir := stat(files, strings.TrimSuffix(dir, string(pathSeparator))); !isDir {
+		files = insert(files, entry{name: dir})
+	}
+	return files
+}
+
+// put replaces the content of a file if it still exists.
+func (w *memWriteFS) put(name, content string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	idx, found := search(w.files, name)
+	if !found {
+		// file was removed or renamed
+		return nil
+	}
+	bytes := w.bytes - fileSize(w.files[idx]) + int64(len(content))
+	if err := w.opts.checkUsage("write", name, bytes, w.count); err != nil {
+		return err
+	}
+	f := entry{name: name, content: content, mode: fileMode(w.files[idx])}
+	w.files = concat(w.files[:idx], []File{f}, w.files[idx+1:])
+	w.bytes = bytes
+	w.notify(name, OpWrite)
+	return nil
+}
+
+// checkWrite reports an error if the content of name can not grow to size bytes within the limits.
+func (w *memWriteFS) checkWrite(name string, size int64) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	idx, found := search(w.files, name)
+	if !found {
+		return nil
+	}
+	return w.opts.checkUsage("write", name, w.bytes-fileSize(w.files[idx])+size, w.count)
+}
+
+func (w *memWriteFS) Create(name string) (WriteFile, error) {
+	return w.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o666)
+}
+
+func (w *memWriteFS) OpenFile(name string, flag int, perm fs.FileMode) (WriteFile, error) {
+	if !validName(name) {
+		return nil, fsPathError("open", name, fs.ErrInvalid)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	idx, isFile, isDir := stat(w.files, name)
+	if isDir {
+		return nil, fsPathError("open", name, syscall.EISDIR)
+	}
+	if isFile && flag&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL {
+		return nil, fsPathError("open", name, fs.ErrExist)
+	}
+	writable := flag&(os.O_WRONLY|os.O_RDWR) != 0
+	var content string
+	mode := perm.Perm()
+	switch {
+	case isFile && flag&os.O_TRUNC != 0 && writable:
+		mode = fileMode(w.files[idx])
+		w.bytes -= fileSize(w.files[idx])
+		w.files = insert(w.files, entry{name: name, mode: mode})
+		w.notify(name, OpWrite)
+	case isFile:
+		content = w.files[idx].GetContent()
+		mode = fileMode(w.files[idx])
+	case flag&os.O_CREATE != 0:
+		if err := checkParent(w.files, "open", name); err != nil {
+			return nil, err
+		}
+		if err := w.opts.checkDepth("open", name); err != nil {
+			return nil, err
+		}
+		if err := w.opts.checkUsage("open", name, w.bytes, w.count+1); err != nil {
+			return nil, err
+		}
+		w.files = insert(w.files, entry{name: name, mode: mode})
+		w.count++
+		w.notify(name, OpCreate)
+	default:
+		return nil, fsPathError("open", name, fs.ErrNotExist)
+	}
+	return &writeFile{
+		fs:       w,
+		name:     name,
+		data:     []byte(content),
+		mode:     mode,
+		readable: flag&os.O_WRONLY == 0,
+		writable: writable,
+		append:   flag&os.O_APPEND != 0,
+	}, nil
+}
+
+func (w *memWriteFS) Mkdir(name string, perm fs.FileMode) error {
+	if !validName(name) {
+		return fsPathError("mkdir", name, fs.ErrInvalid)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, isFile, isDir := stat(w.files, name); isFile || isDir {
+		return fsPathError("mkdir", name, fs.ErrExist)
+	}
+	if err := checkParent(w.files, "mkdir", name); err != nil {
+		return err
+	}
+	if err := w.opts.checkDepth("mkdir", name); err != nil {
+		return err
+	}
+	w.files = insert(w.files, entry{name: toDir(name)})
+	w.notify(name, OpCreate)
+	return nil
+}
+
+func (w *memWriteFS) MkdirAll(name string, perm fs.FileMode) error {
+	if name == "." {
+		return nil
+	}
+	if !validName(name) {
+		return fsPathError("mkdir", name, fs.ErrInvalid)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i := 0; i <= len(name); i++ {
+		if i < len(name) && name[i] != pathSeparator {
+			continue
+		}
+		_, isFile, isDir := stat(w.files, name[:i])
+		if isFile {
+			return fsPathError("mkdir", name[:i], syscall.ENOTDIR)
+		}
+		if !isDir {
+			if err := w.opts.checkDepth("mkdir", name); err != nil {
+				return err
+			}
+			// parents of the deepest directory exist implicitly
+			w.files = insert(w.files, entry{name: toDir(name)})
+			for j := i; j <= len(name); j++ {
+				if j == len(name) || name[j] == pathSeparator {
+					w.notify(name[:j], OpCreate)
+				}
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+func (w *memWriteFS) Remove(name string) error {
+	if !validName(name) {
+		return fsPathError("remove", name, fs.ErrInvalid)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	idx, isFile, isDir := stat(w.files, name)
+	switch {
+	case isFile:
+		w.count--
+		if !isLink(w.files[idx]) {
+			w.bytes -= fileSize(w.files[idx])
+		}
+		w.files = remove(w.files, idx, idx+1)
+	case isDir:
+		dir := toDir(name)
+		low, _ := search(w.files, dir)
+		if low+1 < len(w.files) && strings.HasPrefix(w.files[low+1].GetName(), dir) ||
+			w.files[low].GetName() != dir {
+			return fsPathError("remove", name, syscall.ENOTEMPTY)
+		}
+		w.files = remove(w.files, low, low+1)
+	default:
+		return fsPathError("remove", name, fs.ErrNotExist)
+	}
+	w.notify(name, OpRemove)
+	return nil
+}
+
+func (w *memWriteFS) Rename(oldname, newname string) error {
+	if !validName(oldname) || !validName(newname) {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: fs.ErrInvalid}
+	}
+	linkError := func(err error) error {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: err}
+	}
+	if oldname == newname {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	idx, isFile, isDir := stat(w.files, oldname)
+	if !isFile && !isDir {
+		return linkError(fs.ErrNotExist)
+	}
+	if err := checkParent(w.files, "rename", newname); err != nil {
+		return linkError(err.(*fs.PathError).Err)
+	}
+	newIdx, newIsFile, newIsDir := stat(w.files, newname)
+	if newIsDir || newIsFile && isDir {
+		return linkError(fs.ErrExist)
+	}
+	if isFile {
+		if err := w.opts.checkDepth("rename", newname); err != nil {
+			return err
+		}
+		if newIsFile {
+			// the replaced file is dropped
+			w.count--
+			if replaced := w.files[newIdx]; !isLink(replaced) {
+				w.bytes -= fileSize(replaced)
+			}
+		}
+		f := w.files[idx]
+		files := remove(w.files, idx, idx+1)
+		w.files = insert(files, withName(f, newname))
+		w.notify(oldname, OpRename)
+		w.notify(newname, OpCreate)
+		return nil
+	}
+	olddir, newdir := toDir(oldname), toDir(newname)
+	if strings.HasPrefix(newdir, olddir) {
+		// can not move a directory into itself
+		return linkError(fs.ErrInvalid)
+	}
+	low, _ := search(w.files, olddir)
+	high := low
+	for high < len(w.files) && strings.HasPrefix(w.files[high].GetName(), olddir) {
+		high++
+	}
+	moved := w.files[low:high]
+	for _, f := range moved {
+		if err := w.opts.checkDepth("rename", newdir+strings.TrimPrefix(f.GetName(), olddir)); err != nil {
+			return err
+		}
+	}
+	files := remove(w.files, low, high)
+	for _, f := range moved {
+		files = insert(files, withName(f, newdir+strings.TrimPrefix(f.GetName(), olddir)))
+	}
+	w.files = files
+	w.notify(oldname, OpRename)
+	w.notify(newname, OpCreate)
+	return nil
+}
+
+// writeFile is the WriteFile implementation of memWriteFS.
+type writeFile struct {
+	fs   *memWriteFS
+	name string
+	// data is the file content as seen by this handle
+	data []byte
+	mode fs.FileMode
+	// offset for Read, Write and Seek, negative when closed
+	off      int64
+	readable bool
+	writable bool
+	// append moves the offset to the end before each Write
+	append bool
+	// dirty is set when data has not been published yet
+	dirty bool
+}
+
+var _ WriteFile = (*writeFile)(nil)
+
+func (f *writeFile) isClosed() bool {
+	return f.off < 0
+}
+
+func (f *writeFile) Close() error {
+	if f.isClosed() {
+		return fsPathError("close", f.name, fs.ErrClosed)
+	}
+	err := f.sync()
+	f.off = -1
+	return err
+}
+
+func (f *writeFile) Sync() error {
+	if f.isClosed() {
+		return fsPathError("sync", f.name, fs.ErrClosed)
+	}
+	return f.sync()
+}
+
+// sync publishes the content if it was changed.
+func (f *writeFile) sync() error {
+	if !f.dirty {
+		return nil
+	}
+	if err := f.fs.put(f.name, string(f.data)); err != nil {
+		return err
+	}
+	f.dirty = false
+	return nil
+}
+
+func (f *writeFile) Stat() (fs.FileInfo, error) {
+	if f.isClosed() {
+		return nil, fsPathError("stat", f.name, fs.ErrClosed)
+	}
+	return makeFile(entry{name: f.name, content: string(f.data), mode: f.mode}), nil
+}
+
+func (f *writeFile) Read(r []byte) (int, error) {
+	if f.isClosed() {
+		return 0, fsPathError("read", f.name, fs.ErrClosed)
+	}
+	if !f.readable {
+		return 0, fsPathError("read", f.name, fs.ErrPermission)
+	}
+	if f.off >= int64(len(f.data)) {
+		return 0, io.EOF
+	}
+	n := copy(r, f.data[f.off:])
+	f.off += int64(n)
+	return n, nil
+}
+
+func (f *writeFile) ReadAt(r []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fsPathError("readat", f.name, errNegativeOffset)
+	}
+	if f.isClosed() {
+		return 0, fsPathError("read", f.name, fs.ErrClosed)
+	}
+	if !f.readable {
+		return 0, fsPathError("read", f.name, fs.ErrPermission)
+	}
+	if off > int64(len(f.data)) {
+		return 0, io.EOF
+	}
+	n := copy(r, f.data[off:])
+	if n < len(r) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (f *writeFile) Write(p []byte) (int, error) {
+	if f.isClosed() {
+		return 0, fsPathError("write", f.name, fs.ErrClosed)
+	}
+	if !f.writable {
+		return 0, fsPathError("write", f.name, fs.ErrPermission)
+	}
+	if f.append {
+		f.off = int64(len(f.data))
+	}
+	end, err := f.writeAt(p, f.off)
+	if err != nil {
+		return 0, err
+	}
+	f.off = end
+	return len(p), nil
+}
+
+// WriteAt writes p at offset off without changing the offset for Read, Write and Seek.
+// Like for os.File, it fails for files opened with O_APPEND.
+func (f *writeFile) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fsPathError("writeat", f.name, errNegativeOffset)
+	}
+	if f.isClosed() {
+		return 0, fsPathError("write", f.name, fs.ErrClosed)
+	}
+	if !f.writable {
+		return 0, fsPathError("write", f.name, fs.ErrPermission)
+	}
+	if f.append {
+		return 0, fsPathError("writeat", f.name, errWriteAtAppend)
+	}
+	if _, err := f.writeAt(p, off); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// writeAt writes p at off and retrieves the offset after p.
+// Nothing is written if the content would exceed the limits.
+func (f *writeFile) writeAt(p []byte, off int64) (int64, error) {
+	end := off + int64(len(p))
+	if size := int64(len(f.data)); end > size {
+		if err := f.fs.checkWrite(f.name, end); err != nil {
+			return off, err
+		}
+		f.data = slices.Grow(f.data, int(end-size))[:end]
+		clear(f.data[size:])
+	}
+	copy(f.data[off:], p)
+	f.dirty = true
+	return end, nil
+}
+
+// Truncate changes the size of the file like os.File.Truncate.
+// Like writes, the change is published on Sync and Close.
+func (f *writeFile) Truncate(size int64) error {
+	if size < 0 {
+		return fsPathError("truncate", f.name, fs.ErrInvalid)
+	}
+	if f.isClosed() {
+		return fsPathError("truncate", f.name, fs.ErrClosed)
+	}
+	if !f.writable {
+		return fsPathError("truncate", f.name, fs.ErrPermission)
+	}
+	if cur := int64(len(f.data)); size > cur {
+		if err := f.fs.checkWrite(f.name, size); err != nil {
+			return err
+		}
+		f.data = slices.Grow(f.data, int(size-cur))[:size]
+		clear(f.data[cur:])
+	} else {
+		f.data = f.data[:size]
+	}
+	f.dirty = true
+	return nil
+}
+
+func (f *writeFile) Seek(offset int64, whence int) (int64, error) {
+	if f.isClosed() {
+		return 0, fsPathError("seek", f.name, fs.ErrClosed)
+	}
+	var off int64
+	switch whence {
+	case io.SeekStart:
+		off = offset
+	case io.SeekCurrent:
+		off = f.off + offset
+	case io.SeekEnd:
+		off = int64(len(f.data)) + offset
+	default:
+		return 0, fsPathError("seek", f.name, fs.ErrInvalid)
+	}
+	if off < 0 {
+		return 0, fsPathError("seek", f.name, fs.ErrInvalid)
+	}
+	// seeking beyond the end is valid, the gap is filled with zeros on Write
+	f.off = off
+	return off, nil
+}
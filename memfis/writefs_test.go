@@ -0,0 +1,247 @@
+package memfis
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMemWriteFS(t *testing.T) {
+	w, err := MakeMemWriteFS(makeFiles("a/a", "Hello")...)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	check := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	check(w.MkdirAll("b/c/d", 0o750))
+	check(w.Mkdir("e", 0o750))
+	f, err := w.Create("b/c/x")
+	check(err)
+	_, err = io.WriteString(f, "Hi there")
+	check(err)
+	_, err = f.Seek(3, io.SeekStart)
+	check(err)
+	_, err = io.WriteString(f, "you")
+	check(err)
+	check(f.Close())
+	check(w.Rename("a", "b/a"))
+	check(w.Rename("b/c/x", "y"))
+	err = fstest.TestFS(w, "b/a/a", "b/c/d", "e", "y", "b/c")
+	if err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+	data, err := fs.ReadFile(w, "y")
+	check(err)
+	if string(data) != "Hi youre" {
+		t.Errorf("unexpected content %q", data)
+	}
+	if err := w.Remove("b/c"); err == nil {
+		t.Errorf("removed non-empty directory")
+	}
+	check(w.Remove("b/c/d"))
+	check(w.Remove("b/c"))
+	check(w.Remove("e"))
+	if _, err := w.OpenFile("missing/file", os.O_RDWR|os.O_CREATE, 0o640); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("created file in missing directory: %v", err)
+	}
+	if err := w.Mkdir("y/z", 0o750); err == nil {
+		t.Errorf("created directory in file")
+	}
+	err = fstest.TestFS(w, "b/a/a", "y")
+	if err != nil {
+		t.Fatalf("file system test failed: %v", err)
+	}
+}
+
+func TestOpenFileFlags(t *testing.T) {
+	w, err := MakeMemWriteFS(makeFiles("log", "one\n")...)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	check := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := w.OpenFile("log", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("exclusively created existing file: %v", err)
+	}
+	f, err := w.OpenFile("log", os.O_WRONLY|os.O_APPEND, 0)
+	check(err)
+	_, err = f.Seek(0, io.SeekStart)
+	check(err)
+	_, err = io.WriteString(f, "two\n")
+	check(err)
+	if _, err := f.WriteAt([]byte("x"), 0); err == nil {
+		t.Errorf("WriteAt succeeded on file opened with O_APPEND")
+	}
+	check(f.Close())
+
+	f, err = w.OpenFile("new", os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+	check(err)
+	_, err = f.WriteAt([]byte("end"), 4)
+	check(err)
+	_, err = f.WriteAt([]byte("st"), 0)
+	check(err)
+	_, err = io.WriteString(f, "++")
+	check(err)
+	check(f.Close())
+
+	for name, want := range map[string]string{
+		"log": "one\ntwo\n",
+		"new": "++\x00\x00end",
+	} {
+		if data, err := fs.ReadFile(w, name); err != nil || string(data) != want {
+			t.Errorf("expected %q in %s, got %q (%v)", want, name, data, err)
+		}
+	}
+	if info, err := w.Stat("new"); err != nil || info.Mode() != 0o600 {
+		t.Errorf("unexpected mode of new file: %v (%v)", info.Mode(), err)
+	}
+}
+
+func TestWriteFileSync(t *testing.T) {
+	w, err := MakeMemWriteFS()
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	check := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := w.Create("log")
+	check(err)
+	watcher, err := Watch(w)
+	check(err)
+	defer watcher.Close()
+	expect := func(want string) {
+		t.Helper()
+		if data, err := w.ReadFile("log"); err != nil || string(data) != want {
+			t.Errorf("expected %q, got %q (%v)", want, data, err)
+		}
+	}
+	for range 1000 {
+		_, err = io.WriteString(f, "x")
+		check(err)
+	}
+	expect("")
+	check(f.Sync())
+	expect(strings.Repeat("x", 1000))
+	_, err = f.WriteAt([]byte("y"), 1001)
+	check(err)
+	check(f.Close())
+	expect(strings.Repeat("x", 1000) + "\x00y")
+	if err := f.Sync(); !errors.Is(err, fs.ErrClosed) {
+		t.Errorf("Sync after Close: %v", err)
+	}
+	want := []string{"WRITE log", "WRITE log"}
+	if events := receive(t, watcher, len(want)); !slices.Equal(events, want) {
+		t.Errorf("expected events %q, got %q", want, events)
+	}
+	select {
+	case e := <-watcher.Events:
+		t.Errorf("unexpected event %s", e)
+	default:
+	}
+}
+
+func TestWriteFileTruncate(t *testing.T) {
+	w, err := MakeMemWriteFS(entry{name: "a", content: "Hello"})
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	f, err := w.OpenFile("a", os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	if _, err := f.Seek(4, io.SeekStart); err != nil {
+		t.Fatalf("Seek failed: %v", err)
+	}
+	if err := f.Truncate(2); err != nil {
+		t.Fatalf("Truncate failed: %v", err)
+	}
+	if data, _ := w.ReadFile("a"); string(data) != "Hello" {
+		t.Errorf("truncation published before Sync: %q", data)
+	}
+	// the offset is kept, the gap is filled with zeros
+	if _, err := io.WriteString(f, "!"); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if err := f.Truncate(7); err != nil {
+		t.Fatalf("Truncate failed: %v", err)
+	}
+	if err := f.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if data, err := w.ReadFile("a"); err != nil || string(data) != "He\x00\x00!\x00\x00" {
+		t.Errorf("unexpected content %q (%v)", data, err)
+	}
+	if err := f.Truncate(-1); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("expected invalid argument for a negative size, got %v", err)
+	}
+	f.Close()
+	if err := f.Truncate(0); !errors.Is(err, fs.ErrClosed) {
+		t.Errorf("Truncate after Close: %v", err)
+	}
+	r, err := w.OpenFile("a", os.O_RDONLY, 0)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	defer r.Close()
+	if err := r.Truncate(0); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("expected permission error for a read only file, got %v", err)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	w, err := MakeMemWriteFS(makeFiles("a", "a", "b/c", "c")...)
+	if err != nil {
+		t.Fatalf("file system creation failed: %v", err)
+	}
+	check := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	before := w.Snapshot()
+	f, err := w.Create("a")
+	check(err)
+	_, err = io.WriteString(f, "changed")
+	check(err)
+	check(f.Close())
+	check(w.Remove("b/c"))
+	check(w.Mkdir("d", 0o750))
+	after := w.Snapshot()
+	check(w.Remove("d"))
+
+	if err := fstest.TestFS(before, "a", "b/c"); err != nil {
+		t.Fatalf("snapshot changed: %v", err)
+	}
+	if data, err := fs.ReadFile(before, "a"); err != nil || string(data) != "a" {
+		t.Errorf("snapshot content changed to %q (%v)", data, err)
+	}
+	changes, err := Diff(before, after)
+	check(err)
+	want := []Change{
+		{Kind: Modified, Path: "a"},
+		{Kind: Removed, Path: "b/c"},
+		{Kind: Added, Path: "d"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("expected changes %v, got %v", want, changes)
+	}
+}
@@ -0,0 +1,101 @@
+package memfis
+
+import (
+	"io/fs"
+	"maps"
+	"strings"
+)
+
+// XattrFile is a file with extended attributes,
+// e.g. provenance like the source template or the checksum algorithm used by a generator.
+// They are surfaced in the *Metadata retrieved by FileInfo.Sys and preserved by WriteTar and FromTar.
+type XattrFile interface {
+	File
+	// Xattrs retrieves the extended attributes. The returned map must not be modified.
+	Xattrs() map[string]string
+}
+
+// paxXattr is the prefix of PAX records for extended attributes in tar archives.
+const paxXattr = "SCHILY.xattr."
+
+// xattrFile adds extended attributes to a File of a Builder.
+type xattrFile struct {
+	File
+	xattrs map[string]string
+}
+
+var (
+	_ XattrFile     = xattrFile{}
+	_ FileSizer     = xattrFile{}
+	_ FileModer     = xattrFile{}
+	_ FileSyser     = xattrFile{}
+	_ contentLoader = xattrFile{}
+)
+
+func (f xattrFile) Xattrs() map[string]string {
+	return f.xattrs
+}
+
+func (f xattrFile) Size() int64 {
+	return fileSize(f.File)
+}
+
+func (f xattrFile) Mode() fs.FileMode {
+	return fileMode(f.File)
+}
+
+// Sys retrieves the Sys value of the wrapped file if it is a FileSyser
+// and the *Metadata including the extended attributes otherwise.
+func (f xattrFile) Sys() any {
+	if s, ok := f.File.(FileSyser); ok {
+		return s.Sys()
+	}
+	md, ok := metadata(f.File)
+	if !ok {
+		md = &Metadata{}
+	}
+	md.Xattrs = f.xattrs
+	return md
+}
+
+func (f xattrFile) loadContent() (string, error) {
+	return content(f.File)
+}
+
+// SetXattr sets the extended attribute key of the named file added before.
+// It fails if name is not a regular file of the Builder.
+func (b *Builder) SetXattr(name, key, value string) error {
+	f, ok := b.files[name]
+	if !ok {
+		return fsPathError("setxattr", name, fs.ErrNotExist)
+	}
+	if isLink(f) {
+		return fsPathError("setxattr", name, fs.ErrInvalid)
+	}
+	x, ok := f.(xattrFile)
+	if !ok {
+		x = xattrFile{File: f}
+	}
+	// copy the attributes, a MemFS created by Freeze may still use them
+	x.xattrs = maps.Clone(x.xattrs)
+	if x.xattrs == nil {
+		x.xattrs = make(map[string]string)
+	}
+	x.xattrs[key] = value
+	b.files[name] = x
+	return nil
+}
+
+// paxXattrs retrieves the extended attributes of a tar header, nil if it has none.
+func paxXattrs(records map[string]string) map[string]string {
+	var xattrs map[string]string
+	for k, v := range records {
+		if key, ok := strings.CutPrefix(k, paxXattr); ok {
+			if xattrs == nil {
+				xattrs = make(map[string]string)
+			}
+			xattrs[key] = v
+		}
+	}
+	return xattrs
+}
@@ -0,0 +1,61 @@
+package memfis
+
+import (
+	"bytes"
+	"io/fs"
+	"maps"
+	"testing"
+)
+
+func TestXattrs(t *testing.T) {
+	var b Builder
+	check := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	check(b.AddFile("a/b", "Hello"))
+	check(b.AddFile("c", "World"))
+	check(b.add(MakeSymlink("l", "c")))
+	check(b.SetXattr("a/b", "user.template", "hello.tmpl"))
+	check(b.SetXattr("a/b", "user.hash", "sha256"))
+	if err := b.SetXattr("a", "user.x", "y"); err == nil {
+		t.Errorf("SetXattr succeeded for a directory")
+	}
+	if err := b.SetXattr("l", "user.x", "y"); err == nil {
+		t.Errorf("SetXattr succeeded for a symbolic link")
+	}
+	fsys, err := b.Freeze()
+	check(err)
+	// later changes must not affect the MemFS
+	check(b.SetXattr("a/b", "user.template", "changed"))
+
+	want := map[string]string{"user.template": "hello.tmpl", "user.hash": "sha256"}
+	info, err := fs.Stat(fsys, "a/b")
+	check(err)
+	md, ok := info.Sys().(*Metadata)
+	if !ok || !maps.Equal(md.Xattrs, want) {
+		t.Fatalf("got Sys %#v, want xattrs %v", info.Sys(), want)
+	}
+	if data, err := fs.ReadFile(fsys, "a/b"); err != nil || string(data) != "Hello" {
+		t.Errorf("got content %q, %v", data, err)
+	}
+	if info, err := fs.Stat(fsys, "c"); err != nil || info.Sys() != nil {
+		t.Errorf("got Sys %#v, %v for a file without xattrs", info.Sys(), err)
+	}
+
+	var buf bytes.Buffer
+	check(WriteTar(&buf, fsys))
+	restored, err := FromTar(&buf)
+	check(err)
+	info, err = fs.Stat(restored, "a/b")
+	check(err)
+	md, ok = info.Sys().(*Metadata)
+	if !ok || !maps.Equal(md.Xattrs, want) {
+		t.Errorf("got Sys %#v after tar round trip, want xattrs %v", info.Sys(), want)
+	}
+	if info, err := fs.Stat(restored, "c"); err != nil || info.Sys() != nil {
+		t.Errorf("got Sys %#v, %v after tar round trip for a file without xattrs", info.Sys(), err)
+	}
+}
@@ -0,0 +1,109 @@
+package memfis
+
+import (
+	"archive/zip"
+	"io"
+	"io/fs"
+	"strings"
+	"sync"
+)
+
+// FromZip creates a MemFS containing all files and directories of a zip archive.
+// All files are decompressed immediately.
+func FromZip(r io.ReaderAt, size int64) (MemFS, error) {
+	return fromZip(r, size, false)
+}
+
+// FromZipLazy is like FromZip, but files are decompressed when their content is
+// accessed for the first time.
+// r must remain readable while the MemFS is in use.
+// Decompression errors are reported by reading methods, GetContent reports them as empty content.
+func FromZipLazy(r io.ReaderAt, size int64) (MemFS, error) {
+	return fromZip(r, size, true)
+}
+
+func fromZip(r io.ReaderAt, size int64, lazy bool) (MemFS, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	var b Builder
+	for _, zf := range zr.File {
+		if strings.HasSuffix(zf.Name, "/") {
+			if err := b.MkdirAll(strings.TrimSuffix(zf.Name, "/")); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		f := &zipFile{
+			file: zf,
+		}
+		if zf.Mode()&fs.ModeSymlink != 0 {
+			// the content of a symbolic link is its target
+			target, err := f.loadContent()
+			if err != nil {
+				return nil, fsPathError("unzip", zf.Name, err)
+			}
+			if err := b.add(MakeSymlink(zf.Name, target)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !lazy {
+			if _, err := f.loadContent(); err != nil {
+				return nil, fsPathError("unzip", zf.Name, err)
+			}
+		}
+		if err := b.add(f); err != nil {
+			return nil, err
+		}
+	}
+	return b.Freeze()
+}
+
+// zipFile is a File in a zip archive, it is decompressed on first access.
+type zipFile struct {
+	file    *zip.File
+	once    sync.Once
+	content string
+	err     error
+}
+
+var (
+	_ FileSizer     = (*zipFile)(nil)
+	_ FileModer     = (*zipFile)(nil)
+	_ contentLoader = (*zipFile)(nil)
+)
+
+func (f *zipFile) GetName() string {
+	return f.file.Name
+}
+
+func (f *zipFile) GetContent() string {
+	content, _ := f.loadContent()
+	return content
+}
+
+func (f *zipFile) Size() int64 {
+	return int64(f.file.UncompressedSize64)
+}
+
+func (f *zipFile) Mode() fs.FileMode {
+	return f.file.Mode().Perm()
+}
+
+func (f *zipFile) loadContent() (string, error) {
+	f.once.Do(func() {
+		rc, err := f.file.Open()
+		if err != nil {
+			f.err = err
+			return
+		}
+		defer rc.Close()
+		var sb strings.Builder
+		sb.Grow(int(min(f.file.UncompressedSize64, maxSizeHint)))
+		_, f.err = io.Copy(&sb, rc)
+		f.content = sb.String()
+	})
+	return f.content, f.err
+}
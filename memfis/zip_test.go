@@ -0,0 +1,74 @@
+package memfis
+
+import (
+	"archive/zip"
+	"bytes"
+	"hash/crc32"
+	"testing"
+	"testing/fstest"
+)
+
+func makeZip(t *testing.T, nameContentPairs ...string) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for i := 0; i < len(nameContentPairs); i += 2 {
+		w, err := zw.Create(nameContentPairs[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(nameContentPairs[i+1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestFromZip(t *testing.T) {
+	r := makeZip(t,
+		"a/b", "Hello",
+		"a/c/", "",
+		"d", "Hi",
+	)
+	for _, from := range []func(r *bytes.Reader) (MemFS, error){
+		func(r *bytes.Reader) (MemFS, error) { return FromZip(r, r.Size()) },
+		func(r *bytes.Reader) (MemFS, error) { return FromZipLazy(r, r.Size()) },
+	} {
+		fsys, err := from(r)
+		if err != nil {
+			t.Fatalf("file system creation failed: %v", err)
+		}
+		if err := fstest.TestFS(fsys, "a/b", "a/c", "d"); err != nil {
+			t.Fatalf("file system test failed: %v", err)
+		}
+	}
+}
+
+func TestFromZipForgedSize(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	content := []byte("Hello")
+	w, err := zw.CreateRaw(&zip.FileHeader{
+		Name:               "a",
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE(content),
+		CompressedSize64:   uint64(len(content)),
+		UncompressedSize64: 1<<63 + 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r := bytes.NewReader(buf.Bytes())
+	if _, err := FromZip(r, r.Size()); err == nil {
+		t.Errorf("no error for a forged size")
+	}
+}
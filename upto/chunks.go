@@ -0,0 +1,28 @@
+package upto
+
+import (
+	"iter"
+)
+
+// Chunks yields the bounds start and end of consecutive windows [start, end) of up to size of n elements.
+// All windows but the last have size elements. It is empty if n or size are not positive.
+//
+//	for start, end := range upto.Chunks(len(rows), 500) {
+//		if err := insert(rows[start:end]); err != nil {
+//			return err
+//		}
+//	}
+func Chunks(n, size int) iter.Seq2[int, int] {
+	return func(yield func(int, int) bool) {
+		if size <= 0 {
+			return
+		}
+		for start := 0; start < n; start += size {
+			// does not overflow, start < n
+			end := start + min(size, n-start)
+			if !yield(start, end) || end == n {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package upto
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestChunks(t *testing.T) {
+	for _, tc := range []struct {
+		n, size int
+		want    string
+	}{
+		{10, 4, "[[0 4] [4 8] [8 10]]"},
+		{8, 4, "[[0 4] [4 8]]"},
+		{3, 5, "[[0 3]]"},
+		{0, 5, "[]"},
+		{-1, 5, "[]"},
+		{5, 0, "[]"},
+		{5, -1, "[]"},
+		{math.MaxInt, math.MaxInt - 1, fmt.Sprintf("[[0 %d] [%d %d]]", math.MaxInt-1, math.MaxInt-1, math.MaxInt)},
+	} {
+		got := [][2]int{}
+		for start, end := range Chunks(tc.n, tc.size) {
+			got = append(got, [2]int{start, end})
+		}
+		if fmt.Sprint(got) != tc.want {
+			t.Errorf("Chunks(%d, %d) yielded %v, want %s", tc.n, tc.size, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package upto
+
+import (
+	"iter"
+)
+
+// DownTo yields the integers from n-1 down to 0, the values of UpTo in reverse order,
+// e.g. for the indices of a slice from the back.
+//
+//	for i := range upto.DownTo(len(s)) {
+//		fmt.Println(s[i])
+//	}
+func DownTo(n int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		// n-1 wraps around to math.MaxInt for math.MinInt
+		if n <= 0 {
+			return
+		}
+		for i := n - 1; i >= 0; i-- {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+// DownFrom yields the integers from hi down to lo, both included. It is empty if lo is greater than hi.
+//
+//	for i := range upto.DownFrom(3, 1) {
+//		fmt.Println(i) // 3, 2, 1
+//	}
+func DownFrom(hi, lo int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		if lo > hi {
+			return
+		}
+		// stops before decrementing to not wrap around at math.MinInt
+		for i := hi; yield(i) && i != lo; i-- {
+		}
+	}
+}
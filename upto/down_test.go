@@ -0,0 +1,76 @@
+package upto
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestDownTo(t *testing.T) {
+	for _, tc := range []struct {
+		n    int
+		want []int
+	}{
+		{3, []int{2, 1, 0}},
+		{1, []int{0}},
+		{0, nil},
+		{-1, nil},
+		{math.MinInt, nil},
+	} {
+		if got := slices.Collect(DownTo(tc.n)); !slices.Equal(got, tc.want) {
+			t.Errorf("DownTo(%d) = %v, want %v", tc.n, got, tc.want)
+		}
+	}
+}
+
+func TestDownFrom(t *testing.T) {
+	for _, tc := range []struct {
+		hi, lo int
+		want   []int
+	}{
+		{3, 1, []int{3, 2, 1}},
+		{1, -1, []int{1, 0, -1}},
+		{2, 2, []int{2}},
+		{1, 2, nil},
+		{math.MinInt + 1, math.MinInt, []int{math.MinInt + 1, math.MinInt}},
+	} {
+		if got := slices.Collect(DownFrom(tc.hi, tc.lo)); !slices.Equal(got, tc.want) {
+			t.Errorf("DownFrom(%d, %d) = %v, want %v", tc.hi, tc.lo, got, tc.want)
+		}
+	}
+	n := 0
+	for range DownFrom(10, 0) {
+		if n++; n == 2 {
+			break
+		}
+	}
+	if n != 2 {
+		t.Errorf("got %d iterations, want 2 before break", n)
+	}
+}
+
+func BenchmarkDown(b *testing.B) {
+	const n = 1000
+	sum := 0
+	b.Run("loop", func(b *testing.B) {
+		for b.Loop() {
+			for i := n - 1; i >= 0; i-- {
+				sum += i
+			}
+		}
+	})
+	b.Run("DownTo", func(b *testing.B) {
+		for b.Loop() {
+			for i := range DownTo(n) {
+				sum += i
+			}
+		}
+	})
+	b.Run("DownFrom", func(b *testing.B) {
+		for b.Loop() {
+			for i := range DownFrom(n-1, 0) {
+				sum += i
+			}
+		}
+	})
+}
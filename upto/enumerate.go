@@ -0,0 +1,36 @@
+package upto
+
+import (
+	"iter"
+)
+
+// Enumerate yields the indices and elements of s, like slices.All.
+//
+//	for i, name := range upto.Enumerate(names) {
+//		fmt.Println(i, name)
+//	}
+func Enumerate[T any](s []T) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i, v := range s {
+			if !yield(i, v) {
+				return
+			}
+		}
+	}
+}
+
+// Zip yields the elements of a and b with the same index as pairs.
+// It stops at the end of the shorter slice.
+//
+//	for name, score := range upto.Zip(names, scores) {
+//		fmt.Println(name, score)
+//	}
+func Zip[A, B any](a []A, b []B) iter.Seq2[A, B] {
+	return func(yield func(A, B) bool) {
+		for i := range min(len(a), len(b)) {
+			if !yield(a[i], b[i]) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package upto
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEnumerate(t *testing.T) {
+	var got []string
+	for i, s := range Enumerate([]string{"a", "b", "c"}) {
+		got = append(got, fmt.Sprint(i, s))
+		if i == 1 {
+			break
+		}
+	}
+	if fmt.Sprint(got) != "[0a 1b]" {
+		t.Errorf("got %v, want [0a 1b]", got)
+	}
+	for range Enumerate([]int(nil)) {
+		t.Errorf("iteration over nil")
+	}
+}
+
+func TestZip(t *testing.T) {
+	for _, tc := range []struct {
+		a    []string
+		b    []int
+		want string
+	}{
+		{[]string{"a", "b"}, []int{1, 2}, "[a1 b2]"},
+		{[]string{"a", "b", "c"}, []int{1}, "[a1]"},
+		{[]string{"a"}, []int{1, 2, 3}, "[a1]"},
+		{nil, []int{1}, "[]"},
+	} {
+		var got []string
+		for a, b := range Zip(tc.a, tc.b) {
+			got = append(got, fmt.Sprint(a, b))
+		}
+		if fmt.Sprint(got) != tc.want {
+			t.Errorf("Zip(%v, %v) yielded %v, want %s", tc.a, tc.b, got, tc.want)
+		}
+	}
+	n := 0
+	for range Zip([]int{1, 2, 3}, []int{1, 2, 3}) {
+		if n++; n == 2 {
+			break
+		}
+	}
+	if n != 2 {
+		t.Errorf("got %d iterations, want 2 before break", n)
+	}
+}
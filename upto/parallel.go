@@ -0,0 +1,55 @@
+package upto
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Parallel calls fn for the integers from 0 up to n on up to workers goroutines, GOMAXPROCS if workers is not positive.
+// The indices are handed out in ascending order, but calls of fn can finish in any order.
+// After the first error of fn or the cancellation of ctx, no further indices are handed out;
+// Parallel waits for the running calls and returns the first error or the cause of the cancellation.
+//
+//	err := upto.Parallel(ctx, len(urls), 8, func(i int) error {
+//		return fetch(ctx, urls[i])
+//	})
+func Parallel(ctx context.Context, n, workers int, fn func(i int) error) error {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	var (
+		wg    sync.WaitGroup
+		next  atomic.Int64
+		once  sync.Once
+		first error
+		stop  atomic.Bool
+	)
+	for range min(workers, n) {
+		wg.Go(func() {
+			for !stop.Load() && ctx.Err() == nil {
+				i := next.Add(1) - 1
+				if i >= int64(n) {
+					return
+				}
+				if err := fn(int(i)); err != nil {
+					once.Do(func() {
+						first = err
+						stop.Store(true)
+					})
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+	if first != nil {
+		return first
+	}
+	if next.Load() < int64(n) {
+		// stopped by the cancellation
+		return context.Cause(ctx)
+	}
+	return nil
+}
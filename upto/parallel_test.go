@@ -0,0 +1,63 @@
+package upto
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParallel(t *testing.T) {
+	ctx := context.Background()
+	const n = 1000
+	var seen [n]atomic.Int32
+	var running, peak atomic.Int32
+	err := Parallel(ctx, n, 4, func(i int) error {
+		r := running.Add(1)
+		defer running.Add(-1)
+		for p := peak.Load(); r > p && !peak.CompareAndSwap(p, r); p = peak.Load() {
+		}
+		seen[i].Add(1)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range seen {
+		if c := seen[i].Load(); c != 1 {
+			t.Fatalf("index %d called %d times", i, c)
+		}
+	}
+	if p := peak.Load(); p > 4 {
+		t.Errorf("%d calls at once, want at most 4", p)
+	}
+
+	failed := errors.New("failed")
+	var calls atomic.Int32
+	err = Parallel(ctx, n, 0, func(i int) error {
+		calls.Add(1)
+		if i == 10 {
+			return failed
+		}
+		return nil
+	})
+	if err != failed || calls.Load() == n {
+		t.Errorf("got %v after %d calls, want %v before all calls", err, calls.Load(), failed)
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	calls.Store(0)
+	err = Parallel(cctx, n, 2, func(i int) error {
+		if calls.Add(1) == 5 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || calls.Load() == n {
+		t.Errorf("got %v after %d calls, want %v", err, calls.Load(), context.Canceled)
+	}
+
+	if err := Parallel(ctx, 0, 4, func(int) error { return failed }); err != nil {
+		t.Errorf("got %v for no calls", err)
+	}
+}
@@ -0,0 +1,31 @@
+package upto
+
+import (
+	"iter"
+)
+
+// Range yields the integers from start to stop, excluding stop, in steps of step.
+// With a negative step, it counts down from start. It is empty if stop can not be reached from start
+// or if step is zero. It never overflows, e.g. Range(math.MaxInt-1, math.MaxInt, 2) yields math.MaxInt-1 only.
+//
+//	for i := range upto.Range(10, 0, -3) {
+//		fmt.Println(i) // 10, 7, 4, 1
+//	}
+func Range(start, stop, step int) iter.Seq[int] {
+	// kept small to be inlined, the closure does not escape then.
+	// The number of values is computed as uint to count across the whole range of int.
+	var n uint
+	if step > 0 && start < stop {
+		n = (uint(stop-start)-1)/uint(step) + 1
+	} else if step < 0 && start > stop {
+		n = (uint(start-stop)-1)/uint(-step) + 1
+	}
+	return func(yield func(int) bool) {
+		for i := range n {
+			// wraps around like the values do at the limits of int
+			if !yield(start + int(i)*step) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package upto
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestRange(t *testing.T) {
+	for _, tc := range []struct {
+		start, stop, step int
+		want              []int
+	}{
+		{0, 5, 1, []int{0, 1, 2, 3, 4}},
+		{2, 9, 3, []int{2, 5, 8}},
+		{2, 8, 3, []int{2, 5}},
+		{10, 0, -3, []int{10, 7, 4, 1}},
+		{-2, 2, 2, []int{-2, 0}},
+		{5, 5, 1, nil},
+		{5, 0, 1, nil},
+		{0, 5, -1, nil},
+		{math.MaxInt - 1, math.MaxInt, 2, []int{math.MaxInt - 1}},
+		{math.MinInt + 1, math.MinInt, math.MinInt, []int{math.MinInt + 1}},
+		{math.MinInt, math.MaxInt, math.MaxInt, []int{math.MinInt, -1, math.MaxInt - 1}},
+	} {
+		if got := slices.Collect(Range(tc.start, tc.stop, tc.step)); !slices.Equal(got, tc.want) {
+			t.Errorf("Range(%d, %d, %d) = %v, want %v", tc.start, tc.stop, tc.step, got, tc.want)
+		}
+	}
+	if got := slices.Collect(Range(0, 1, 0)); got != nil {
+		t.Errorf("Range(0, 1, 0) = %v, want none", got)
+	}
+}
+
+func BenchmarkRange(b *testing.B) {
+	const n = 1000
+	sum := 0
+	b.Run("loop", func(b *testing.B) {
+		for b.Loop() {
+			for i := 0; i < n; i += 2 {
+				sum += i
+			}
+		}
+	})
+	b.Run("Range", func(b *testing.B) {
+		for b.Loop() {
+			for i := range Range(0, n, 2) {
+				sum += i
+			}
+		}
+	})
+}
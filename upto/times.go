@@ -0,0 +1,30 @@
+package upto
+
+import (
+	"errors"
+)
+
+// Done can be returned by the func passed to Times to stop early without an error.
+var Done = errors.New("upto: done")
+
+// Times calls fn with the integers from 0 up to n and stops at the first error, which it returns.
+// If the error is Done, Times stops and returns nil.
+//
+//	err := upto.Times(3, func(i int) error {
+//		if ok, err := try(); ok || err != nil {
+//			return cmp.Or(err, upto.Done)
+//		}
+//		time.Sleep(time.Second << i)
+//		return nil
+//	})
+func Times(n int, fn func(i int) error) error {
+	for i := 0; i < n; i++ {
+		if err := fn(i); err != nil {
+			if errors.Is(err, Done) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
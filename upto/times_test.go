@@ -0,0 +1,40 @@
+package upto
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestTimes(t *testing.T) {
+	failed := errors.New("failed")
+	for _, tc := range []struct {
+		n     int
+		stop  int
+		err   error
+		calls int
+		want  error
+	}{
+		{n: 5, stop: -1, calls: 5},
+		{n: 0, stop: -1, calls: 0},
+		{n: -1, stop: -1, calls: 0},
+		{n: 5, stop: 2, err: Done, calls: 3},
+		{n: 5, stop: 2, err: fmt.Errorf("wrapped: %w", Done), calls: 3},
+		{n: 5, stop: 1, err: failed, calls: 2, want: failed},
+	} {
+		calls := 0
+		err := Times(tc.n, func(i int) error {
+			if i != calls {
+				t.Errorf("got %d in call %d", i, calls)
+			}
+			calls++
+			if i == tc.stop {
+				return tc.err
+			}
+			return nil
+		})
+		if err != tc.want || calls != tc.calls {
+			t.Errorf("Times(%d) stopping at %d with %v: got %v after %d calls, want %v after %d", tc.n, tc.stop, tc.err, err, calls, tc.want, tc.calls)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Package upto provides iterators for counting loops.
+//
+//	for i := range upto.UpTo(3) {
+//		fmt.Println(i) // 0, 1, 2
+//	}
+package upto
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+)
+
+// ErrOutOfRange is reported by UpToChecked for counts which can not be iterated.
+var ErrOutOfRange = errors.New("upto: count out of range")
+
+// UpTo yields the integers from 0 up to n, excluding n.
+// It yields nothing if n is zero or negative.
+func UpTo(n int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := 0; i < n; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+// UpToChecked is UpTo for untrusted counts.
+// It reports an error wrapping ErrOutOfRange for a negative n instead of yielding nothing.
+func UpToChecked(n int) (iter.Seq[int], error) {
+	if n < 0 {
+		return nil, fmt.Errorf("%w: %d", ErrOutOfRange, n)
+	}
+	return UpTo(n), nil
+}
+
+// N creates a slice of n elements without size to count with a range loop over its indices,
+// for code which can not use iterators:
+//
+//	for i := range upto.N(3) {
+//		fmt.Println(i) // 0, 1, 2
+//	}
+//
+// It does not allocate, all elements share the same address.
+// Unlike arrays with a fixed maximum length, it works for every length up to math.MaxInt,
+// also on 32 bit platforms.
+// It returns an empty slice if n is negative instead of panicking like make.
+func N(n int) []struct{} {
+	if n < 0 {
+		return nil
+	}
+	return make([]struct{}, n)
+}
@@ -0,0 +1,109 @@
+package upto
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestUpTo(t *testing.T) {
+	for _, n := range []int{0, 1, 5} {
+		got := slices.Collect(UpTo(n))
+		var want []int
+		for i := range N(n) {
+			want = append(want, i)
+		}
+		if !slices.Equal(got, want) || len(got) != n {
+			t.Errorf("UpTo(%d) = %v, want %v", n, got, want)
+		}
+	}
+	n := 0
+	for i := range UpTo(10) {
+		if i == 3 {
+			break
+		}
+		n++
+	}
+	if n != 3 {
+		t.Errorf("got %d iterations before break, want 3", n)
+	}
+}
+
+func TestNegative(t *testing.T) {
+	for _, n := range []int{-1, math.MinInt} {
+		if got := slices.Collect(UpTo(n)); len(got) != 0 {
+			t.Errorf("UpTo(%d) = %v, want nothing", n, got)
+		}
+		if got := len(N(n)); got != 0 {
+			t.Errorf("len(N(%d)) = %d, want 0", n, got)
+		}
+		if seq, err := UpToChecked(n); seq != nil || !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("UpToChecked(%d) got error %v, want ErrOutOfRange", n, err)
+		}
+	}
+	seq, err := UpToChecked(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := slices.Collect(seq); !slices.Equal(got, []int{0, 1, 2}) {
+		t.Errorf("UpToChecked(3) = %v, want [0 1 2]", got)
+	}
+}
+
+func TestNoAllocs(t *testing.T) {
+	sum := 0
+	allocs := testing.AllocsPerRun(100, func() {
+		for i := range UpTo(100) {
+			sum += i
+		}
+		for i := range N(100) {
+			sum += i
+		}
+		for i := range Range(100, 0, -7) {
+			sum += i
+		}
+		for i := range DownTo(100) {
+			sum += i
+		}
+		for i := range DownFrom(100, 1) {
+			sum += i
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocations, want none", allocs)
+	}
+}
+
+func BenchmarkUpTo(b *testing.B) {
+	const n = 1000
+	sum := 0
+	b.Run("loop", func(b *testing.B) {
+		for b.Loop() {
+			for i := 0; i < n; i++ {
+				sum += i
+			}
+		}
+	})
+	b.Run("UpTo", func(b *testing.B) {
+		for b.Loop() {
+			for i := range UpTo(n) {
+				sum += i
+			}
+		}
+	})
+	b.Run("N", func(b *testing.B) {
+		for b.Loop() {
+			for i := range N(n) {
+				sum += i
+			}
+		}
+	})
+}
+
+func TestNMaxInt(t *testing.T) {
+	// elements without size fit any length on 32 and 64 bit platforms
+	if n := len(N(math.MaxInt)); n != math.MaxInt {
+		t.Errorf("got length %d, want %d", n, math.MaxInt)
+	}
+}